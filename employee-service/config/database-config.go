@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DatabaseDriver names the SQL backend the service talks to
+type DatabaseDriver string
+
+const (
+	DatabaseDriverSQLite   DatabaseDriver = "sqlite"
+	DatabaseDriverPostgres DatabaseDriver = "postgres"
+	DatabaseDriverMySQL    DatabaseDriver = "mysql"
+)
+
+// DatabaseMode decides what happens to an existing database file at startup
+type DatabaseMode string
+
+const (
+	// DatabaseModePersistent keeps the existing database file and creates it when missing
+	DatabaseModePersistent DatabaseMode = "persistent"
+	// DatabaseModeEphemeral removes the database file at startup, meant for demos and tests only
+	DatabaseModeEphemeral DatabaseMode = "ephemeral"
+	// DatabaseModeReadOnly opens an existing database file without write access
+	DatabaseModeReadOnly DatabaseMode = "readonly"
+)
+
+const (
+	defaultDatabaseDriver = DatabaseDriverSQLite
+	defaultDatabaseFile   = "rest-sqlite.db"
+	defaultDatabaseMode   = DatabaseModePersistent
+)
+
+// DatabaseConfig holds the database settings read from the environment
+type DatabaseConfig struct {
+	// Driver is one of sqlite, postgres or mysql (DB_DRIVER)
+	Driver DatabaseDriver
+	// DSN is the driver specific connection string, required for postgres and mysql (DB_DSN)
+	DSN string
+	// FilePath is the location of the SQLite database file (DB_FILE)
+	FilePath string
+	// Mode is one of persistent, ephemeral or readonly, only supported by sqlite (DB_MODE)
+	Mode DatabaseMode
+	// MaxOpenConns limits the open connections in the pool, 0 means unlimited (DB_MAX_OPEN_CONNS)
+	MaxOpenConns int
+	// MaxIdleConns limits the idle connections kept in the pool (DB_MAX_IDLE_CONNS)
+	MaxIdleConns int
+	// ConnMaxLifetime closes connections older than this, 0 means never (DB_CONN_MAX_LIFETIME)
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime closes connections idle for longer than this, 0 means never (DB_CONN_MAX_IDLE_TIME)
+	ConnMaxIdleTime time.Duration
+	// AutoMigrate applies pending schema migrations at startup, otherwise they are only verified (DB_AUTO_MIGRATE)
+	AutoMigrate bool
+}
+
+// LoadDatabaseConfig reads the database settings from the environment, falling back to defaults
+func LoadDatabaseConfig() (*DatabaseConfig, error) {
+	databaseConfig := &DatabaseConfig{
+		Driver:       defaultDatabaseDriver,
+		FilePath:     defaultDatabaseFile,
+		Mode:         defaultDatabaseMode,
+		MaxIdleConns: 2,
+		AutoMigrate:  true,
+	}
+	if driver := os.Getenv("DB_DRIVER"); len(driver) > 0 {
+		databaseConfig.Driver = DatabaseDriver(strings.ToLower(driver))
+	}
+	databaseConfig.DSN = os.Getenv("DB_DSN")
+	if filePath := os.Getenv("DB_FILE"); len(filePath) > 0 {
+		databaseConfig.FilePath = filePath
+	}
+	if mode := os.Getenv("DB_MODE"); len(mode) > 0 {
+		databaseConfig.Mode = DatabaseMode(strings.ToLower(mode))
+	}
+
+	var err error
+	if databaseConfig.MaxOpenConns, err = intFromEnv("DB_MAX_OPEN_CONNS", databaseConfig.MaxOpenConns); err != nil {
+		return nil, err
+	}
+	if databaseConfig.MaxIdleConns, err = intFromEnv("DB_MAX_IDLE_CONNS", databaseConfig.MaxIdleConns); err != nil {
+		return nil, err
+	}
+	if databaseConfig.ConnMaxLifetime, err = durationFromEnv("DB_CONN_MAX_LIFETIME", databaseConfig.ConnMaxLifetime); err != nil {
+		return nil, err
+	}
+	if databaseConfig.ConnMaxIdleTime, err = durationFromEnv("DB_CONN_MAX_IDLE_TIME", databaseConfig.ConnMaxIdleTime); err != nil {
+		return nil, err
+	}
+
+	if autoMigrate := os.Getenv("DB_AUTO_MIGRATE"); len(autoMigrate) > 0 {
+		if databaseConfig.AutoMigrate, err = strconv.ParseBool(autoMigrate); err != nil {
+			return nil, fmt.Errorf("invalid DB_AUTO_MIGRATE %q, expected true or false", autoMigrate)
+		}
+	}
+
+	if err := databaseConfig.Validate(); err != nil {
+		return nil, err
+	}
+	return databaseConfig, nil
+}
+
+// Validate checks that the driver, mode and DSN combination is usable
+func (databaseConfig *DatabaseConfig) Validate() error {
+	switch databaseConfig.Mode {
+	case DatabaseModePersistent, DatabaseModeEphemeral, DatabaseModeReadOnly:
+	default:
+		return fmt.Errorf("invalid DB_MODE %q, expected one of persistent, ephemeral, readonly", databaseConfig.Mode)
+	}
+
+	switch databaseConfig.Driver {
+	case DatabaseDriverSQLite:
+	case DatabaseDriverPostgres, DatabaseDriverMySQL:
+		if len(databaseConfig.DSN) == 0 {
+			return fmt.Errorf("DB_DSN is required for the %s driver", databaseConfig.Driver)
+		}
+		if databaseConfig.Mode != DatabaseModePersistent {
+			return fmt.Errorf("DB_MODE %s is only supported by the sqlite driver", databaseConfig.Mode)
+		}
+	default:
+		return fmt.Errorf("invalid DB_DRIVER %q, expected one of sqlite, postgres, mysql", databaseConfig.Driver)
+	}
+	return nil
+}
+
+func intFromEnv(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if len(value) == 0 {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid %s %q, expected a non-negative integer", key, value)
+	}
+	return parsed, nil
+}
+
+func durationFromEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if len(value) == 0 {
+		return fallback, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid %s %q, expected a duration such as 30m", key, value)
+	}
+	return parsed, nil
+}
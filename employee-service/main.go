@@ -5,6 +5,9 @@ import (
 	"github.com/MrAzharuddin/employee-crud/employee-service/config"
 	_ "github.com/MrAzharuddin/employee-crud/employee-service/docs"
 	restcontrollers "github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/controllers"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/services"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sinhashubham95/go-actuator"
@@ -20,35 +23,134 @@ var (
 	serviceName  = os.Getenv("SERVICE_NAME")
 	collectorURL = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
 	insecure     = os.Getenv("INSECURE_MODE")
+	adminKey     = os.Getenv("ADMIN_API_KEY")
+	policyFile   = os.Getenv("RBAC_POLICY_FILE")
+	authConfig   = restcontrollers.AuthConfig{
+		HMACSecret: os.Getenv("JWT_HMAC_SECRET"),
+		JWKSFile:   os.Getenv("JWT_JWKS_FILE"),
+		JWKSURL:    os.Getenv("JWT_JWKS_URL"),
+		Issuer:     os.Getenv("JWT_ISSUER"),
+		Audience:   os.Getenv("JWT_AUDIENCE"),
+	}
 )
 
 func ServeRoutes() *gin.Engine {
-	router := gin.Default()
-	employeeController, err := restcontrollers.NewEmployeeController()
+	router := gin.New()
+	router.Use(gin.Logger(), gin.CustomRecovery(restcontrollers.Recovered), restcontrollers.RequestID())
+	router.NoRoute(restcontrollers.NoRoute)
+	employeeController, departmentController, err := newControllers()
+	if err != nil {
+		log.Errorf("error occurred: %v", err)
+		os.Exit(1)
+	}
+	tokenVerifier, err := restcontrollers.NewTokenVerifier(authConfig)
+	if err != nil {
+		log.Errorf("error occurred: %v", err)
+		os.Exit(1)
+	}
+	if !tokenVerifier.Configured() {
+		log.Warn("neither JWT_HMAC_SECRET nor a JWKS is set, every /v1 request is refused")
+	}
+	if len(policyFile) == 0 {
+		policyFile = "rbac-policy.json"
+	}
+	policy, err := restcontrollers.LoadPolicy(policyFile)
 	if err != nil {
 		log.Errorf("error occurred: %v", err)
 		os.Exit(1)
 	}
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
-	v1 := router.Group("/v1")
+	v1 := router.Group("/v1", restcontrollers.Authenticate(tokenVerifier), restcontrollers.Authorize(policy))
 	{
 
-		v1.POST("/employees", employeeController.CreateEmployee)
+		v1.POST("/employees", restcontrollers.RequirePermission(restcontrollers.PermissionWriteEmployees, employeeController.CreateEmployee))
+
+		v1.GET("/employees/search", restcontrollers.RequirePermission(restcontrollers.PermissionReadEmployees, employeeController.SearchEmployees))
+
+		v1.GET("/employees/:id", restcontrollers.RequirePermission(restcontrollers.PermissionReadEmployees, employeeController.FetchEmployee))
+
+		v1.GET("/employees", restcontrollers.RequirePermission(restcontrollers.PermissionReadEmployees, employeeController.FetchEmployees))
+
+		v1.GET("/employees:method", restcontrollers.CustomMethods(map[string]gin.HandlerFunc{
+			"export":        restcontrollers.RequirePermission(restcontrollers.PermissionReadEmployees, employeeController.ExportEmployees),
+			"orgchart":      restcontrollers.RequirePermission(restcontrollers.PermissionReadEmployees, employeeController.ExportOrgChart),
+			"salary-report": restcontrollers.RequirePermission(restcontrollers.PermissionReadSalaries, employeeController.FetchSalaryReport),
+		}))
+
+		v1.GET("/employees/:id/reports", restcontrollers.RequirePermission(restcontrollers.PermissionReadEmployees, employeeController.FetchDirectReports))
+
+		v1.GET("/employees/:id/chain", restcontrollers.RequirePermission(restcontrollers.PermissionReadEmployees, employeeController.FetchManagementChain))
+
+		v1.GET("/employees/:id/subtree", restcontrollers.RequirePermission(restcontrollers.PermissionReadEmployees, employeeController.FetchReportingSubtree))
+
+		v1.GET("/employees/:id/salary-changes", restcontrollers.RequirePermission(restcontrollers.PermissionReadSalaries, employeeController.FetchSalaryChanges))
+
+		v1.POST("/employees/:id/salary-changes", restcontrollers.RequirePermission(restcontrollers.PermissionWriteSalaries, employeeController.CreateSalaryChange))
+
+		v1.DELETE("/employees/:id/salary-changes/:change_id", restcontrollers.RequirePermission(restcontrollers.PermissionWriteSalaries, employeeController.DeleteSalaryChange))
+
+		v1.GET("/employees/:id/audit", restcontrollers.RequirePermission(restcontrollers.PermissionReadAudit, employeeController.FetchEmployeeAudit))
+
+		v1.GET("/audit", restcontrollers.RequirePermission(restcontrollers.PermissionReadAudit, employeeController.FetchAuditEntries))
 
-		v1.GET("/employees/:id", employeeController.FetchEmployee)
+		v1.PUT("/employees/:id", restcontrollers.RequirePermission(restcontrollers.PermissionWriteEmployees, employeeController.UpdateEmployee))
 
-		v1.GET("/employees", employeeController.FetchEmployees)
+		v1.PATCH("/employees/:id", restcontrollers.RequirePermission(restcontrollers.PermissionWriteEmployees, employeeController.PatchEmployee))
 
-		v1.PUT("/employees/:id", employeeController.UpdateEmployee)
+		v1.DELETE("/employees/:id", restcontrollers.RequirePermission(restcontrollers.PermissionDeleteEmployees, employeeController.DeleteEmployee))
 
-		v1.DELETE("/employees/:id", employeeController.DeleteEmployee)
+		v1.POST("/employees/:id/restore", restcontrollers.RequirePermission(restcontrollers.PermissionDeleteEmployees, employeeController.RestoreEmployee))
 
-		v1.POST("/employees/random", employeeController.PushEmployee)
+		v1.POST("/employees/random", restcontrollers.RequirePermission(restcontrollers.PermissionGenerateEmployees, employeeController.PushEmployee))
+
+		v1.POST("/employees:method", restcontrollers.CustomMethods(map[string]gin.HandlerFunc{
+			"batch":  restcontrollers.RequirePermission(restcontrollers.PermissionWriteEmployees, employeeController.BatchEmployees),
+			"import": restcontrollers.RequirePermission(restcontrollers.PermissionWriteEmployees, employeeController.ImportEmployees),
+		}))
+
+	}
+	{
+
+		v1.POST("/departments", restcontrollers.RequirePermission(restcontrollers.PermissionWriteDepartments, departmentController.CreateDepartment))
+
+		v1.GET("/departments/:id", restcontrollers.RequirePermission(restcontrollers.PermissionReadDepartments, departmentController.FetchDepartment))
+
+		v1.GET("/departments", restcontrollers.RequirePermission(restcontrollers.PermissionReadDepartments, departmentController.FetchDepartments))
+
+		v1.PUT("/departments/:id", restcontrollers.RequirePermission(restcontrollers.PermissionWriteDepartments, departmentController.UpdateDepartment))
+
+		v1.DELETE("/departments/:id", restcontrollers.RequirePermission(restcontrollers.PermissionDeleteDepartments, departmentController.DeleteDepartment))
+
+		v1.GET("/departments/:id/employees", restcontrollers.RequirePermission(restcontrollers.PermissionReadEmployees, departmentController.FetchDepartmentEmployees))
+
+	}
+	admin := v1.Group("/admin", restcontrollers.RequireAdminKey(adminKey))
+	{
+
+		admin.DELETE("/employees/:id", restcontrollers.RequirePermission(restcontrollers.PermissionDeleteEmployees, employeeController.PurgeEmployee))
 
 	}
 	return router
 }
 
+// newControllers wires the employee and department controllers to the configured database
+func newControllers() (*restcontrollers.EmployeeController, *restcontrollers.DepartmentController, error) {
+	sqlClient, err := sqls.InitGORMDB()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := prepareSchema(sqlClient); err != nil {
+		return nil, nil, err
+	}
+	employeeDao, err := daos.NewEmployeeDao(sqlClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	employeeService := services.NewEmployeeService(employeeDao)
+	departmentService := services.NewDepartmentService(daos.NewDepartmentDao(sqlClient))
+	return restcontrollers.NewEmployeeController(employeeService), restcontrollers.NewDepartmentController(departmentService, employeeService), nil
+}
+
 //	@title			employee-service
 //	@version		1.0
 //	@description	Testing Swagger APIs.
@@ -61,7 +163,22 @@ func ServeRoutes() *gin.Engine {
 //	@host			localhost:8000
 //	@BasePath		/v1
 //	@schemes		http
+//
+//	@security					BearerAuth
+//
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				"Bearer " followed by a JWT signed with HS256, RS256 or ES256, with a subject and an expiry
+//
+//	@securityDefinitions.apikey	AdminKey
+//	@in							header
+//	@name						X-Admin-Key
+//	@description				value of the ADMIN_API_KEY environment variable
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
 
 	// rest server configuration
 	router := ServeRoutes()
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls/migrations"
+	log "github.com/sirupsen/logrus"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = `usage: main migrate <command>
+
+commands:
+  up [version]   apply pending migrations, up to version when given
+  down [steps]   roll back the last applied migration, or the last steps migrations
+  status         list migrations and whether they are applied
+  verify         check checksums and fail when migrations are pending`
+
+// prepareSchema applies pending migrations at startup, or only verifies them when
+// auto migration is disabled or the database is read-only
+func prepareSchema(sqlClient *sqls.SQLClient) error {
+	migrator, err := migrations.NewMigrator(sqlClient)
+	if err != nil {
+		return err
+	}
+	if !sqlClient.AutoMigrate || sqlClient.ReadOnly() {
+		return migrator.Verify()
+	}
+	return migrator.Up(0)
+}
+
+// runMigrate implements the migrate sub-command and returns the process exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	argument := int64(0)
+	if len(args) == 2 {
+		var err error
+		if argument, err = strconv.ParseInt(args[1], 10, 64); err != nil || argument < 0 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+	}
+
+	sqlClient, err := sqls.InitGORMDB()
+	if err != nil {
+		log.Errorf("error occurred: %v", err)
+		return 1
+	}
+	migrator, err := migrations.NewMigrator(sqlClient)
+	if err != nil {
+		log.Errorf("error occurred: %v", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "up":
+		err = migrator.Up(argument)
+	case "down":
+		if argument == 0 {
+			argument = 1
+		}
+		err = migrator.Down(int(argument))
+	case "status":
+		var statuses []*migrations.MigrationStatus
+		if statuses, err = migrator.Status(); err == nil {
+			for _, status := range statuses {
+				appliedAt := "pending"
+				if status.Applied {
+					appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+				}
+				fmt.Printf("%04d  %-40s  %s\n", status.Version, status.Name, appliedAt)
+			}
+		}
+	case "verify":
+		err = migrator.Verify()
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	if err != nil {
+		log.Errorf("error occurred: %v", err)
+		return 1
+	}
+	return 0
+}
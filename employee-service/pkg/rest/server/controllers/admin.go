@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminKeyHeader carries the key that unlocks the admin endpoints
+const AdminKeyHeader = "X-Admin-Key"
+
+// RequireAdminKey only lets requests through that carry key in the X-Admin-Key header,
+// with an empty key every request is refused
+func RequireAdminKey(key string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		given := context.GetHeader(AdminKeyHeader)
+		if len(key) == 0 || subtle.ConstantTimeCompare([]byte(given), []byte(key)) != 1 {
+			abortWithProblem(context, problemUnauthorized, errors.New("missing or wrong admin key"))
+			return
+		}
+		context.Next()
+	}
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+const (
+	// subjectKey stores the subject of the bearer token in the gin context
+	subjectKey = "subject"
+	// claimsKey stores the claims of the bearer token in the gin context
+	claimsKey = "claims"
+)
+
+// Authenticate only lets requests through that carry a bearer token verifier accepts, and puts the subject and
+// the claims of the token into the gin context. With an unconfigured verifier every request is refused.
+func Authenticate(verifier *TokenVerifier) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		scheme, token, _ := strings.Cut(context.GetHeader("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") || len(strings.TrimSpace(token)) == 0 {
+			context.Header("WWW-Authenticate", `Bearer realm="employee-service"`)
+			abortWithProblem(context, problemUnauthorized, errors.New("missing bearer token"))
+			return
+		}
+		if !verifier.Configured() {
+			context.Header("WWW-Authenticate", `Bearer realm="employee-service", error="invalid_token"`)
+			abortWithProblem(context, problemUnauthorized, errors.New("no tokens are accepted, authentication is not configured"))
+			return
+		}
+		subject, claims, err := verifier.Verify(strings.TrimSpace(token))
+		if err != nil {
+			context.Header("WWW-Authenticate", `Bearer realm="employee-service", error="invalid_token"`)
+			abortWithProblem(context, problemUnauthorized, err)
+			return
+		}
+		context.Set(subjectKey, subject)
+		context.Set(claimsKey, claims)
+		context.Next()
+	}
+}
+
+// TokenSubject returns the subject of the bearer token Authenticate accepted, empty for unauthenticated routes
+func TokenSubject(context *gin.Context) string {
+	return context.GetString(subjectKey)
+}
+
+// TokenClaims returns the claims of the bearer token Authenticate accepted, nil for unauthenticated routes
+func TokenClaims(context *gin.Context) Claims {
+	claims, _ := context.Get(claimsKey)
+	tokenClaims, _ := claims.(Claims)
+	return tokenClaims
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Permission allows an operation on a kind of resource, roles are granted permissions by the policy
+type Permission string
+
+const (
+	PermissionReadEmployees     Permission = "employees:read"
+	PermissionWriteEmployees    Permission = "employees:write"
+	PermissionDeleteEmployees   Permission = "employees:delete"
+	PermissionGenerateEmployees Permission = "employees:generate"
+	PermissionReadSalaries      Permission = "salaries:read"
+	PermissionWriteSalaries     Permission = "salaries:write"
+	PermissionReadAudit         Permission = "audit:read"
+	PermissionReadDepartments   Permission = "departments:read"
+	PermissionWriteDepartments  Permission = "departments:write"
+	PermissionDeleteDepartments Permission = "departments:delete"
+)
+
+// Permissions lists every permission a policy can grant
+var Permissions = []Permission{
+	PermissionReadEmployees,
+	PermissionWriteEmployees,
+	PermissionDeleteEmployees,
+	PermissionGenerateEmployees,
+	PermissionReadSalaries,
+	PermissionWriteSalaries,
+	PermissionReadAudit,
+	PermissionReadDepartments,
+	PermissionWriteDepartments,
+	PermissionDeleteDepartments,
+}
+
+// defaultRolesClaim is the claim of the bearer token that lists the roles of the caller
+const defaultRolesClaim = "roles"
+
+const (
+	// permissionsKey stores the permissions of the caller in the gin context
+	permissionsKey = "permissions"
+	// rolesKey stores the roles of the caller in the gin context, for the forbidden problems
+	rolesKey = "roles"
+)
+
+// Policy grants permissions to roles, the roles of a caller are taken from a claim of their bearer token
+type Policy struct {
+	// RolesClaim names the claim with the roles, an array of strings or a single string, roles by default
+	RolesClaim string                  `json:"roles_claim"`
+	Roles      map[string][]Permission `json:"roles"`
+}
+
+// permissionError tells which permission the caller lacks, the forbidden problem lists it
+type permissionError struct {
+	permission Permission
+	roles      []string
+}
+
+func (err *permissionError) Error() string {
+	if len(err.roles) == 0 {
+		return fmt.Sprintf("no role grants the permission %s", err.permission)
+	}
+	return fmt.Sprintf("the roles %s do not grant the permission %s", strings.Join(err.roles, ", "), err.permission)
+}
+
+// LoadPolicy reads a policy from a JSON file, refusing permissions it does not know
+func LoadPolicy(name string) (*Policy, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	policy := &Policy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(policy.RolesClaim) == 0 {
+		policy.RolesClaim = defaultRolesClaim
+	}
+	for role, permissions := range policy.Roles {
+		for _, permission := range permissions {
+			if !slices.Contains(Permissions, permission) {
+				return nil, fmt.Errorf("%s: role %q has unknown permission %q", name, role, permission)
+			}
+		}
+	}
+	return policy, nil
+}
+
+// roles returns the roles listed by the roles claim of claims, sorted
+func (policy *Policy) roles(claims Claims) []string {
+	var roles []string
+	switch value := claims[policy.RolesClaim].(type) {
+	case string:
+		roles = strings.Fields(value)
+	case []interface{}:
+		for _, role := range value {
+			if role, ok := role.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+	}
+	slices.Sort(roles)
+	return roles
+}
+
+// Authorize resolves the permissions the roles of the caller are granted, which RequirePermission and the
+// controllers check. It goes after Authenticate.
+func Authorize(policy *Policy) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		roles := policy.roles(TokenClaims(context))
+		permissions := map[Permission]bool{}
+		for _, role := range roles {
+			for _, permission := range policy.Roles[role] {
+				permissions[permission] = true
+			}
+		}
+		context.Set(permissionsKey, permissions)
+		context.Set(rolesKey, roles)
+		// the permissions decide which fields responses mask, so caches have to keep them apart per caller
+		context.Writer.Header().Add("Vary", "Authorization")
+		context.Next()
+	}
+}
+
+// RequirePermission only lets callers granted permission through to handler, others get a forbidden problem
+func RequirePermission(permission Permission, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if !checkPermission(context, permission) {
+			return
+		}
+		handler(context)
+	}
+}
+
+// checkPermission tells whether the caller is granted permission, and aborts with a forbidden problem otherwise
+func checkPermission(context *gin.Context, permission Permission) bool {
+	if granted(context, permission) {
+		return true
+	}
+	abortWithMissingPermission(context, permission)
+	return false
+}
+
+// abortWithMissingPermission answers with a forbidden problem naming the permission the caller lacks
+func abortWithMissingPermission(context *gin.Context, permission Permission) {
+	roles, _ := context.Get(rolesKey)
+	callerRoles, _ := roles.([]string)
+	abortWithProblem(context, problemForbidden, &permissionError{permission: permission, roles: callerRoles})
+}
+
+// granted tells whether the caller is granted permission. Callers on routes without Authorize are granted nothing.
+func granted(context *gin.Context, permission Permission) bool {
+	value, _ := context.Get(permissionsKey)
+	permissions, _ := value.(map[Permission]bool)
+	return permissions[permission]
+}
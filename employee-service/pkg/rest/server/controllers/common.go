@@ -1,5 +0,0 @@
-package controllers
-
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
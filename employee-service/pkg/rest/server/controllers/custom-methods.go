@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// CustomMethods serves "/resource:method" paths such as /employees:batch. gin cannot register the colon
+// literally, so the route is registered as "/resource:method" and the method arrives as a path parameter.
+func CustomMethods(handlers map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		method := strings.TrimPrefix(context.Param("method"), ":")
+		handler, ok := handlers[method]
+		if !ok {
+			abortWithProblem(context, problemNotFound, fmt.Errorf("unknown method %q", method))
+			return
+		}
+		handler(context)
+	}
+}
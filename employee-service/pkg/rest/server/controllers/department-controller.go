@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// DepartmentList is a page of departments with its position in the whole listing
+type DepartmentList struct {
+	Data  []*models.Department `json:"data"`
+	Page  PageInfo             `json:"page"`
+	Links PageLinks            `json:"links"`
+}
+
+type DepartmentController struct {
+	departmentService *services.DepartmentService
+	employeeService   *services.EmployeeService
+}
+
+func NewDepartmentController(departmentService *services.DepartmentService, employeeService *services.EmployeeService) *DepartmentController {
+	return &DepartmentController{
+		departmentService: departmentService,
+		employeeService:   employeeService,
+	}
+}
+
+// CreateDepartment creates a new department for the department service
+// @Summary Creates a new department
+// @Description Creates a new department. The name is required, unique and at most 100 characters long, the description at most 1000 characters long.
+// @Tags departments
+// @Accept json
+// @Produce json,application/problem+json
+// @Param department body models.Department true "Create department"
+// @Success 201 {object} models.Department
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 409 {object} Problem "another department has the same name"
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /departments [post]
+func (departmentController *DepartmentController) CreateDepartment(context *gin.Context) {
+	// validate input
+	var input models.Department
+	if err := context.ShouldBindJSON(&input); err != nil {
+		abortWithBindingError(context, err)
+		return
+	}
+
+	// trigger department creation
+	departmentCreated, err := departmentController.departmentService.CreateDepartment(&input)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusCreated, departmentCreated)
+}
+
+// FetchDepartment fetches a single department for the department service
+// @Summary Fetches a single department
+// @Description Fetches a single department
+// @Tags departments
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "id"
+// @Success 200 {object} models.Department
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /departments/{id} [get]
+func (departmentController *DepartmentController) FetchDepartment(context *gin.Context) {
+	id, err := departmentID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// trigger department fetching
+	department, err := departmentController.departmentService.GetDepartment(id)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, department)
+}
+
+// FetchDepartments fetches all departments for the department service
+// @Summary Fetches all departments
+// @Description Fetches all departments ordered by name
+// @Tags departments
+// @Accept json
+// @Produce json,application/problem+json
+// @Param page query int false "page"
+// @Param page_size query int false "page_size, at most 100"
+// @Success 200 {object} DepartmentList
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /departments [get]
+func (departmentController *DepartmentController) FetchDepartments(context *gin.Context) {
+	query := context.Request.URL.Query()
+	page, limit := parsePage(query), parsePageSize(query)
+
+	// trigger department fetching
+	departments, total, err := departmentController.departmentService.GetDepartments(page, limit)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	departmentList := DepartmentList{Data: departments}
+	if departmentList.Data == nil {
+		departmentList.Data = []*models.Department{}
+	}
+	departmentList.Page, departmentList.Links = offsetPage(context, page, limit, total)
+	context.JSON(http.StatusOK, departmentList)
+}
+
+// UpdateDepartment updates a single department for the department service
+// @Summary Updates a single department
+// @Description Replaces the name and description of a single department, following the same rules as creating one
+// @Tags departments
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "id"
+// @Param department body models.Department true "Update department"
+// @Success 200 {object} models.Department
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem "another department has the same name"
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /departments/{id} [put]
+func (departmentController *DepartmentController) UpdateDepartment(context *gin.Context) {
+	// validate input
+	var input models.Department
+	if err := context.ShouldBindJSON(&input); err != nil {
+		abortWithBindingError(context, err)
+		return
+	}
+
+	id, err := departmentID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// trigger department update
+	department, err := departmentController.departmentService.UpdateDepartment(id, &input)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, department)
+}
+
+// DeleteDepartment deletes a single department for the department service
+// @Summary Deletes a single department
+// @Description Deletes a single department for good. A department with employees can only be deleted when they are reassigned to another department with reassign_to, deleted employees are detached from it otherwise.
+// @Tags departments
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "id"
+// @Param reassign_to query int false "id of the department the employees are moved to"
+// @Success 204 {object} interface{}
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem "the department has employees and reassign_to is missing"
+// @Failure 422 {object} Problem "reassign_to is not another existing department"
+// @Failure 500 {object} Problem
+// @Router /departments/{id} [delete]
+func (departmentController *DepartmentController) DeleteDepartment(context *gin.Context) {
+	id, err := departmentID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// validate input
+	var reassignTo int64
+	if value, ok := context.GetQuery("reassign_to"); ok {
+		if reassignTo, err = strconv.ParseInt(value, 10, 64); err != nil || reassignTo <= 0 {
+			abortWithProblem(context, problemBadRequest, fmt.Errorf("invalid reassign_to %q, expected a positive integer", value))
+			return
+		}
+	}
+
+	// trigger department deletion
+	if err := departmentController.departmentService.DeleteDepartment(id, reassignTo, auditContext(context)); err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusNoContent, gin.H{})
+}
+
+// FetchDepartmentEmployees fetches the employees of a single department for the department service
+// @Summary Fetches the employees of a single department
+// @Description Fetches the employees of a single department, paged, filtered and sorted like all employees
+// @Tags departments
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "id"
+// @Param page query int false "page, ignored when cursor is given"
+// @Param page_size query int false "page_size, at most 100"
+// @Param cursor query string false "opaque keyset cursor from page.next_cursor or page.prev_cursor, pass it empty to start keyset pagination"
+// @Param name_prefix query string false "case-insensitive name prefix"
+// @Param name_contains query string false "case-insensitive name substring"
+// @Param position query []string false "exact position, repeat for any of several" collectionFormat(multi)
+// @Param salary_min query number false "minimum salary, inclusive, with at most 4 decimal places, needs salaries:read"
+// @Param salary_max query number false "maximum salary, inclusive, with at most 4 decimal places, needs salaries:read"
+// @Param currency query []string false "ISO 4217 code of the salary currency, repeat for any of several" collectionFormat(multi)
+// @Param sort query string false "comma separated fields out of id, name, position, salary, created_at, updated_at, prefixed with - for descending order; salary needs salaries:read"
+// @Success 200 {object} EmployeeList
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /departments/{id}/employees [get]
+func (departmentController *DepartmentController) FetchDepartmentEmployees(context *gin.Context) {
+	id, err := departmentID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// validate input
+	query, err := parseEmployeeQuery(context.Request.URL.Query())
+	if err != nil {
+		abortWithQueryError(context, err)
+		return
+	}
+	query.Filter.DepartmentIDs = []uint{uint(id)}
+	if !checkSalaryQuery(context, &query.Filter, query.Sort) {
+		return
+	}
+
+	// trigger employee fetching, an unknown department is a 404 rather than an empty list
+	if _, err := departmentController.departmentService.GetDepartment(id); err != nil {
+		abortWithError(context, err)
+		return
+	}
+	page, err := departmentController.employeeService.GetEmployees(query)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, newEmployeeList(context, query, page))
+}
+
+// departmentID reads the id path parameter
+func departmentID(context *gin.Context) (int64, error) {
+	id, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid department id %q, expected a positive integer", context.Param("id"))
+	}
+	return id, nil
+}
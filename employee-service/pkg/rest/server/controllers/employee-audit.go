@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ActorHeader names who makes a request on routes without authentication, authenticated requests are attributed
+// to the subject of their bearer token instead
+const ActorHeader = "X-Actor"
+
+// maxActorLength bounds the actors taken from clients and tokens, longer ones are cut
+const maxActorLength = 191
+
+// AuditLog is a page of the audit trail, oldest changes first
+type AuditLog struct {
+	Data  []*models.AuditEntry `json:"data"`
+	Page  PageInfo             `json:"page"`
+	Links PageLinks            `json:"links"`
+}
+
+// auditContext tells who makes the changes of a request, for the audit trail
+func auditContext(context *gin.Context) models.AuditContext {
+	actor := TokenSubject(context)
+	if len(actor) == 0 {
+		actor = context.GetHeader(ActorHeader)
+	}
+	if len(actor) > maxActorLength {
+		actor = strings.ToValidUTF8(actor[:maxActorLength], "")
+	}
+	return models.AuditContext{Actor: actor, RequestID: requestID(context)}
+}
+
+// FetchEmployeeAudit fetches the audit trail of a single employee for the employee service
+// @Summary Fetches the audit trail of a single employee
+// @Description Fetches every create, update, delete, restore and purge of the employee in the order they were made, with the fields each of them changed. The trail outlives the employee, purged employees included.
+// @Tags employees
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "id"
+// @Param page query int false "page"
+// @Param page_size query int false "page_size, at most 100"
+// @Success 200 {object} AuditLog
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /employees/{id}/audit [get]
+func (employeeController *EmployeeController) FetchEmployeeAudit(context *gin.Context) {
+	id, err := employeeID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+	values := context.Request.URL.Query()
+	query := &models.AuditQuery{Page: parsePage(values), Limit: parsePageSize(values)}
+
+	// trigger audit trail fetching
+	page, err := employeeController.employeeService.GetEmployeeAudit(id, query)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, newAuditLog(context, query, page))
+}
+
+// FetchAuditEntries fetches the audit trail of all employees for the employee service
+// @Summary Fetches the audit trail of all employees
+// @Description Fetches the changes of employees matching every given filter in the order they were made. Changes the service makes on its own, like scheduled salary changes taking effect, have no actor.
+// @Tags audit
+// @Accept json
+// @Produce json,application/problem+json
+// @Param page query int false "page"
+// @Param page_size query int false "page_size, at most 100"
+// @Param employee_id query []int false "id of the employee, repeat for any of several" collectionFormat(multi)
+// @Param actor query string false "who made the change, the subject of their bearer token"
+// @Param operation query []string false "create, update, delete, restore or purge, repeat for any of several" collectionFormat(multi)
+// @Param request_id query string false "X-Request-ID of the request that made the change"
+// @Param from query string false "changed on or after, date or RFC 3339 timestamp"
+// @Param to query string false "changed on or before, date or RFC 3339 timestamp"
+// @Success 200 {object} AuditLog
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /audit [get]
+func (employeeController *EmployeeController) FetchAuditEntries(context *gin.Context) {
+	// validate input
+	query, err := parseAuditQuery(context.Request.URL.Query())
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// trigger audit trail fetching
+	page, err := employeeController.employeeService.GetAuditEntries(query)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, newAuditLog(context, query, page))
+}
+
+// parseAuditQuery reads the paging and filtering parameters of the audit trail
+func parseAuditQuery(query url.Values) (*models.AuditQuery, error) {
+	auditQuery := &models.AuditQuery{
+		Actor:     query.Get("actor"),
+		RequestID: query.Get("request_id"),
+		Page:      parsePage(query),
+		Limit:     parsePageSize(query),
+	}
+	for _, operation := range query["operation"] {
+		if !slices.Contains(models.AuditOperations, operation) {
+			return nil, fmt.Errorf("invalid operation %q, expected one of %s", operation, strings.Join(models.AuditOperations, ", "))
+		}
+		auditQuery.Operations = append(auditQuery.Operations, operation)
+	}
+
+	var err error
+	if auditQuery.EmployeeIDs, err = parseIDsParam(query, "employee_id"); err != nil {
+		return nil, err
+	}
+	if auditQuery.From, err = parseTimeParam(query, "from", false); err != nil {
+		return nil, err
+	}
+	if auditQuery.To, err = parseTimeParam(query, "to", true); err != nil {
+		return nil, err
+	}
+	return auditQuery, nil
+}
+
+// newAuditLog wraps a page of the audit trail with the paging information of query
+func newAuditLog(context *gin.Context, query *models.AuditQuery, page *models.AuditPage) AuditLog {
+	auditLog := AuditLog{Data: redactAuditEntries(context, page.Entries)}
+	if auditLog.Data == nil {
+		auditLog.Data = []*models.AuditEntry{}
+	}
+	auditLog.Page, auditLog.Links = offsetPage(context, query.Page, query.Limit, page.Total)
+	return auditLog
+}
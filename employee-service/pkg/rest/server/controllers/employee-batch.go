@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+const (
+	batchModeAtomic     = "atomic"
+	batchModeBestEffort = "best_effort"
+)
+
+// EmployeeBatchRequest is a list of operations applied in order
+type EmployeeBatchRequest struct {
+	// Mode is atomic, all operations or none, or best_effort, every operation on its own. Defaults to atomic.
+	Mode       string                           `json:"mode" enums:"atomic,best_effort"`
+	Operations []*models.EmployeeBatchOperation `json:"operations"`
+}
+
+// EmployeeBatchItem is the outcome of the operation at Index
+type EmployeeBatchItem struct {
+	Index  int                        `json:"index"`
+	Op     models.EmployeeBatchOp     `json:"op"`
+	Status models.EmployeeBatchStatus `json:"status" enums:"applied,failed,rolled_back,skipped"`
+	// Code is the status the operation would have had as a single request,
+	// 424 for operations undone or skipped because another one failed
+	Code  int    `json:"code"`
+	Error string `json:"error,omitempty"`
+	// Errors lists the invalid fields of the employee of a 422 operation
+	Errors   []*models.FieldError `json:"errors,omitempty"`
+	Employee *models.Employee     `json:"employee,omitempty"`
+}
+
+// EmployeeBatchResponse reports every operation of a batch
+type EmployeeBatchResponse struct {
+	Mode    string               `json:"mode"`
+	Applied int                  `json:"applied"`
+	Failed  int                  `json:"failed"`
+	Results []*EmployeeBatchItem `json:"results"`
+}
+
+// BatchEmployees creates, updates and deletes employees in one request for the employee service
+// @Summary Creates, updates and deletes employees in one request
+// @Description Applies up to 1000 operations in order. In atomic mode a failing operation undoes the whole batch and its status becomes the response status, in best_effort mode each operation succeeds or fails on its own and partial failures answer 207. Delete operations need the employees:delete permission on top of employees:write, setting salaries and currencies like creating and updating single employees needs salaries:write.
+// @Tags employees
+// @Accept json
+// @Produce json,application/problem+json
+// @Param batch body EmployeeBatchRequest true "operations"
+// @Success 200 {object} EmployeeBatchResponse
+// @Success 207 {object} EmployeeBatchResponse "best_effort batch with failed operations"
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /employees:batch [post]
+func (employeeController *EmployeeController) BatchEmployees(context *gin.Context) {
+	// validate input
+	var input EmployeeBatchRequest
+	if err := context.ShouldBindJSON(&input); err != nil {
+		abortWithBindingError(context, err)
+		return
+	}
+	if len(input.Mode) == 0 {
+		input.Mode = batchModeAtomic
+	}
+	var err error
+	switch {
+	case input.Mode != batchModeAtomic && input.Mode != batchModeBestEffort:
+		err = fmt.Errorf("invalid mode %q, expected %s or %s", input.Mode, batchModeAtomic, batchModeBestEffort)
+	case len(input.Operations) == 0:
+		err = errors.New("operations must not be empty")
+	case len(input.Operations) > models.MaxEmployeeBatchOperations:
+		err = fmt.Errorf("too many operations, at most %d are allowed", models.MaxEmployeeBatchOperations)
+	}
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+	for _, operation := range input.Operations {
+		if operation == nil {
+			abortWithProblem(context, problemBadRequest, errors.New("operations must not be null"))
+			return
+		}
+	}
+	// deleting and setting salaries take more than the write permission the route asks for
+	for _, operation := range input.Operations {
+		switch {
+		case operation.Op == models.EmployeeBatchDelete && !checkPermission(context, PermissionDeleteEmployees):
+			return
+		case operation.Employee == nil:
+		case operation.Op == models.EmployeeBatchCreate && !checkSalaryCreate(context, operation.Employee):
+			return
+		case operation.Op == models.EmployeeBatchUpdate && !checkSalaryUpdate(context, operation.Employee):
+			return
+		}
+	}
+
+	// trigger employee batch
+	results, err := employeeController.employeeService.WithAudit(auditContext(context)).BatchEmployees(input.Operations, input.Mode == batchModeAtomic)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	response := EmployeeBatchResponse{Mode: input.Mode, Results: make([]*EmployeeBatchItem, 0, len(results))}
+	status := http.StatusOK
+	for _, result := range results {
+		item := &EmployeeBatchItem{Index: result.Index, Op: result.Op, Status: result.Status, Employee: redactEmployee(context, result.Employee)}
+		switch result.Status {
+		case models.EmployeeBatchApplied:
+			response.Applied++
+			item.Code = batchSuccessCode(result.Op)
+		case models.EmployeeBatchFailed:
+			response.Failed++
+			kind, detail := problemOf(result.Err)
+			item.Code, item.Error = kind.status, detail
+			if kind == problemInternal {
+				log.WithField("request_id", requestID(context)).Error(result.Err)
+			}
+			var validationError *models.ValidationError
+			if errors.As(result.Err, &validationError) {
+				item.Errors = validationError.Errors
+			}
+			if input.Mode == batchModeAtomic {
+				status = item.Code
+			} else {
+				status = http.StatusMultiStatus
+			}
+		default:
+			item.Code = http.StatusFailedDependency
+		}
+		response.Results = append(response.Results, item)
+	}
+	context.JSON(status, response)
+}
+
+func batchSuccessCode(op models.EmployeeBatchOp) int {
+	switch op {
+	case models.EmployeeBatchCreate:
+		return http.StatusCreated
+	case models.EmployeeBatchDelete:
+		return http.StatusNoContent
+	}
+	return http.StatusOK
+}
@@ -2,11 +2,11 @@ package controllers
 
 import (
 	"errors"
+	"fmt"
 	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
 	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
 	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/services"
 	"github.com/gin-gonic/gin"
-	log "github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"net/http"
@@ -14,49 +14,57 @@ import (
 	"strconv"
 )
 
+// EmployeeList is a page of employees with its position in the whole listing
+type EmployeeList struct {
+	Data  []*models.Employee `json:"data"`
+	Page  PageInfo           `json:"page"`
+	Links PageLinks          `json:"links"`
+}
+
 type EmployeeController struct {
 	employeeService *services.EmployeeService
 }
 
-func NewEmployeeController() (*EmployeeController, error) {
-	employeeService, err := services.NewEmployeeService()
-	if err != nil {
-		return nil, err
-	}
+func NewEmployeeController(employeeService *services.EmployeeService) *EmployeeController {
 	return &EmployeeController{
 		employeeService: employeeService,
-	}, nil
+	}
 }
 
 // CreateEmployee creates a new employee for the employee service
 // @Summary Creates a new employee
-// @Description Creates a new employee
+// @Description Creates a new employee. The name is required and at most 100 characters long, a position has to be in the position catalog and the salary must not be negative. A salary or a currency other than USD needs the salaries:write permission.
 // @Tags employees
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param employee body models.Employee true "Create employee"
 // @Success 201 {object} models.Employee
-// @Failure 422 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Header 201 {string} ETag "entity tag of the employee"
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /employees [post]
 func (employeeController *EmployeeController) CreateEmployee(context *gin.Context) {
 	// validate input
 	var input models.Employee
 	if err := context.ShouldBindJSON(&input); err != nil {
-		log.Error(err)
-		context.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		abortWithBindingError(context, err)
+		return
+	}
+	if !checkSalaryCreate(context, &input) {
 		return
 	}
 
 	// trigger employee creation
-	employeeCreated, err := employeeController.employeeService.CreateEmployee(&input)
+	employeeCreated, err := employeeController.employeeService.WithAudit(auditContext(context)).CreateEmployee(&input)
 	if err != nil {
-		log.Error(err)
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		abortWithError(context, err)
 		return
 	}
 
-	context.JSON(http.StatusCreated, employeeCreated)
+	context.Header("ETag", employeeETag(context, employeeCreated))
+	context.JSON(http.StatusCreated, redactEmployee(context, employeeCreated))
 }
 
 // FetchEmployee fetches a single employee for the employee service
@@ -64,29 +72,54 @@ func (employeeController *EmployeeController) CreateEmployee(context *gin.Contex
 // @Description Fetches a single employee
 // @Tags employees
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param id path int true "id"
+// @Param as_of query string false "the employee as it was then, deleted since or not, date (the end of the day) or RFC 3339 timestamp"
+// @Param If-None-Match header string false "entity tags, 304 when one of them is current"
+// @Param If-Match header string false "entity tags, 412 when none of them is current"
 // @Success 200 {object} models.Employee
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Header 200,304 {string} ETag "entity tag of the employee"
+// @Success 304 "not modified"
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /employees/{id} [get]
 func (employeeController *EmployeeController) FetchEmployee(context *gin.Context) {
-	id, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	id, err := employeeID(context)
 	if err != nil {
-		log.Error(err)
-		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// validate input
+	asOf, err := parseTimeParam(context.Request.URL.Query(), "as_of", true)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
 		return
 	}
 
 	// trigger employee fetching
-	employee, err := employeeController.employeeService.GetEmployee(id)
+	var employee *models.Employee
+	if asOf != nil {
+		employee, err = employeeController.employeeService.GetEmployeeAsOf(id, *asOf)
+	} else {
+		employee, err = employeeController.employeeService.GetEmployee(id)
+	}
 	if err != nil {
-		log.Error(err)
-		if errors.Is(err, sqls.ErrNotExists) {
-			context.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-			return
-		}
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		abortWithError(context, err)
+		return
+	}
+
+	if ifMatch := context.GetHeader("If-Match"); len(ifMatch) > 0 && !matchesVersion(ifMatch, employee) {
+		abortWithError(context, sqls.ErrVersionMismatch)
+		return
+	}
+	context.Header("ETag", employeeETag(context, employee))
+	if ifNoneMatch := context.GetHeader("If-None-Match"); len(ifNoneMatch) > 0 && matchesETag(context, ifNoneMatch, employee) {
+		context.Status(http.StatusNotModified)
 		return
 	}
 
@@ -98,7 +131,7 @@ func (employeeController *EmployeeController) FetchEmployee(context *gin.Context
 		currentSpan.SetAttributes(attribute.String("employee.id", strconv.FormatInt(int64(employee.ID), 10)))
 	}
 
-	context.JSON(http.StatusOK, employee)
+	context.JSON(http.StatusOK, redactEmployee(context, employee))
 }
 
 // FetchEmployees fetches all employees for the employee service
@@ -106,98 +139,365 @@ func (employeeController *EmployeeController) FetchEmployee(context *gin.Context
 // @Description Fetches all employees
 // @Tags employees
 // @Accept json
-// @Produce json
-// @Param page query int false "page"
-// @Param page_size query int false "page_size"
-// @Success 200 {array} models.Employee
-// @Failure 500 {object} ErrorResponse
+// @Produce json,application/problem+json
+// @Param page query int false "page, ignored when cursor is given"
+// @Param page_size query int false "page_size, at most 100"
+// @Param cursor query string false "opaque keyset cursor from page.next_cursor or page.prev_cursor, pass it empty to start keyset pagination"
+// @Param name_prefix query string false "case-insensitive name prefix"
+// @Param name_contains query string false "case-insensitive name substring"
+// @Param position query []string false "exact position, repeat for any of several" collectionFormat(multi)
+// @Param department_id query []int false "department id, repeat for any of several" collectionFormat(multi)
+// @Param manager_id query []int false "id of the manager, repeat for the reports of any of several" collectionFormat(multi)
+// @Param salary_min query number false "minimum salary, inclusive, with at most 4 decimal places, needs salaries:read"
+// @Param salary_max query number false "maximum salary, inclusive, with at most 4 decimal places, needs salaries:read"
+// @Param currency query []string false "ISO 4217 code of the salary currency, repeat for any of several" collectionFormat(multi)
+// @Param created_from query string false "created on or after, date or RFC 3339 timestamp"
+// @Param created_to query string false "created on or before, date or RFC 3339 timestamp"
+// @Param updated_from query string false "updated on or after, date or RFC 3339 timestamp"
+// @Param updated_to query string false "updated on or before, date or RFC 3339 timestamp"
+// @Param sort query string false "comma separated fields out of id, name, position, salary, created_at, updated_at, prefixed with - for descending order; salary needs salaries:read"
+// @Param as_of query string false "the employees as they were then, deleted since or not, date (the end of the day) or RFC 3339 timestamp; filters apply to the past values and paging is by page only"
+// @Success 200 {object} EmployeeList
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /employees [get]
 func (employeeController *EmployeeController) FetchEmployees(context *gin.Context) {
+	// validate input
+	query, err := parseEmployeeQuery(context.Request.URL.Query())
+	if err != nil {
+		abortWithQueryError(context, err)
+		return
+	}
+	if query.AsOf, err = parseTimeParam(context.Request.URL.Query(), "as_of", true); err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+	if query.AsOf != nil && query.Cursor != nil {
+		abortWithProblem(context, problemBadRequest, errors.New("as_of cannot be combined with cursor, page by page number instead"))
+		return
+	}
+	if !checkSalaryQuery(context, &query.Filter, query.Sort) {
+		return
+	}
+
 	// trigger employee fetching
-	query := context.Request.URL.Query()
-	page, err := strconv.Atoi(query.Get("page"))
+	page, err := employeeController.employeeService.GetEmployees(query)
 	if err != nil {
-		page = 1
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, newEmployeeList(context, query, page))
+}
+
+// newEmployeeList wraps a page of employees with the paging information of query
+func newEmployeeList(context *gin.Context, query *models.EmployeeQuery, page *models.EmployeePage) EmployeeList {
+	employeeList := EmployeeList{Data: redactEmployees(context, page.Employees)}
+	if employeeList.Data == nil {
+		employeeList.Data = []*models.Employee{}
+	}
+	if query.Cursor == nil {
+		employeeList.Page, employeeList.Links = offsetPage(context, query.Page, query.Limit, page.Total)
+	} else {
+		var nextCursor, prevCursor string
+		if len(page.Employees) > 0 {
+			if page.HasNext {
+				nextCursor = models.NewEmployeeCursor(page.Employees[len(page.Employees)-1], query.Sort, false).Encode()
+			}
+			if page.HasPrev {
+				prevCursor = models.NewEmployeeCursor(page.Employees[0], query.Sort, true).Encode()
+			}
+		} else if !query.Cursor.IsStart() {
+			// nothing left on this side of the cursor, offer to turn around at the same position
+			turned := *query.Cursor
+			turned.Backward = !turned.Backward
+			if turned.Backward {
+				prevCursor = turned.Encode()
+			} else {
+				nextCursor = turned.Encode()
+			}
+		}
+		employeeList.Page, employeeList.Links = cursorPage(context, query.Limit, page.Total, nextCursor, prevCursor)
+	}
+	return employeeList
+}
+
+// EmployeeSearchResults lists the best matching employees first
+type EmployeeSearchResults struct {
+	Data []*models.EmployeeSearchHit `json:"data"`
+	// Engine is fts5 for the full-text index and like for the pattern matching fallback
+	Engine string `json:"engine"`
+}
+
+// SearchEmployees searches employees by name and position for the employee service
+// @Summary Searches employees
+// @Description Ranked full-text search over name and position, matching words by prefix. Matched fragments are wrapped in <mark></mark> in highlights.
+// @Tags employees
+// @Accept json
+// @Produce json,application/problem+json
+// @Param q query string true "search text"
+// @Param page_size query int false "maximum number of results, at most 100"
+// @Param position query []string false "exact position, repeat for any of several" collectionFormat(multi)
+// @Param department_id query []int false "department id, repeat for any of several" collectionFormat(multi)
+// @Param manager_id query []int false "id of the manager, repeat for the reports of any of several" collectionFormat(multi)
+// @Param salary_min query number false "minimum salary, inclusive, with at most 4 decimal places, needs salaries:read"
+// @Param salary_max query number false "maximum salary, inclusive, with at most 4 decimal places, needs salaries:read"
+// @Param currency query []string false "ISO 4217 code of the salary currency, repeat for any of several" collectionFormat(multi)
+// @Success 200 {object} EmployeeSearchResults
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /employees/search [get]
+func (employeeController *EmployeeController) SearchEmployees(context *gin.Context) {
+	// validate input
+	query := context.Request.URL.Query()
+	terms := models.SearchTerms(query.Get("q"))
+	if len(terms) == 0 {
+		abortWithProblem(context, problemBadRequest, errors.New("q must contain at least one word"))
+		return
 	}
-	limit, err := strconv.Atoi(query.Get("page_size"))
+	filter, err := parseEmployeeFilter(query)
 	if err != nil {
-		limit = 10
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+	if !checkSalaryQuery(context, filter, nil) {
+		return
 	}
-	employees, err := employeeController.employeeService.GetEmployees(page, limit)
+
+	// trigger employee search
+	result, err := employeeController.employeeService.SearchEmployees(&models.EmployeeSearchQuery{
+		Terms:  terms,
+		Filter: *filter,
+		Limit:  parsePageSize(query),
+	})
 	if err != nil {
-		log.Error(err)
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		abortWithError(context, err)
 		return
 	}
-	context.JSON(http.StatusOK, employees)
+	hits := make([]*models.EmployeeSearchHit, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		hits = append(hits, &models.EmployeeSearchHit{Employee: redactEmployee(context, hit.Employee), Score: hit.Score, Highlights: hit.Highlights})
+	}
+	context.JSON(http.StatusOK, EmployeeSearchResults{Data: hits, Engine: result.Engine})
 }
 
 // UpdateEmployee updates a single employee for the employee service
 // @Summary Updates a single employee
-// @Description Updates a single employee
+// @Description Replaces a single employee, following the same rules as creating one. A null salary keeps the stored salary and currency, any other salary needs the salaries:write permission.
 // @Tags employees
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param id path int true "id"
-// @Param employee body models.Employee true "Update employee"
+// @Param employee body models.Employee true "Update employee, a non-zero version has to be the current one"
+// @Param If-Match header string false "entity tag the update is based on, takes precedence over the version in the body"
 // @Success 204 {object} interface{}
-// @Failure 404 {object} ErrorResponse
-// @Failure 422 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Header 204 {string} ETag "entity tag of the updated employee"
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /employees/{id} [put]
 func (employeeController *EmployeeController) UpdateEmployee(context *gin.Context) {
 	// validate input
 	var input models.Employee
 	if err := context.ShouldBindJSON(&input); err != nil {
-		log.Error(err)
-		context.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		abortWithBindingError(context, err)
 		return
 	}
 
-	id, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	id, err := employeeID(context)
 	if err != nil {
-		log.Error(err)
-		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+	if !checkSalaryUpdate(context, &input) {
 		return
 	}
 
-	// trigger employee update
-	if _, err := employeeController.employeeService.UpdateEmployee(id, &input); err != nil {
-		log.Error(err)
-		if errors.Is(err, sqls.ErrNotExists) {
-			context.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	if len(context.GetHeader("If-Match")) > 0 {
+		if input.Version, err = employeeController.ifMatchVersion(context, id); err != nil {
+			abortWithError(context, err)
 			return
 		}
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+
+	// trigger employee update
+	employee, err := employeeController.employeeService.WithAudit(auditContext(context)).UpdateEmployee(id, &input)
+	if err != nil {
+		abortWithError(context, err)
 		return
 	}
 
+	context.Header("ETag", employeeETag(context, employee))
 	context.JSON(http.StatusNoContent, gin.H{})
 }
 
+// PatchEmployee partially updates a single employee for the employee service
+// @Summary Partially updates a single employee
+// @Description Updates only the fields named in a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) document, removed fields are reset to their zero value. Patching the salary or the currency needs the salaries:write permission, testing, copying or moving the salary salaries:read.
+// @Tags employees
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json,application/problem+json
+// @Param id path int true "id"
+// @Param patch body interface{} true "merge patch object or list of JSON Patch operations"
+// @Param If-Match header string false "entity tag the patch is based on"
+// @Success 200 {object} models.Employee
+// @Header 200 {string} ETag "entity tag of the patched employee"
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem
+// @Failure 412 {object} Problem
+// @Failure 415 {object} Problem
+// @Failure 422 {object} Problem "invalid field values, or a patch that does not fit an employee (without errors)"
+// @Failure 500 {object} Problem
+// @Router /employees/{id} [patch]
+func (employeeController *EmployeeController) PatchEmployee(context *gin.Context) {
+	id, err := employeeID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// validate input
+	contentType := context.ContentType()
+	if contentType != models.MergePatchContentType && contentType != models.JSONPatchContentType {
+		context.Header("Accept-Patch", models.MergePatchContentType+", "+models.JSONPatchContentType)
+		abortWithProblem(context, problemUnsupportedMediaType, fmt.Errorf("unsupported content type %q, expected %s or %s", contentType, models.MergePatchContentType, models.JSONPatchContentType))
+		return
+	}
+	document, err := context.GetRawData()
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+	patch := &models.EmployeePatch{ContentType: contentType, Document: document}
+	if !checkSalaryPatch(context, patch) {
+		return
+	}
+
+	// trigger employee patching
+	version, err := employeeController.ifMatchVersion(context, id)
+	var employee *models.Employee
+	if err == nil {
+		employee, err = employeeController.employeeService.WithAudit(auditContext(context)).PatchEmployee(id, version, patch)
+	}
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.Header("ETag", employeeETag(context, employee))
+	context.JSON(http.StatusOK, redactEmployee(context, employee))
+}
+
 // DeleteEmployee deletes a single employee for the employee service
 // @Summary Deletes a single employee
-// @Description Deletes a single employee
+// @Description Soft deletes a single employee, it can be brought back with the restore endpoint
 // @Tags employees
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param id path int true "id"
+// @Param If-Match header string false "entity tag the deletion is based on"
 // @Success 204 {object} interface{}
-// @Failure 404 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem "the employee never existed or was purged"
+// @Failure 410 {object} Problem "the employee is deleted already"
+// @Failure 412 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /employees/{id} [delete]
 func (employeeController *EmployeeController) DeleteEmployee(context *gin.Context) {
-	id, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	id, err := employeeID(context)
 	if err != nil {
-		log.Error(err)
-		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		abortWithProblem(context, problemBadRequest, err)
 		return
 	}
 
 	// trigger employee deletion
-	if err := employeeController.employeeService.DeleteEmployee(id); err != nil {
-		log.Error(err)
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, err := employeeController.ifMatchVersion(context, id)
+	if err == nil {
+		err = employeeController.employeeService.WithAudit(auditContext(context)).DeleteEmployee(id, version)
+	}
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusNoContent, gin.H{})
+}
+
+// RestoreEmployee restores a single deleted employee for the employee service
+// @Summary Restores a single deleted employee
+// @Description Undoes the soft delete of a single employee
+// @Tags employees
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "id"
+// @Param If-Match header string false "entity tag the employee had when it was deleted"
+// @Success 200 {object} models.Employee
+// @Header 200 {string} ETag "entity tag of the restored employee"
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem "the employee never existed or was purged"
+// @Failure 409 {object} Problem "the employee is not deleted"
+// @Failure 412 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /employees/{id}/restore [post]
+func (employeeController *EmployeeController) RestoreEmployee(context *gin.Context) {
+	id, err := employeeID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// trigger employee restoration, the single tag form of If-Match is enough for a deleted employee
+	var version uint
+	if versions, wildcard := entityTags(context.GetHeader("If-Match"), false); len(versions) > 0 && !wildcard {
+		version = versions[0]
+	}
+	employee, err := employeeController.employeeService.WithAudit(auditContext(context)).RestoreEmployee(id, version)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.Header("ETag", employeeETag(context, employee))
+	context.JSON(http.StatusOK, redactEmployee(context, employee))
+}
+
+// PurgeEmployee permanently removes a single employee for the employee service
+// @Summary Purges a single employee
+// @Description Removes a single employee for good, whether it was soft deleted or not. Admin only, the admin key is needed on top of the bearer token.
+// @Tags admin
+// @Accept json
+// @Produce json,application/problem+json
+// @Security BearerAuth || AdminKey
+// @Param id path int true "id"
+// @Success 204 {object} interface{}
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /admin/employees/{id} [delete]
+func (employeeController *EmployeeController) PurgeEmployee(context *gin.Context) {
+	id, err := employeeID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// trigger employee purge
+	if err := employeeController.employeeService.WithAudit(auditContext(context)).PurgeEmployee(id); err != nil {
+		abortWithError(context, err)
 		return
 	}
 
@@ -209,221 +509,231 @@ func (employeeController *EmployeeController) DeleteEmployee(context *gin.Contex
 // @Description Pushes multiple random employees
 // @Tags employees
 // @Accept json
-// @Produce json
+// @Produce json,application/problem+json
 // @Param employee body map[string]interface{} true "Push employee"
 // @Success 204 {object} interface{}
-// @Failure 422 {object} ErrorResponse
-// @Failure 500 {object} ErrorResponse
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
 // @Router /employees/random [post]
 func (employeeController *EmployeeController) PushEmployee(context *gin.Context) {
 	employees := []*models.Employee{
 		{
 			Name:     "Rahul Gupta",
 			Position: "Software Developer",
-			Salary:   76000.25,
+			Salary:   models.RequireAmount("76000.25"),
 		},
 		{
 			Name:     "Deepika Patel",
 			Position: "Marketing Specialist",
-			Salary:   59000.50,
+			Salary:   models.RequireAmount("59000.50"),
 		},
 		{
 			Name:     "Amit Kumar",
 			Position: "Accountant",
-			Salary:   53000.00,
+			Salary:   models.RequireAmount("53000.00"),
 		},
 		{
 			Name:     "Ananya Singh",
 			Position: "Customer Service Representative",
-			Salary:   40500.75,
+			Salary:   models.RequireAmount("40500.75"),
 		},
 		{
 			Name:     "Prakash Sharma",
 			Position: "Human Resources Manager",
-			Salary:   82000.00,
+			Salary:   models.RequireAmount("82000.00"),
 		},
 		{
 			Name:     "Neha Reddy",
 			Position: "Software Developer",
-			Salary:   74000.25,
+			Salary:   models.RequireAmount("74000.25"),
 		},
 		{
 			Name:     "Sanjay Mishra",
 			Position: "Marketing Specialist",
-			Salary:   60000.50,
+			Salary:   models.RequireAmount("60000.50"),
 		},
 		{
 			Name:     "Pooja Shah",
 			Position: "Accountant",
-			Salary:   56000.00,
+			Salary:   models.RequireAmount("56000.00"),
 		},
 		{
 			Name:     "Rajesh Patel",
 			Position: "Customer Service Representative",
-			Salary:   43000.75,
+			Salary:   models.RequireAmount("43000.75"),
 		},
 		{
 			Name:     "Nisha Desai",
 			Position: "Human Resources Manager",
-			Salary:   83000.00,
+			Salary:   models.RequireAmount("83000.00"),
 		},
 		{
 			Name:     "Ajay Verma",
 			Position: "Software Developer",
-			Salary:   77000.25,
+			Salary:   models.RequireAmount("77000.25"),
 		},
 		{
 			Name:     "Shreya Singh",
 			Position: "Marketing Specialist",
-			Salary:   61000.50,
+			Salary:   models.RequireAmount("61000.50"),
 		},
 		{
 			Name:     "Manoj Kumar",
 			Position: "Accountant",
-			Salary:   54000.00,
+			Salary:   models.RequireAmount("54000.00"),
 		},
 		{
 			Name:     "Sneha Sharma",
 			Position: "Customer Service Representative",
-			Salary:   41000.75,
+			Salary:   models.RequireAmount("41000.75"),
 		},
 		{
 			Name:     "Vivek Joshi",
 			Position: "Human Resources Manager",
-			Salary:   84000.00,
+			Salary:   models.RequireAmount("84000.00"),
 		},
 		{
 			Name:     "Meera Patel",
 			Position: "Software Developer",
-			Salary:   78000.25,
+			Salary:   models.RequireAmount("78000.25"),
 		},
 		{
 			Name:     "Raj Gupta",
 			Position: "Marketing Specialist",
-			Salary:   62000.50,
+			Salary:   models.RequireAmount("62000.50"),
 		},
 		{
 			Name:     "Aarti Sharma",
 			Position: "Accountant",
-			Salary:   55000.00,
+			Salary:   models.RequireAmount("55000.00"),
 		},
 		{
 			Name:     "Anand Singh",
 			Position: "Customer Service Representative",
-			Salary:   42000.75,
+			Salary:   models.RequireAmount("42000.75"),
 		},
 		{
 			Name:     "Divya Desai",
 			Position: "Human Resources Manager",
-			Salary:   85000.00,
+			Salary:   models.RequireAmount("85000.00"),
 		},
 		{
 			Name:     "Vikas Reddy",
 			Position: "Software Developer",
-			Salary:   79000.25,
+			Salary:   models.RequireAmount("79000.25"),
 		},
 		{
 			Name:     "Kavita Mishra",
 			Position: "Marketing Specialist",
-			Salary:   63000.50,
+			Salary:   models.RequireAmount("63000.50"),
 		},
 		{
 			Name:     "Amita Shah",
 			Position: "Accountant",
-			Salary:   56000.00,
+			Salary:   models.RequireAmount("56000.00"),
 		},
 		{
 			Name:     "Vinod Patel",
 			Position: "Customer Service Representative",
-			Salary:   43000.75,
+			Salary:   models.RequireAmount("43000.75"),
 		},
 		{
 			Name:     "Neeraj Desai",
 			Position: "Human Resources Manager",
-			Salary:   86000.00,
+			Salary:   models.RequireAmount("86000.00"),
 		},
 		{
 			Name:     "Priya Gupta",
 			Position: "Software Developer",
-			Salary:   80000.25,
+			Salary:   models.RequireAmount("80000.25"),
 		},
 		{
 			Name:     "Rahul Singh",
 			Position: "Marketing Specialist",
-			Salary:   64000.50,
+			Salary:   models.RequireAmount("64000.50"),
 		},
 		{
 			Name:     "Anjali Kumar",
 			Position: "Accountant",
-			Salary:   57000.00,
+			Salary:   models.RequireAmount("57000.00"),
 		},
 		{
 			Name:     "Arjun Sharma",
 			Position: "Customer Service Representative",
-			Salary:   44000.75,
+			Salary:   models.RequireAmount("44000.75"),
 		},
 		{
 			Name:     "Kiran Desai",
 			Position: "Human Resources Manager",
-			Salary:   87000.00,
+			Salary:   models.RequireAmount("87000.00"),
 		},
 		{
 			Name:     "Sarita Patel",
 			Position: "Software Developer",
-			Salary:   81000.25,
+			Salary:   models.RequireAmount("81000.25"),
 		},
 		{
 			Name:     "Pradeep Mishra",
 			Position: "Marketing Specialist",
-			Salary:   65000.50,
+			Salary:   models.RequireAmount("65000.50"),
 		},
 		{
 			Name:     "Nandini Shah",
 			Position: "Accountant",
-			Salary:   58000.00,
+			Salary:   models.RequireAmount("58000.00"),
 		},
 		{
 			Name:     "Rajeev Singh",
 			Position: "Customer Service Representative",
-			Salary:   45000.75,
+			Salary:   models.RequireAmount("45000.75"),
 		},
 		{
 			Name:     "Gaurav Desai",
 			Position: "Human Resources Manager",
-			Salary:   88000.00,
+			Salary:   models.RequireAmount("88000.00"),
 		},
 		{
 			Name:     "Sunita Gupta",
 			Position: "Software Developer",
-			Salary:   82000.25,
+			Salary:   models.RequireAmount("82000.25"),
 		},
 		{
 			Name:     "Pawan Singh",
 			Position: "Marketing Specialist",
-			Salary:   66000.50,
+			Salary:   models.RequireAmount("66000.50"),
 		},
 		{
 			Name:     "Anita Kumar",
 			Position: "Accountant",
-			Salary:   59000.00,
+			Salary:   models.RequireAmount("59000.00"),
 		},
 		{
 			Name:     "Suresh Sharma",
 			Position: "Customer Service Representative",
-			Salary:   46000.75,
+			Salary:   models.RequireAmount("46000.75"),
 		},
 		{
 			Name:     "Deepak Desai",
 			Position: "Human Resources Manager",
-			Salary:   89000.00,
+			Salary:   models.RequireAmount("89000.00"),
 		},
 	}
 
-	err := employeeController.employeeService.CreateEmployees(employees)
+	err := employeeController.employeeService.WithAudit(auditContext(context)).CreateEmployees(employees)
 	if err != nil {
-		log.Error(err)
-		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		abortWithError(context, err)
 		return
 	}
 	context.JSON(http.StatusCreated, gin.H{"message": "Random employees created"})
 }
+
+// employeeID reads the id path parameter
+func employeeID(context *gin.Context) (int64, error) {
+	id, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid employee id %q, expected a positive integer", context.Param("id"))
+	}
+	return id, nil
+}
@@ -0,0 +1,288 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportFlushRows is how many rows are buffered before they are pushed to the client
+const exportFlushRows = 100
+
+// exportColumns are the columns of CSV and XLSX exports, the header row names them like the JSON fields
+var exportColumns = []string{"id", "name", "position", "salary", "currency", "department_id", "manager_id", "version", "created_at", "updated_at"}
+
+// escapeFormula prefixes text a spreadsheet would evaluate with a quote, so that exported names
+// and positions stay text; the import strips the quote again
+func escapeFormula(text string) string {
+	if len(text) > 0 && strings.ContainsRune(models.FormulaPrefixes, rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// exportID formats an optional reference, empty when unset
+func exportID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
+
+// employeeExporter encodes employees one by one into a file format
+type employeeExporter interface {
+	write(employee *models.Employee) error
+	// flush hands the buffered rows on to the response
+	flush() error
+	// close writes whatever the format still needs after the last employee
+	close() error
+}
+
+// newEmployeeExporter returns the exporter of format and the content type of its output
+func newEmployeeExporter(format string, w io.Writer) (employeeExporter, string, error) {
+	switch format {
+	case "csv":
+		return newCSVExporter(w), "text/csv; charset=utf-8", nil
+	case "ndjson":
+		return &ndjsonExporter{encoder: json.NewEncoder(w)}, "application/x-ndjson", nil
+	case "xlsx":
+		exporter, err := newXLSXExporter(w)
+		return exporter, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", err
+	}
+	return nil, "", fmt.Errorf("invalid format %q, expected csv, ndjson or xlsx", format)
+}
+
+// exportRow returns the cells of employee in the order of exportColumns, masked fields left empty
+func exportRow(employee *models.Employee) []string {
+	salary := employee.Salary.String()
+	if employee.Salary.Masked() {
+		salary = ""
+	}
+	return []string{
+		strconv.FormatUint(uint64(employee.ID), 10),
+		escapeFormula(employee.Name),
+		escapeFormula(employee.Position),
+		salary,
+		escapeFormula(employee.Currency),
+		exportID(employee.DepartmentID),
+		exportID(employee.ManagerID),
+		strconv.FormatUint(uint64(employee.Version), 10),
+		employee.CreatedAt.UTC().Format(time.RFC3339Nano),
+		employee.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+type csvExporter struct {
+	writer *csv.Writer
+	header bool
+}
+
+func newCSVExporter(w io.Writer) *csvExporter {
+	return &csvExporter{writer: csv.NewWriter(w)}
+}
+
+func (exporter *csvExporter) write(employee *models.Employee) error {
+	if !exporter.header {
+		exporter.header = true
+		if err := exporter.writer.Write(exportColumns); err != nil {
+			return err
+		}
+	}
+	return exporter.writer.Write(exportRow(employee))
+}
+
+func (exporter *csvExporter) flush() error {
+	exporter.writer.Flush()
+	return exporter.writer.Error()
+}
+
+func (exporter *csvExporter) close() error {
+	if !exporter.header {
+		// no employees, still a valid file with a header row
+		exporter.header = true
+		if err := exporter.writer.Write(exportColumns); err != nil {
+			return err
+		}
+	}
+	return exporter.flush()
+}
+
+type ndjsonExporter struct {
+	encoder *json.Encoder
+}
+
+func (exporter *ndjsonExporter) write(employee *models.Employee) error {
+	return exporter.encoder.Encode(employee)
+}
+
+func (exporter *ndjsonExporter) flush() error {
+	return nil
+}
+
+func (exporter *ndjsonExporter) close() error {
+	return nil
+}
+
+// xlsxExporter keeps memory flat by letting excelize spill the rows to a temporary file.
+// A workbook is a zip archive that is only complete at the end, so it reaches the client on close.
+type xlsxExporter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXExporter(w io.Writer) (*xlsxExporter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	header := make([]interface{}, 0, len(exportColumns))
+	for _, column := range exportColumns {
+		header = append(header, column)
+	}
+	if err := stream.SetRow("A1", header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &xlsxExporter{w: w, file: file, stream: stream, row: 1}, nil
+}
+
+func (exporter *xlsxExporter) write(employee *models.Employee) error {
+	exporter.row++
+	cell, err := excelize.CoordinatesToCellName(1, exporter.row)
+	if err != nil {
+		return err
+	}
+	// a spreadsheet number, exact as long as it has fewer than 16 digits, and an empty cell when masked
+	var salary interface{} = employee.Salary.InexactFloat64()
+	if employee.Salary.Masked() {
+		salary = nil
+	}
+	var departmentID, managerID interface{}
+	if employee.DepartmentID != nil {
+		departmentID = *employee.DepartmentID
+	}
+	if employee.ManagerID != nil {
+		managerID = *employee.ManagerID
+	}
+	return exporter.stream.SetRow(cell, []interface{}{
+		employee.ID,
+		escapeFormula(employee.Name),
+		escapeFormula(employee.Position),
+		salary,
+		escapeFormula(employee.Currency),
+		departmentID,
+		managerID,
+		employee.Version,
+		employee.CreatedAt.UTC().Format(time.RFC3339Nano),
+		employee.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	})
+}
+
+func (exporter *xlsxExporter) flush() error {
+	return nil
+}
+
+func (exporter *xlsxExporter) close() error {
+	defer exporter.file.Close()
+	if err := exporter.stream.Flush(); err != nil {
+		return err
+	}
+	return exporter.file.Write(exporter.w)
+}
+
+// ExportEmployees streams employees as a file for the employee service
+// @Summary Exports employees
+// @Description Streams every employee matching the filters as a CSV, newline-delimited JSON or XLSX download, without paging. CSV and XLSX files start with a header row, text starting with =, +, -, @, a tab or a carriage return is prefixed with a quote so spreadsheets do not evaluate it.
+// @Tags employees
+// @Produce text/csv,application/x-ndjson,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/problem+json
+// @Param format query string false "csv (default), ndjson or xlsx"
+// @Param name_prefix query string false "name starts with, case-insensitive"
+// @Param name_contains query string false "name contains, case-insensitive"
+// @Param position query []string false "exact position, repeat for any of several" collectionFormat(multi)
+// @Param department_id query []int false "department id, repeat for any of several" collectionFormat(multi)
+// @Param manager_id query []int false "id of the manager, repeat for the reports of any of several" collectionFormat(multi)
+// @Param salary_min query number false "minimum salary, inclusive, with at most 4 decimal places, needs salaries:read"
+// @Param salary_max query number false "maximum salary, inclusive, with at most 4 decimal places, needs salaries:read"
+// @Param currency query []string false "ISO 4217 code of the salary currency, repeat for any of several" collectionFormat(multi)
+// @Param created_from query string false "created on or after, date or RFC 3339 timestamp"
+// @Param created_to query string false "created on or before, date or RFC 3339 timestamp"
+// @Param updated_from query string false "updated on or after, date or RFC 3339 timestamp"
+// @Param updated_to query string false "updated on or before, date or RFC 3339 timestamp"
+// @Param sort query string false "comma separated fields out of id, name, position, salary, created_at, updated_at, prefixed with - for descending order; salary needs salaries:read"
+// @Success 200 {file} file
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /employees:export [get]
+func (employeeController *EmployeeController) ExportEmployees(context *gin.Context) {
+	// validate input
+	query := context.Request.URL.Query()
+	filter, err := parseEmployeeFilter(query)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+	sort, err := parseEmployeeSort(query.Get("sort"))
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+	if !checkSalaryQuery(context, filter, sort) {
+		return
+	}
+	format := context.DefaultQuery("format", "csv")
+	exporter, contentType, err := newEmployeeExporter(format, context.Writer)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// trigger employee export, the headers go out with the first flushed rows
+	context.Header("Content-Type", contentType)
+	context.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="employees-%s.%s"`, time.Now().UTC().Format("20060102"), format))
+	context.Header("X-Content-Type-Options", "nosniff")
+	rows := 0
+	err = employeeController.employeeService.ExportEmployees(filter, sort, func(employee *models.Employee) error {
+		if err := exporter.write(redactEmployee(context, employee)); err != nil {
+			return err
+		}
+		if rows++; rows%exportFlushRows == 0 {
+			if err := exporter.flush(); err != nil {
+				return err
+			}
+			context.Writer.Flush()
+		}
+		// stop reading once the client went away
+		return context.Request.Context().Err()
+	})
+	if err == nil {
+		err = exporter.close()
+	}
+	if err != nil {
+		if !context.Writer.Written() {
+			context.Header("Content-Disposition", "")
+			abortWithError(context, err)
+			return
+		}
+		log.WithField("request_id", requestID(context)).Error(err)
+		// too late for an error response, drop the connection so the client sees a broken download instead of a short file
+		if conn, _, err := context.Writer.Hijack(); err == nil {
+			conn.Close()
+		}
+		return
+	}
+	context.Writer.Flush()
+}
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// EmployeeHierarchy lists employees along a reporting line, in the order of the hierarchy
+type EmployeeHierarchy struct {
+	Data []*models.Employee `json:"data"`
+}
+
+// OrgChart holds the trees of an org chart, one per employee at the top
+type OrgChart struct {
+	Data []*models.OrgChartNode `json:"data"`
+}
+
+// dotEscaper makes text safe inside a quoted Graphviz string
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`)
+
+// FetchDirectReports fetches the direct reports of a single employee for the employee service
+// @Summary Fetches the direct reports of a single employee
+// @Description Fetches the employees whose manager is the given employee, paged, filtered and sorted like all employees
+// @Tags employees
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "id"
+// @Param page query int false "page, ignored when cursor is given"
+// @Param page_size query int false "page_size, at most 100"
+// @Param cursor query string false "opaque keyset cursor from page.next_cursor or page.prev_cursor, pass it empty to start keyset pagination"
+// @Param sort query string false "comma separated fields out of id, name, position, salary, created_at, updated_at, prefixed with - for descending order"
+// @Success 200 {object} EmployeeList
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /employees/{id}/reports [get]
+func (employeeController *EmployeeController) FetchDirectReports(context *gin.Context) {
+	id, err := employeeID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// validate input
+	query, err := parseEmployeeQuery(context.Request.URL.Query())
+	if err != nil {
+		abortWithQueryError(context, err)
+		return
+	}
+	query.Filter.ManagerIDs = []uint{uint(id)}
+	if !checkSalaryQuery(context, &query.Filter, query.Sort) {
+		return
+	}
+
+	// trigger employee fetching, an unknown manager is a 404 rather than an empty list
+	if _, err := employeeController.employeeService.GetEmployee(id); err != nil {
+		abortWithError(context, err)
+		return
+	}
+	page, err := employeeController.employeeService.GetEmployees(query)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, newEmployeeList(context, query, page))
+}
+
+// FetchManagementChain fetches the managers of a single employee for the employee service
+// @Summary Fetches the management chain of a single employee
+// @Description Fetches the manager of the employee, their manager and so on up to the top of the organization, nearest first. A deleted manager ends the chain.
+// @Tags employees
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "id"
+// @Success 200 {object} EmployeeHierarchy
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /employees/{id}/chain [get]
+func (employeeController *EmployeeController) FetchManagementChain(context *gin.Context) {
+	id, err := employeeID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// trigger management chain fetching
+	chain, err := employeeController.employeeService.GetManagementChain(id)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, newEmployeeHierarchy(redactEmployees(context, chain)))
+}
+
+// FetchReportingSubtree fetches everyone reporting to a single employee for the employee service
+// @Summary Fetches the reporting subtree of a single employee
+// @Description Fetches everyone reporting to the employee directly or indirectly, level by level and by id within a level. The reports of deleted employees are left out.
+// @Tags employees
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "id"
+// @Success 200 {object} EmployeeHierarchy
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /employees/{id}/subtree [get]
+func (employeeController *EmployeeController) FetchReportingSubtree(context *gin.Context) {
+	id, err := employeeID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// trigger reporting subtree fetching
+	subtree, err := employeeController.employeeService.GetReportingSubtree(id)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, newEmployeeHierarchy(redactEmployees(context, subtree)))
+}
+
+// ExportOrgChart renders the org chart for the employee service
+// @Summary Exports the org chart
+// @Description Renders who reports to whom as a JSON tree or as a Graphviz DOT digraph, for the whole organization or for the employees under root. Employees without a manager, or whose manager was deleted, are at the top.
+// @Tags employees
+// @Produce json,text/vnd.graphviz,application/problem+json
+// @Param format query string false "json (default) or dot"
+// @Param root query int false "id of the employee at the top of the chart"
+// @Success 200 {object} OrgChart
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /employees:orgchart [get]
+func (employeeController *EmployeeController) ExportOrgChart(context *gin.Context) {
+	// validate input
+	format := context.DefaultQuery("format", "json")
+	if format != "json" && format != "dot" {
+		abortWithProblem(context, problemBadRequest, fmt.Errorf("invalid format %q, expected json or dot", format))
+		return
+	}
+	var root int64
+	if value, ok := context.GetQuery("root"); ok {
+		var err error
+		if root, err = strconv.ParseInt(value, 10, 64); err != nil || root <= 0 {
+			abortWithProblem(context, problemBadRequest, fmt.Errorf("invalid root %q, expected a positive integer", value))
+			return
+		}
+	}
+
+	// trigger org chart rendering
+	roots, err := employeeController.employeeService.GetOrgChart(root)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	if format == "dot" {
+		context.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", orgChartDOT(roots))
+		return
+	}
+	orgChart := OrgChart{Data: roots}
+	if orgChart.Data == nil {
+		orgChart.Data = []*models.OrgChartNode{}
+	}
+	context.JSON(http.StatusOK, orgChart)
+}
+
+func newEmployeeHierarchy(employees []*models.Employee) EmployeeHierarchy {
+	if employees == nil {
+		employees = []*models.Employee{}
+	}
+	return EmployeeHierarchy{Data: employees}
+}
+
+// orgChartDOT renders roots as a digraph with an edge from every manager to each of their reports
+func orgChartDOT(roots []*models.OrgChartNode) []byte {
+	var dot bytes.Buffer
+	dot.WriteString("digraph orgchart {\n\tnode [shape=box];\n")
+	var edges []string
+	var visit func(node *models.OrgChartNode)
+	visit = func(node *models.OrgChartNode) {
+		label := node.Name
+		if len(node.Position) > 0 {
+			label += "\n" + node.Position
+		}
+		fmt.Fprintf(&dot, "\t%d [label=\"%s\"];\n", node.ID, dotEscaper.Replace(label))
+		for _, report := range node.Reports {
+			edges = append(edges, fmt.Sprintf("\t%d -> %d;\n", node.ID, report.ID))
+			visit(report)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+	for _, edge := range edges {
+		dot.WriteString(edge)
+	}
+	dot.WriteString("}\n")
+	return dot.Bytes()
+}
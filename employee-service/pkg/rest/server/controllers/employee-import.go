@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	importModeDryRun = "dry_run"
+	importModeCommit = "commit"
+)
+
+// ImportEmployees imports employees from a spreadsheet for the employee service
+// @Summary Imports employees from a CSV or XLSX file
+// @Description Reads one employee per row below a header row. A dry run only validates the rows, checking that their departments and managers exist, a commit stores all of them in one transaction, or none when a row is invalid. Salaries and currencies need the salaries:write permission.
+// @Tags employees
+// @Accept mpfd
+// @Produce json,application/problem+json
+// @Param file formData file true "CSV or XLSX file of at most 10 MB"
+// @Param format formData string false "csv or xlsx, taken from the file name when missing"
+// @Param sheet formData string false "XLSX sheet to read, the first one when missing"
+// @Param mapping formData string false "JSON object mapping header names to name, position, salary, currency, department_id or manager_id, e.g. {\"Full Name\": \"name\"}. Headers named like the fields are used when missing."
+// @Param mode formData string false "dry_run (default) or commit"
+// @Success 200 {object} models.EmployeeImportResult "dry run"
+// @Success 201 {object} models.EmployeeImportResult "committed"
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 413 {object} Problem
+// @Failure 422 {object} models.EmployeeImportResult "commit refused because of invalid rows"
+// @Failure 500 {object} Problem
+// @Router /employees:import [post]
+func (employeeController *EmployeeController) ImportEmployees(context *gin.Context) {
+	// validate input
+	context.Request.Body = http.MaxBytesReader(context.Writer, context.Request.Body, models.MaxEmployeeImportSize+1<<20)
+	fileHeader, err := context.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			abortWithError(context, err)
+			return
+		}
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+	if fileHeader.Size > models.MaxEmployeeImportSize {
+		abortWithProblem(context, problemTooLarge, fmt.Errorf("file is larger than %d bytes", models.MaxEmployeeImportSize))
+		return
+	}
+
+	request := &models.EmployeeImportRequest{
+		Format: models.EmployeeImportFormat(strings.ToLower(context.PostForm("format"))),
+		Sheet:  context.PostForm("sheet"),
+	}
+	if len(request.Format) == 0 {
+		request.Format = models.EmployeeImportFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), "."))
+	}
+	if mapping := context.PostForm("mapping"); len(mapping) > 0 {
+		if err := json.Unmarshal([]byte(mapping), &request.Mapping); err != nil {
+			abortWithProblem(context, problemBadRequest, fmt.Errorf("invalid mapping: %w", err))
+			return
+		}
+	}
+	switch mode := context.DefaultPostForm("mode", importModeDryRun); mode {
+	case importModeDryRun:
+	case importModeCommit:
+		request.Commit = true
+	default:
+		abortWithProblem(context, problemBadRequest, fmt.Errorf("invalid mode %q, expected %s or %s", mode, importModeDryRun, importModeCommit))
+		return
+	}
+	if !granted(context, PermissionWriteSalaries) {
+		request.ReadOnlyFields = models.SalaryFields
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+	defer file.Close()
+	request.File = file
+
+	// trigger employee import
+	result, err := employeeController.employeeService.WithAudit(auditContext(context)).ImportEmployees(request)
+	if errors.Is(err, models.ErrReadOnlyImportField) {
+		abortWithMissingPermission(context, PermissionWriteSalaries)
+		return
+	}
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	switch {
+	case result.Committed:
+		context.JSON(http.StatusCreated, result)
+	case request.Commit && result.Invalid > 0:
+		context.JSON(http.StatusUnprocessableEntity, result)
+	default:
+		context.JSON(http.StatusOK, result)
+	}
+}
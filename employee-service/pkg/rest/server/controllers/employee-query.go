@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/gin-gonic/gin"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseEmployeeQuery reads paging, filtering and sorting parameters of an employee listing.
+// A cursor parameter, even empty, switches to keyset pagination.
+func parseEmployeeQuery(query url.Values) (*models.EmployeeQuery, error) {
+	filter, err := parseEmployeeFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	sort, err := parseEmployeeSort(query.Get("sort"))
+	if err != nil {
+		return nil, err
+	}
+	employeeQuery := &models.EmployeeQuery{
+		Filter: *filter,
+		Sort:   sort,
+		Page:   parsePage(query),
+		Limit:  parsePageSize(query),
+	}
+
+	if query.Has("cursor") {
+		employeeQuery.Page = 0
+		if token := query.Get("cursor"); len(token) > 0 {
+			if employeeQuery.Cursor, err = models.DecodeEmployeeCursor(token); err != nil {
+				return nil, err
+			}
+			if employeeQuery.Cursor.Sort != models.EmployeeSortString(sort) {
+				return nil, fmt.Errorf("%w: it was created for sort %q", models.ErrInvalidCursor, employeeQuery.Cursor.Sort)
+			}
+		} else {
+			employeeQuery.Cursor = models.NewEmployeeStartCursor(sort)
+		}
+	}
+	return employeeQuery, nil
+}
+
+// abortWithQueryError answers 400 for the errors of parseEmployeeQuery
+func abortWithQueryError(context *gin.Context, err error) {
+	if errors.Is(err, models.ErrInvalidCursor) {
+		abortWithError(context, err)
+		return
+	}
+	abortWithProblem(context, problemBadRequest, err)
+}
+
+// parseEmployeeFilter reads the filter parameters shared by every endpoint listing employees
+func parseEmployeeFilter(query url.Values) (*models.EmployeeFilter, error) {
+	filter := &models.EmployeeFilter{
+		NamePrefix:   query.Get("name_prefix"),
+		NameContains: query.Get("name_contains"),
+	}
+	for _, position := range query["position"] {
+		if len(position) > 0 {
+			filter.Positions = append(filter.Positions, position)
+		}
+	}
+	for _, currency := range query["currency"] {
+		if len(currency) > 0 {
+			filter.Currencies = append(filter.Currencies, currency)
+		}
+	}
+
+	var err error
+	if filter.DepartmentIDs, err = parseIDsParam(query, "department_id"); err != nil {
+		return nil, err
+	}
+	if filter.ManagerIDs, err = parseIDsParam(query, "manager_id"); err != nil {
+		return nil, err
+	}
+	if filter.SalaryMin, err = parseAmountParam(query, "salary_min"); err != nil {
+		return nil, err
+	}
+	if filter.SalaryMax, err = parseAmountParam(query, "salary_max"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedFrom, err = parseTimeParam(query, "created_from", false); err != nil {
+		return nil, err
+	}
+	if filter.CreatedTo, err = parseTimeParam(query, "created_to", true); err != nil {
+		return nil, err
+	}
+	if filter.UpdatedFrom, err = parseTimeParam(query, "updated_from", false); err != nil {
+		return nil, err
+	}
+	if filter.UpdatedTo, err = parseTimeParam(query, "updated_to", true); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// parseEmployeeSort reads a comma separated list of fields, each optionally prefixed with - for descending order
+func parseEmployeeSort(value string) ([]models.EmployeeSort, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+	var sort []models.EmployeeSort
+	seen := map[string]bool{}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(strings.TrimPrefix(field, "-"), "+")
+		if _, ok := models.EmployeeSortColumns[field]; !ok {
+			return nil, fmt.Errorf("invalid sort field %q", field)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("duplicate sort field %q", field)
+		}
+		seen[field] = true
+		sort = append(sort, models.EmployeeSort{Field: field, Desc: desc})
+	}
+	return sort, nil
+}
+
+// parseIDsParam reads a repeatable parameter of row ids
+func parseIDsParam(query url.Values, key string) ([]uint, error) {
+	var ids []uint
+	for _, value := range query[key] {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("invalid %s %q, expected a positive integer", key, value)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
+
+// parseAmountParam reads an exact decimal amount of money
+func parseAmountParam(query url.Values, key string) (*models.Amount, error) {
+	value := query.Get(key)
+	if len(value) == 0 {
+		return nil, nil
+	}
+	parsed, err := models.NewAmount(value)
+	if err != nil || !parsed.Valid() {
+		return nil, fmt.Errorf("invalid %s %q, expected a decimal number with at most %d decimal places", key, value, models.AmountScale)
+	}
+	return &parsed, nil
+}
+
+// parseTimeParam accepts RFC 3339 timestamps or plain dates, a plain date used as an
+// upper bound includes the whole day
+func parseTimeParam(query url.Values, key string, upperBound bool) (*time.Time, error) {
+	value := query.Get(key)
+	if len(value) == 0 {
+		return nil, nil
+	}
+	if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		parsed = parsed.UTC()
+		return &parsed, nil
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q, expected a date (2006-01-02) or an RFC 3339 timestamp", key, value)
+	}
+	if upperBound {
+		parsed = parsed.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SalaryHistory lists the salary changes of an employee by effective date, changes effective the same day in the
+// order they were made
+type SalaryHistory struct {
+	Data []*models.SalaryChange `json:"data"`
+}
+
+// SalaryReport holds the salary statistics of every group of employees, see models.SalaryStats
+type SalaryReport struct {
+	Data []*models.SalaryStats `json:"data"`
+}
+
+// FetchSalaryChanges fetches the salary history of a single employee for the employee service
+// @Summary Fetches the salary history of a single employee
+// @Description Fetches every salary change of the employee by effective date, the scheduled ones included and marked. The salary of the employee is the one of the latest change effective today.
+// @Tags employees
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "id"
+// @Success 200 {object} SalaryHistory
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /employees/{id}/salary-changes [get]
+func (employeeController *EmployeeController) FetchSalaryChanges(context *gin.Context) {
+	id, err := employeeID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// trigger salary history fetching
+	changes, err := employeeController.employeeService.GetSalaryChanges(id)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+	if changes == nil {
+		changes = []*models.SalaryChange{}
+	}
+
+	context.JSON(http.StatusOK, SalaryHistory{Data: changes})
+}
+
+// CreateSalaryChange changes the salary of a single employee for the employee service
+// @Summary Changes the salary of a single employee
+// @Description Records a salary change effective on effective_date, today in UTC when it is left out. A change effective after today is scheduled and takes effect on its day, one effective by today changes the salary right away unless a later change is effective already. The author of the change is the caller, the way audit entries name their actor.
+// @Tags employees
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "id"
+// @Param change body models.SalaryChange true "salary, currency, effective_date and reason of the change"
+// @Success 201 {object} models.SalaryChange
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 422 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /employees/{id}/salary-changes [post]
+func (employeeController *EmployeeController) CreateSalaryChange(context *gin.Context) {
+	id, err := employeeID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// validate input
+	var input models.SalaryChange
+	if err := context.ShouldBindJSON(&input); err != nil {
+		abortWithBindingError(context, err)
+		return
+	}
+	// the server records who made the change and when, whatever the body claims
+	input.ID, input.EmployeeID, input.CreatedAt = 0, uint(id), time.Time{}
+	audit := auditContext(context)
+	input.Author = audit.Actor
+
+	// trigger salary change creation
+	change, err := employeeController.employeeService.WithAudit(audit).CreateSalaryChange(&input)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusCreated, change)
+}
+
+// DeleteSalaryChange cancels a scheduled salary change of a single employee for the employee service
+// @Summary Cancels a scheduled salary change
+// @Description Deletes a salary change that has not taken effect yet, the changes effective by today are history and stay.
+// @Tags employees
+// @Accept json
+// @Produce json,application/problem+json
+// @Param id path int true "id"
+// @Param change_id path int true "id of the salary change"
+// @Success 204 {object} interface{}
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 404 {object} Problem
+// @Failure 409 {object} Problem "the change is effective already"
+// @Failure 500 {object} Problem
+// @Router /employees/{id}/salary-changes/{change_id} [delete]
+func (employeeController *EmployeeController) DeleteSalaryChange(context *gin.Context) {
+	id, err := employeeID(context)
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+	changeID, err := strconv.ParseInt(context.Param("change_id"), 10, 64)
+	if err != nil || changeID <= 0 {
+		abortWithProblem(context, problemBadRequest, fmt.Errorf("invalid salary change id %q, expected a positive integer", context.Param("change_id")))
+		return
+	}
+
+	// trigger salary change deletion
+	if err := employeeController.employeeService.DeleteSalaryChange(id, changeID); err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusNoContent, gin.H{})
+}
+
+// FetchSalaryReport computes salary statistics for the employee service
+// @Summary Reports salary statistics
+// @Description Computes headcount, total, average, median, minimum, maximum and the requested percentiles of the salaries of the employees matching the filters, for every group of employees. Salaries are grouped by currency in any case, percentiles interpolate between the closest salaries. band_width adds the number of employees in every salary band of that width with employees in it.
+// @Tags employees
+// @Produce json,application/problem+json
+// @Param group_by query string false "comma separated fields out of position, department and hire_year, the year the employee was created in"
+// @Param percentile query []number false "percentile between 0 and 100 to report along with the median, repeat for several" collectionFormat(multi)
+// @Param band_width query number false "width of the salary bands to count employees in, positive with at most 4 decimal places"
+// @Param name_prefix query string false "name starts with, case-insensitive"
+// @Param name_contains query string false "name contains, case-insensitive"
+// @Param position query []string false "exact position, repeat for any of several" collectionFormat(multi)
+// @Param department_id query []int false "department id, repeat for any of several" collectionFormat(multi)
+// @Param manager_id query []int false "id of the manager, repeat for the reports of any of several" collectionFormat(multi)
+// @Param salary_min query number false "minimum salary, inclusive, with at most 4 decimal places"
+// @Param salary_max query number false "maximum salary, inclusive, with at most 4 decimal places"
+// @Param currency query []string false "ISO 4217 code of the salary currency, repeat for any of several" collectionFormat(multi)
+// @Param created_from query string false "created on or after, date or RFC 3339 timestamp"
+// @Param created_to query string false "created on or before, date or RFC 3339 timestamp"
+// @Param updated_from query string false "updated on or after, date or RFC 3339 timestamp"
+// @Param updated_to query string false "updated on or before, date or RFC 3339 timestamp"
+// @Success 200 {object} SalaryReport
+// @Failure 400 {object} Problem
+// @Failure 401 {object} Problem
+// @Failure 403 {object} Problem
+// @Failure 500 {object} Problem
+// @Router /employees:salary-report [get]
+func (employeeController *EmployeeController) FetchSalaryReport(context *gin.Context) {
+	// validate input
+	query, err := parseSalaryReportQuery(context.Request.URL.Query())
+	if err != nil {
+		abortWithProblem(context, problemBadRequest, err)
+		return
+	}
+
+	// trigger salary report computation
+	stats, err := employeeController.employeeService.GetSalaryReport(query)
+	if err != nil {
+		abortWithError(context, err)
+		return
+	}
+
+	context.JSON(http.StatusOK, SalaryReport{Data: stats})
+}
+
+// parseSalaryReportQuery reads the grouping, percentiles and band width of a salary report along with the filter
+// parameters of employee listings
+func parseSalaryReportQuery(query url.Values) (*models.SalaryReportQuery, error) {
+	filter, err := parseEmployeeFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	reportQuery := &models.SalaryReportQuery{Filter: *filter}
+
+	if value := query.Get("group_by"); len(value) > 0 {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if _, ok := models.SalaryGroupFields[field]; !ok {
+				return nil, fmt.Errorf("invalid group_by field %q, expected position, department or hire_year", field)
+			}
+			if slices.Contains(reportQuery.GroupBy, field) {
+				return nil, fmt.Errorf("duplicate group_by field %q", field)
+			}
+			reportQuery.GroupBy = append(reportQuery.GroupBy, field)
+		}
+	}
+	for _, value := range query["percentile"] {
+		p, err := strconv.ParseFloat(value, 64)
+		if err != nil || p < 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile %q, expected a number between 0 and 100", value)
+		}
+		reportQuery.Percentiles = append(reportQuery.Percentiles, p)
+	}
+	if reportQuery.BandWidth, err = parseAmountParam(query, "band_width"); err != nil {
+		return nil, err
+	}
+	if reportQuery.BandWidth != nil && !reportQuery.BandWidth.IsPositive() {
+		return nil, fmt.Errorf("invalid band_width %q, expected a positive amount", query.Get("band_width"))
+	}
+	return reportQuery, nil
+}
@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/gin-gonic/gin"
+)
+
+// abortWithBindingError answers 422 for a body that does not decode, listing the field when a value has the wrong type
+func abortWithBindingError(context *gin.Context, err error) {
+	err = models.FieldTypeError(err)
+	if errors.Is(err, models.ErrInvalidEmployee) {
+		abortWithError(context, err)
+		return
+	}
+	abortWithProblem(context, problemUnprocessable, err)
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/gin-gonic/gin"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maskedETagSuffix sets the entity tags of employees served with masked salaries apart from the full ones
+const maskedETagSuffix = "-m"
+
+// employeeETag is the strong entity tag of employee as the caller gets it, it changes with every write
+// and tells a representation with masked salaries from the full one
+func employeeETag(context *gin.Context, employee *models.Employee) string {
+	tag := strconv.FormatUint(uint64(employee.Version), 10)
+	if !granted(context, PermissionReadSalaries) {
+		tag += maskedETagSuffix
+	}
+	return `"` + tag + `"`
+}
+
+// entityTags parses an If-Match or If-None-Match header into the employee versions it lists, wildcard is set for "*".
+// Weak tags only count when weak comparison is allowed, tags not issued by employeeETag never match.
+// Masked and full tags of a version list the same version, write preconditions are about the stored employee.
+func entityTags(header string, weak bool) (versions []uint, wildcard bool) {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			wildcard = true
+			continue
+		}
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, err := strconv.ParseUint(strings.TrimSuffix(tag[1:len(tag)-1], maskedETagSuffix), 10, 64)
+		if err != nil || version == 0 {
+			continue
+		}
+		versions = append(versions, uint(version))
+	}
+	return versions, wildcard
+}
+
+// matchesVersion reports whether header lists an entity tag of the version of employee, masked or not
+func matchesVersion(header string, employee *models.Employee) bool {
+	versions, wildcard := entityTags(header, false)
+	return wildcard || slices.Contains(versions, employee.Version)
+}
+
+// matchesETag reports whether header lists the entity tag of employee as the caller gets it, by weak comparison,
+// so that a cached representation with masked salaries never passes for the full one
+func matchesETag(context *gin.Context, header string, employee *models.Employee) bool {
+	etag := employeeETag(context, employee)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion returns the version the If-Match header allows a write on, 0 when any version will do.
+// It fails with sqls.ErrVersionMismatch when no version can match.
+func (employeeController *EmployeeController) ifMatchVersion(context *gin.Context, id int64) (uint, error) {
+	header := context.GetHeader("If-Match")
+	if len(header) == 0 {
+		return 0, nil
+	}
+	versions, wildcard := entityTags(header, false)
+	if wildcard {
+		return 0, nil
+	}
+	switch len(versions) {
+	case 0:
+		return 0, sqls.ErrVersionMismatch
+	case 1:
+		return versions[0], nil
+	}
+
+	// several tags, the write is conditional on the one the stored employee has
+	employee, err := employeeController.employeeService.GetEmployee(id)
+	if err != nil {
+		if errors.Is(err, sqls.ErrNotExists) {
+			return 0, sqls.ErrVersionMismatch
+		}
+		return 0, err
+	}
+	if !slices.Contains(versions, employee.Version) {
+		return 0, sqls.ErrVersionMismatch
+	}
+	return employee.Version, nil
+}
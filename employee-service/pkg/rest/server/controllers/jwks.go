@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxKeySetSize bounds the JWK sets read from a file or a URL
+const maxKeySetSize = 1 << 20
+
+// minRSAKeyBits refuses RSA keys too short to be trusted
+const minRSAKeyBits = 2048
+
+const (
+	// keySetMaxAge is how long fetched keys are used before they are fetched again
+	keySetMaxAge = 15 * time.Minute
+	// keySetMinRefresh is how long to wait before fetching the keys again, for a token signed by an unknown key or
+	// after a failed fetch
+	keySetMinRefresh = time.Minute
+)
+
+// keySource supplies the keys to check tokens signed by the key keyID with, all keys for an empty keyID
+type keySource interface {
+	keys(keyID string) ([]*verificationKey, error)
+}
+
+// staticKeySet is a JWK set read once
+type staticKeySet []*verificationKey
+
+func (keySet staticKeySet) keys(string) ([]*verificationKey, error) {
+	return keySet, nil
+}
+
+// remoteKeySet is a JWK set served at a URL, fetched again every keySetMaxAge and when a token names a key it
+// does not know, which is how issuers roll their keys over. A single fetch runs at a time, outside of the lock, and
+// fetches follow each other at least keySetMinRefresh apart, failed ones included, so that tokens naming unknown keys
+// do not turn into requests to the issuer.
+type remoteKeySet struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	cached    []*verificationKey
+	fetchedAt time.Time
+	// attemptedAt is when the last fetch started, whether it succeeded or not
+	attemptedAt time.Time
+	// fetching is closed once the fetch in flight is done, nil while there is none
+	fetching chan struct{}
+}
+
+func newRemoteKeySet(url string) *remoteKeySet {
+	return &remoteKeySet{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (keySet *remoteKeySet) keys(keyID string) ([]*verificationKey, error) {
+	keySet.mu.Lock()
+	defer keySet.mu.Unlock()
+
+	if keySet.stale(keyID) {
+		keySet.attemptedAt = time.Now()
+		keySet.fetching = make(chan struct{})
+		fetching := keySet.fetching
+		keySet.mu.Unlock()
+		keys, err := keySet.fetch()
+		keySet.mu.Lock()
+		if err != nil {
+			// keep checking tokens with the keys at hand while the issuer is unreachable
+			log.Errorf("failed to fetch the JWK set: %v", err)
+		} else {
+			keySet.cached, keySet.fetchedAt = keys, time.Now()
+		}
+		keySet.fetching = nil
+		close(fetching)
+	} else if fetching := keySet.fetching; fetching != nil && !(keySet.cached != nil && keySet.knows(keyID)) {
+		// the keys at hand cannot check the token, the fetch in flight may bring its key
+		keySet.mu.Unlock()
+		<-fetching
+		keySet.mu.Lock()
+	}
+	if keySet.cached == nil {
+		return nil, errors.New("no keys to check the signature with")
+	}
+	return keySet.cached, nil
+}
+
+// stale tells whether the keys are to be fetched for a token signed by the key keyID, the caller holds the lock
+func (keySet *remoteKeySet) stale(keyID string) bool {
+	if keySet.fetching != nil {
+		return false
+	}
+	if keySet.attemptedAt.IsZero() {
+		return true
+	}
+	if time.Since(keySet.attemptedAt) < keySetMinRefresh {
+		return false
+	}
+	return time.Since(keySet.fetchedAt) > keySetMaxAge || !keySet.knows(keyID)
+}
+
+// knows tells whether a cached key has the id keyID, tokens that name no key are checked with every key
+func (keySet *remoteKeySet) knows(keyID string) bool {
+	if len(keyID) == 0 {
+		return true
+	}
+	for _, key := range keySet.cached {
+		if key.id == keyID {
+			return true
+		}
+	}
+	return false
+}
+
+func (keySet *remoteKeySet) fetch() ([]*verificationKey, error) {
+	response, err := keySet.client.Get(keySet.url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %s", keySet.url, response.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxKeySetSize))
+	if err != nil {
+		return nil, err
+	}
+	return parseKeySet(data)
+}
+
+// readKeySetFile reads a JWK set from a file
+func readKeySetFile(name string) (staticKeySet, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxKeySetSize))
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return keys, nil
+}
+
+// jsonWebKey is a key of a JWK set as defined by RFC 7517, with the members of RSA and EC public keys
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+}
+
+// parseKeySet reads the RSA and P-256 signing keys of a JWK set, skipping keys of other kinds and for encryption
+func parseKeySet(data []byte) ([]*verificationKey, error) {
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &keySet); err != nil {
+		return nil, fmt.Errorf("invalid JWK set: %w", err)
+	}
+	var keys []*verificationKey
+	for i, jwk := range keySet.Keys {
+		if len(jwk.Use) > 0 && jwk.Use != "sig" {
+			continue
+		}
+		var publicKey interface{}
+		var err error
+		switch jwk.KeyType {
+		case "RSA":
+			publicKey, err = jwk.rsaPublicKey()
+		case "EC":
+			if jwk.Curve != "P-256" {
+				continue
+			}
+			publicKey, err = jwk.ecdsaPublicKey()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d of the JWK set: %w", i, err)
+		}
+		keys = append(keys, &verificationKey{id: jwk.KeyID, algorithm: jwk.Algorithm, key: publicKey})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("the JWK set has no RSA or P-256 signing keys")
+	}
+	return keys, nil
+}
+
+func (jwk *jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("n: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("e: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("e out of range")
+	}
+	publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+	if publicKey.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA keys need at least %d bits", minRSAKeyBits)
+	}
+	return publicKey, nil
+}
+
+func (jwk *jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("x: %w", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if err != nil {
+		return nil, fmt.Errorf("y: %w", err)
+	}
+	if len(x) != 32 || len(y) != 32 {
+		return nil, errors.New("P-256 coordinates need 32 bytes")
+	}
+	// the uncompressed point is only accepted on the curve
+	if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
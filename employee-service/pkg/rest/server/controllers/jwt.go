@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// tokenLeeway tolerates clocks of the token issuer and the service that are slightly apart
+const tokenLeeway = time.Minute
+
+// maxTokenLength bounds the tokens taken from clients
+const maxTokenLength = 8192
+
+// ErrInvalidToken is returned for bearer tokens that are malformed, not signed by a trusted key or not valid now
+var ErrInvalidToken = errors.New("invalid token")
+
+// AuthConfig tells which JWTs the API accepts. HS256 tokens are checked with HMACSecret, RS256 and ES256 tokens
+// with the keys of a JWK set read from JWKSFile or fetched from JWKSURL. Issuer and Audience, when set, have to
+// match the iss and aud claims.
+type AuthConfig struct {
+	HMACSecret string
+	JWKSFile   string
+	JWKSURL    string
+	Issuer     string
+	Audience   string
+}
+
+// Claims are the claims of a verified token
+type Claims map[string]interface{}
+
+// TokenVerifier checks the signature and the registered claims of JWTs
+type TokenVerifier struct {
+	secret   []byte
+	keys     keySource
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// tokenHeader is the JOSE header of a JWT
+type tokenHeader struct {
+	Algorithm string   `json:"alg"`
+	KeyID     string   `json:"kid"`
+	Critical  []string `json:"crit"`
+}
+
+// registeredClaims are the claims every token is checked against
+type registeredClaims struct {
+	Subject   string       `json:"sub"`
+	Issuer    string       `json:"iss"`
+	Audience  audience     `json:"aud"`
+	ExpiresAt *numericDate `json:"exp"`
+	NotBefore *numericDate `json:"nbf"`
+}
+
+// audience is the aud claim, a single string or an array of them
+type audience []string
+
+func (aud *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*aud = audience{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(aud))
+}
+
+// numericDate is a time in seconds since the epoch, possibly with a fraction
+type numericDate struct {
+	time.Time
+}
+
+func (date *numericDate) UnmarshalJSON(data []byte) error {
+	var seconds json.Number
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+	value, err := seconds.Float64()
+	if err != nil {
+		return err
+	}
+	date.Time = time.Unix(0, 0).Add(time.Duration(value * float64(time.Second)))
+	return nil
+}
+
+// NewTokenVerifier verifies tokens the way config tells, reading the JWKS file right away and fetching the JWKS URL
+// when the first token needs it. Without a secret or a key set every token is refused.
+func NewTokenVerifier(config AuthConfig) (*TokenVerifier, error) {
+	if len(config.JWKSFile) > 0 && len(config.JWKSURL) > 0 {
+		return nil, errors.New("a JWKS file and a JWKS URL cannot both be set")
+	}
+	verifier := &TokenVerifier{secret: []byte(config.HMACSecret), issuer: config.Issuer, audience: config.Audience, now: time.Now}
+	if len(config.JWKSFile) > 0 {
+		keys, err := readKeySetFile(config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.keys = keys
+	}
+	if len(config.JWKSURL) > 0 {
+		verifier.keys = newRemoteKeySet(config.JWKSURL)
+	}
+	return verifier, nil
+}
+
+// Configured tells whether the verifier accepts any token at all
+func (verifier *TokenVerifier) Configured() bool {
+	return len(verifier.secret) > 0 || verifier.keys != nil
+}
+
+// Verify checks the signature of token and its registered claims and returns its subject and claims.
+// Every token needs a subject and an expiry.
+func (verifier *TokenVerifier) Verify(token string) (string, Claims, error) {
+	if len(token) > maxTokenLength {
+		return "", nil, fmt.Errorf("%w: too long", ErrInvalidToken)
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", nil, fmt.Errorf("%w: not a signed JWT", ErrInvalidToken)
+	}
+	var header tokenHeader
+	if err := decodeTokenPart(parts[0], &header); err != nil {
+		return "", nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	if len(header.Critical) > 0 {
+		return "", nil, fmt.Errorf("%w: unsupported critical header %q", ErrInvalidToken, header.Critical[0])
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: signature: %v", ErrInvalidToken, err)
+	}
+	if err := verifier.verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var registered registeredClaims
+	if err := decodeTokenPart(parts[1], &registered); err != nil {
+		return "", nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	if err := verifier.checkClaims(&registered); err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	claims := Claims{}
+	if err := decodeTokenPart(parts[1], &claims); err != nil {
+		return "", nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	return registered.Subject, claims, nil
+}
+
+// verifySignature checks signature over the signing input with the key the header points to. The algorithm picks
+// the kind of key, so that a public key is never taken for an HMAC secret.
+func (verifier *TokenVerifier) verifySignature(header tokenHeader, signingInput string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+	switch header.Algorithm {
+	case "HS256":
+		if len(verifier.secret) == 0 {
+			return errors.New("HS256 tokens are not accepted")
+		}
+		mac := hmac.New(sha256.New, verifier.secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("signature mismatch")
+		}
+		return nil
+	case "RS256", "ES256":
+		if verifier.keys == nil {
+			return fmt.Errorf("%s tokens are not accepted", header.Algorithm)
+		}
+		keys, err := verifier.keys.keys(header.KeyID)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if key.verifies(header.Algorithm, header.KeyID) && key.verify(header.Algorithm, digest[:], signature) {
+				return nil
+			}
+		}
+		return errors.New("signature mismatch or unknown key")
+	}
+	return fmt.Errorf("unsupported algorithm %q", header.Algorithm)
+}
+
+// checkClaims checks the registered claims against the configuration and the current time
+func (verifier *TokenVerifier) checkClaims(claims *registeredClaims) error {
+	now := verifier.now()
+	if len(claims.Subject) == 0 {
+		return errors.New("missing subject")
+	}
+	if claims.ExpiresAt == nil {
+		return errors.New("missing expiry")
+	}
+	if now.After(claims.ExpiresAt.Add(tokenLeeway)) {
+		return errors.New("expired")
+	}
+	if claims.NotBefore != nil && now.Add(tokenLeeway).Before(claims.NotBefore.Time) {
+		return errors.New("not valid yet")
+	}
+	if len(verifier.issuer) > 0 && claims.Issuer != verifier.issuer {
+		return errors.New("wrong issuer")
+	}
+	if len(verifier.audience) > 0 && !slices.Contains(claims.Audience, verifier.audience) {
+		return errors.New("wrong audience")
+	}
+	return nil
+}
+
+// decodeTokenPart decodes the base64url JSON of a header or the claims into value, numbers kept exact
+func decodeTokenPart(part string, value interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
+
+// verificationKey is a public key of a JWK set
+type verificationKey struct {
+	id        string
+	algorithm string
+	key       crypto.PublicKey
+}
+
+// verifies tells whether the key is meant for tokens signed with algorithm by the key keyID, any key of the right
+// kind for tokens that do not name one
+func (key *verificationKey) verifies(algorithm, keyID string) bool {
+	if len(keyID) > 0 && key.id != keyID {
+		return false
+	}
+	if len(key.algorithm) > 0 && key.algorithm != algorithm {
+		return false
+	}
+	switch key.key.(type) {
+	case *rsa.PublicKey:
+		return algorithm == "RS256"
+	case *ecdsa.PublicKey:
+		return algorithm == "ES256"
+	}
+	return false
+}
+
+// verify checks signature over the SHA-256 digest of a token
+func (key *verificationKey) verify(algorithm string, digest, signature []byte) bool {
+	switch publicKey := key.key.(type) {
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		// ES256 signatures are r and s side by side, 32 bytes each
+		if len(signature) != 64 {
+			return false
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(publicKey, digest, r, s)
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/url"
+	"strconv"
+)
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// PageInfo describes where a page sits in a listing.
+// Page and TotalPages are set for offset pagination, the cursors for keyset pagination.
+type PageInfo struct {
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Total      int64  `json:"total"`
+	TotalPages int64  `json:"total_pages,omitempty"`
+	HasNext    bool   `json:"has_next"`
+	HasPrev    bool   `json:"has_prev"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// PageLinks holds ready to follow links to the neighbouring pages, empty when there is none
+type PageLinks struct {
+	Self  string `json:"self"`
+	First string `json:"first"`
+	Last  string `json:"last,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+// parsePage reads page, falling back to the first one
+func parsePage(query url.Values) int {
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		return 1
+	}
+	return page
+}
+
+// parsePageSize reads page_size, falling back to the default and capping it
+func parsePageSize(query url.Values) int {
+	limit, err := strconv.Atoi(query.Get("page_size"))
+	if err != nil || limit < 1 {
+		return defaultPageSize
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
+
+// pageLink returns the current request URL with the params query parameters replaced
+// and the remove ones dropped
+func pageLink(context *gin.Context, params map[string]string, remove ...string) string {
+	link := *context.Request.URL
+	query := link.Query()
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	for _, key := range remove {
+		query.Del(key)
+	}
+	link.RawQuery = query.Encode()
+	return link.RequestURI()
+}
+
+// offsetPage fills page info and links of a listing addressed by page number
+func offsetPage(context *gin.Context, page int, pageSize int, total int64) (PageInfo, PageLinks) {
+	totalPages := (total + int64(pageSize) - 1) / int64(pageSize)
+	info := PageInfo{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    int64(page) < totalPages,
+		HasPrev:    page > 1,
+	}
+	links := PageLinks{
+		Self:  context.Request.URL.RequestURI(),
+		First: pageLink(context, map[string]string{"page": "1"}, "cursor"),
+	}
+	if totalPages > 0 {
+		links.Last = pageLink(context, map[string]string{"page": strconv.FormatInt(totalPages, 10)}, "cursor")
+	}
+	if info.HasNext {
+		links.Next = pageLink(context, map[string]string{"page": strconv.Itoa(page + 1)}, "cursor")
+	}
+	if info.HasPrev {
+		links.Prev = pageLink(context, map[string]string{"page": strconv.Itoa(page - 1)}, "cursor")
+	}
+	return info, links
+}
+
+// cursorPage fills page info and links of a listing addressed by keyset cursors
+func cursorPage(context *gin.Context, pageSize int, total int64, nextCursor, prevCursor string) (PageInfo, PageLinks) {
+	info := PageInfo{
+		PageSize:   pageSize,
+		Total:      total,
+		HasNext:    len(nextCursor) > 0,
+		HasPrev:    len(prevCursor) > 0,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+	}
+	links := PageLinks{
+		Self:  context.Request.URL.RequestURI(),
+		First: pageLink(context, map[string]string{"cursor": ""}, "page"),
+	}
+	if info.HasNext {
+		links.Next = pageLink(context, map[string]string{"cursor": nextCursor}, "page")
+	}
+	if info.HasPrev {
+		links.Prev = pageLink(context, map[string]string{"cursor": prevCursor}, "page")
+	}
+	return info, links
+}
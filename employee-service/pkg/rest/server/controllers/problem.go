@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// ProblemContentType is the media type of every error response
+const ProblemContentType = "application/problem+json"
+
+// internalProblemDetail stands in for the messages of unexpected errors, which may expose database internals
+const internalProblemDetail = "the request could not be handled, the request id points to the details in the logs"
+
+// problemTypePrefix namespaces the problem types, they identify a kind of problem and are not meant to be dereferenced
+const problemTypePrefix = "urn:employee-service:problem:"
+
+// Problem is an error response in the problem details format of RFC 7807, extended by the id of the request,
+// the invalid fields of an employee, a department or a salary change and the permission a forbidden caller lacks
+type Problem struct {
+	// Type identifies the kind of problem, e.g. urn:employee-service:problem:not-found
+	Type   string `json:"type" example:"urn:employee-service:problem:not-found"`
+	Title  string `json:"title" example:"Not found"`
+	Status int    `json:"status" example:"404"`
+	Detail string `json:"detail,omitempty" example:"row not exists"`
+	// Instance is the path of the request that failed
+	Instance string `json:"instance,omitempty" example:"/v1/employees/42"`
+	// RequestID is the X-Request-ID of the request, to find it in the logs
+	RequestID string `json:"request_id" example:"4f2d6c1e9a8b7d3c5e0f1a2b3c4d5e6f"`
+	// Errors lists the invalid fields of a validation-failed problem
+	Errors []*models.FieldError `json:"errors,omitempty"`
+	// MissingPermission is the permission the roles of the caller lack, for a forbidden problem
+	MissingPermission string `json:"missing_permission,omitempty" example:"employees:write"`
+}
+
+// problemKind is a kind of problem the API reports, its slug makes the type
+type problemKind struct {
+	slug   string
+	title  string
+	status int
+}
+
+var (
+	problemBadRequest            = problemKind{"bad-request", "Bad request", http.StatusBadRequest}
+	problemInvalidCursor         = problemKind{"invalid-cursor", "Invalid cursor", http.StatusBadRequest}
+	problemInvalidImport         = problemKind{"invalid-import", "Invalid import", http.StatusBadRequest}
+	problemInvalidPatch          = problemKind{"invalid-patch", "Invalid patch document", http.StatusBadRequest}
+	problemUnauthorized          = problemKind{"unauthorized", "Unauthorized", http.StatusUnauthorized}
+	problemForbidden             = problemKind{"forbidden", "Forbidden", http.StatusForbidden}
+	problemNotFound              = problemKind{"not-found", "Not found", http.StatusNotFound}
+	problemDuplicate             = problemKind{"duplicate", "Already exists", http.StatusConflict}
+	problemNotDeleted            = problemKind{"not-deleted", "Not deleted", http.StatusConflict}
+	problemPatchTestFailed       = problemKind{"patch-test-failed", "Patch test failed", http.StatusConflict}
+	problemDepartmentNotEmpty    = problemKind{"department-not-empty", "Department not empty", http.StatusConflict}
+	problemSalaryChangeEffective = problemKind{"salary-change-effective", "Salary change effective", http.StatusConflict}
+	problemGone                  = problemKind{"gone", "Deleted", http.StatusGone}
+	problemVersionMismatch       = problemKind{"version-mismatch", "Version mismatch", http.StatusPreconditionFailed}
+	problemTooLarge              = problemKind{"too-large", "Request too large", http.StatusRequestEntityTooLarge}
+	problemUnsupportedMediaType  = problemKind{"unsupported-media-type", "Unsupported media type", http.StatusUnsupportedMediaType}
+	problemValidationFailed      = problemKind{"validation-failed", "Validation failed", http.StatusUnprocessableEntity}
+	problemUnprocessable         = problemKind{"unprocessable", "Unprocessable request", http.StatusUnprocessableEntity}
+	problemInternal              = problemKind{"internal", "Internal server error", http.StatusInternalServerError}
+)
+
+// errorProblems maps the errors of the services and daos to the problems they stand for, the first match wins.
+// Any other error is an internal problem whose message stays in the logs.
+var errorProblems = []struct {
+	err  error
+	kind problemKind
+}{
+	{models.ErrInvalidEmployee, problemValidationFailed},
+	{models.ErrInvalidDepartment, problemValidationFailed},
+	{models.ErrDepartmentNotEmpty, problemDepartmentNotEmpty},
+	{models.ErrInvalidSalaryChange, problemValidationFailed},
+	{models.ErrSalaryChangeEffective, problemSalaryChangeEffective},
+	{models.ErrInvalidBatchOperation, problemBadRequest},
+	{models.ErrInvalidCursor, problemInvalidCursor},
+	{models.ErrInvalidImport, problemInvalidImport},
+	{models.ErrInvalidPatch, problemInvalidPatch},
+	{models.ErrPatchTestFailed, problemPatchTestFailed},
+	{models.ErrUnprocessablePatch, problemUnprocessable},
+	{sqls.ErrNotExists, problemNotFound},
+	{sqls.ErrDuplicate, problemDuplicate},
+	{sqls.ErrDeleted, problemGone},
+	{sqls.ErrNotDeleted, problemNotDeleted},
+	{sqls.ErrVersionMismatch, problemVersionMismatch},
+}
+
+// problemOf returns the kind of problem err stands for and the detail that is safe to show to clients
+func problemOf(err error) (problemKind, string) {
+	for _, errorProblem := range errorProblems {
+		if errors.Is(err, errorProblem.err) {
+			return errorProblem.kind, err.Error()
+		}
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return problemTooLarge, err.Error()
+	}
+	return problemInternal, internalProblemDetail
+}
+
+// abortWithError answers with the problem err stands for
+func abortWithError(context *gin.Context, err error) {
+	kind, detail := problemOf(err)
+	abortWithProblemDetail(context, kind, detail, err)
+}
+
+// abortWithProblem answers with a problem of kind that err, a message of the handler itself, details
+func abortWithProblem(context *gin.Context, kind problemKind, err error) {
+	abortWithProblemDetail(context, kind, err.Error(), err)
+}
+
+func abortWithProblemDetail(context *gin.Context, kind problemKind, detail string, err error) {
+	problem := Problem{
+		Type:      problemTypePrefix + kind.slug,
+		Title:     kind.title,
+		Status:    kind.status,
+		Detail:    detail,
+		Instance:  context.Request.URL.Path,
+		RequestID: requestID(context),
+	}
+	var validationError *models.ValidationError
+	if errors.As(err, &validationError) {
+		problem.Errors = validationError.Errors
+	}
+	var permissionError *permissionError
+	if errors.As(err, &permissionError) {
+		problem.MissingPermission = string(permissionError.permission)
+	}
+
+	entry := log.WithField("request_id", problem.RequestID)
+	if kind.status >= http.StatusInternalServerError {
+		entry.Error(err)
+	} else {
+		entry.Warn(err)
+	}
+	context.Header("Content-Type", ProblemContentType)
+	context.AbortWithStatusJSON(kind.status, problem)
+}
+
+// NoRoute answers requests no route matches
+func NoRoute(context *gin.Context) {
+	abortWithProblem(context, problemNotFound, errors.New("no such endpoint "+context.Request.Method+" "+context.Request.URL.Path))
+}
+
+// Recovered answers requests whose handler panicked, gin's recovery has logged the panic already
+func Recovered(context *gin.Context, recovered interface{}) {
+	abortWithProblemDetail(context, problemInternal, internalProblemDetail, errors.New("handler panicked"))
+}
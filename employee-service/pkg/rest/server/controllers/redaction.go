@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/gin-gonic/gin"
+	"slices"
+)
+
+// redactEmployee masks the sensitive fields of employee unless the caller may read salaries
+func redactEmployee(context *gin.Context, employee *models.Employee) *models.Employee {
+	if employee == nil || granted(context, PermissionReadSalaries) {
+		return employee
+	}
+	return employee.Redact()
+}
+
+// redactEmployees masks the sensitive fields of employees unless the caller may read salaries
+func redactEmployees(context *gin.Context, employees []*models.Employee) []*models.Employee {
+	if granted(context, PermissionReadSalaries) {
+		return employees
+	}
+	redacted := make([]*models.Employee, 0, len(employees))
+	for _, employee := range employees {
+		redacted = append(redacted, employee.Redact())
+	}
+	return redacted
+}
+
+// redactAuditEntries masks the values of the sensitive fields changed by entries unless the caller may read salaries
+func redactAuditEntries(context *gin.Context, entries []*models.AuditEntry) []*models.AuditEntry {
+	if granted(context, PermissionReadSalaries) {
+		return entries
+	}
+	redacted := make([]*models.AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		redacted = append(redacted, entry.Redact())
+	}
+	return redacted
+}
+
+// checkSalaryQuery fails with a forbidden problem when a caller who may not read salaries filters or sorts by them,
+// which would tell the salaries all the same
+func checkSalaryQuery(context *gin.Context, filter *models.EmployeeFilter, sort []models.EmployeeSort) bool {
+	usesSalary := filter.SalaryMin != nil || filter.SalaryMax != nil
+	for _, field := range sort {
+		usesSalary = usesSalary || field.Field == "salary"
+	}
+	return !usesSalary || checkPermission(context, PermissionReadSalaries)
+}
+
+// checkSalaryCreate fails with a forbidden problem when a caller who may not write salaries creates employee with a
+// salary or a currency other than the defaults
+func checkSalaryCreate(context *gin.Context, employee *models.Employee) bool {
+	setsSalary := !employee.Salary.IsZero() || len(employee.Currency) > 0 && employee.Currency != models.DefaultCurrency
+	return !setsSalary || checkPermission(context, PermissionWriteSalaries)
+}
+
+// checkSalaryUpdate fails with a forbidden problem when a caller who may not write salaries replaces employee with
+// a salary other than null, which keeps the stored salary and currency
+func checkSalaryUpdate(context *gin.Context, employee *models.Employee) bool {
+	return employee.Salary.Masked() || checkPermission(context, PermissionWriteSalaries)
+}
+
+// checkSalaryPatch fails with a forbidden problem when patch writes the salary or the currency of a caller who may
+// not write salaries, or tests, copies or moves the salary of a caller who may not read salaries
+func checkSalaryPatch(context *gin.Context, patch *models.EmployeePatch) bool {
+	written, read := patch.Fields()
+	touches := func(fields, sensitive []string) bool {
+		return slices.ContainsFunc(fields, func(field string) bool { return slices.Contains(sensitive, field) })
+	}
+	if touches(read, models.SensitiveEmployeeFields) && !checkPermission(context, PermissionReadSalaries) {
+		return false
+	}
+	return !touches(written, models.SalaryFields) || checkPermission(context, PermissionWriteSalaries)
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the id of a request, taken from the client or generated
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request ids taken from clients
+const maxRequestIDLength = 128
+
+// requestIDKey stores the request id in the gin context
+const requestIDKey = "request_id"
+
+// RequestID makes every request carry an id, the one in the X-Request-ID header when it is sane,
+// and sends it back in the same header
+func RequestID() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		id := context.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		context.Set(requestIDKey, id)
+		context.Header(RequestIDHeader, id)
+		context.Next()
+	}
+}
+
+// requestID returns the id RequestID gave the request, and makes one up when the middleware is not installed
+func requestID(context *gin.Context) string {
+	if id := context.GetString(requestIDKey); len(id) > 0 {
+		return id
+	}
+	id := newRequestID()
+	context.Set(requestIDKey, id)
+	context.Header(RequestIDHeader, id)
+	return id
+}
+
+// validRequestID accepts ids that are safe to log and echo, letters, digits and -_.:
+func validRequestID(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' || c == ':') {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id)
+}
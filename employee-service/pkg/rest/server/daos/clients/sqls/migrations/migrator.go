@@ -0,0 +1,422 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/config"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed sqlite/*.sql postgres/*.sql mysql/*.sql
+var scripts embed.FS
+
+var (
+	ErrChecksumMismatch = errors.New("migration checksum mismatch")
+	ErrUnknownVersion   = errors.New("database has a migration this build does not know")
+	ErrPending          = errors.New("database has pending migrations")
+	// ErrLocked means another migrator kept the migration lock for longer than migrationLockTimeout
+	ErrLocked = errors.New("migrations are locked by another migrator")
+	// ErrLockLost means the SQLite lock row of the migrator was taken over, its migration is rolled back
+	ErrLockLost = errors.New("migration lock was taken over by another migrator")
+)
+
+const (
+	// migrationLockTimeout bounds the wait for another migrator, and after that long an SQLite lock row is
+	// taken to be left behind by a migrator that died. The holder refreshes the row as every migration commits,
+	// and while a migration runs its transaction keeps other writers, breaking the lock included, waiting. So a
+	// live migrator only loses the lock if the gap between two of its migrations exceeds the timeout, in which
+	// case its next migration fails with ErrLockLost instead of running alongside the new holder.
+	migrationLockTimeout = 10 * time.Minute
+	migrationLockRetry   = 250 * time.Millisecond
+	// migrationLockKey names the advisory lock on PostgreSQL and MySQL
+	migrationLockKey  = "employee-service.schema_migrations"
+	migrationLockID   = 7305169226134862161
+	migrationLockName = "schema_migrations_lock"
+)
+
+// scriptName matches files such as 0001_create_employees.up.sql
+var scriptName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change with its up and down scripts
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// SchemaMigration is a row of the schema_migrations bookkeeping table
+type SchemaMigration struct {
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:255"`
+	Checksum  string `gorm:"size:64"`
+	AppliedAt time.Time
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus tells whether a known migration is applied to the database
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// Migrator applies the embedded migrations of the client's driver
+type Migrator struct {
+	db         *gorm.DB
+	driver     config.DatabaseDriver
+	migrations []*Migration
+}
+
+func NewMigrator(sqlClient *sqls.SQLClient) (*Migrator, error) {
+	migrations, err := loadMigrations(string(sqlClient.Driver))
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         sqlClient.DB,
+		driver:     sqlClient.Driver,
+		migrations: migrations,
+	}, nil
+}
+
+// Latest returns the version of the newest known migration
+func (migrator *Migrator) Latest() int64 {
+	if len(migrator.migrations) == 0 {
+		return 0
+	}
+	return migrator.migrations[len(migrator.migrations)-1].Version
+}
+
+// Up applies every pending migration up to and including target, 0 meaning the latest.
+// It holds the migration lock, so that instances starting together apply every migration once.
+func (migrator *Migrator) Up(target int64) error {
+	return migrator.locked(func(db *gorm.DB) error {
+		return migrator.up(db, target)
+	})
+}
+
+func (migrator *Migrator) up(db *gorm.DB, target int64) error {
+	applied, err := migrator.verify(db)
+	if err != nil {
+		return err
+	}
+	for _, migration := range migrator.migrations {
+		if target > 0 && migration.Version > target {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Up); err != nil {
+				return err
+			}
+			if err := migrator.refresh(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Checksum:  migration.Checksum,
+				AppliedAt: time.Now().UTC(),
+			}).Error
+		}); err != nil {
+			return fmt.Errorf("migration %04d_%s up: %w", migration.Version, migration.Name, err)
+		}
+		log.Infof("applied migration %04d_%s", migration.Version, migration.Name)
+	}
+	return nil
+}
+
+// Down rolls back the given number of most recently applied migrations, holding the migration lock
+func (migrator *Migrator) Down(steps int) error {
+	return migrator.locked(func(db *gorm.DB) error {
+		return migrator.down(db, steps)
+	})
+}
+
+func (migrator *Migrator) down(db *gorm.DB, steps int) error {
+	applied, err := migrator.verify(db)
+	if err != nil {
+		return err
+	}
+	for i := len(migrator.migrations) - 1; i >= 0 && steps > 0; i-- {
+		migration := migrator.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Down); err != nil {
+				return err
+			}
+			if err := migrator.refresh(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, migration.Version).Error
+		}); err != nil {
+			return fmt.Errorf("migration %04d_%s down: %w", migration.Version, migration.Name, err)
+		}
+		log.Infof("rolled back migration %04d_%s", migration.Version, migration.Name)
+		steps--
+	}
+	return nil
+}
+
+// Verify checks the applied migrations against the embedded ones and fails when some are pending
+func (migrator *Migrator) Verify() error {
+	applied, err := migrator.verify(migrator.db)
+	if err != nil {
+		return err
+	}
+	for _, migration := range migrator.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			return fmt.Errorf("%w: %04d_%s", ErrPending, migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+
+// Status lists every known migration and whether it is applied
+func (migrator *Migrator) Status() ([]*MigrationStatus, error) {
+	applied, err := migrator.verify(migrator.db)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]*MigrationStatus, 0, len(migrator.migrations))
+	for _, migration := range migrator.migrations {
+		status := &MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if schemaMigration, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &schemaMigration.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// verify makes sure the bookkeeping table exists and that applied migrations were not edited
+func (migrator *Migrator) verify(db *gorm.DB) (map[int64]*SchemaMigration, error) {
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		if err := db.Migrator().CreateTable(&SchemaMigration{}); err != nil {
+			return nil, err
+		}
+	}
+
+	var schemaMigrations []*SchemaMigration
+	if err := db.Order("version").Find(&schemaMigrations).Error; err != nil {
+		return nil, err
+	}
+
+	known := make(map[int64]*Migration, len(migrator.migrations))
+	for _, migration := range migrator.migrations {
+		known[migration.Version] = migration
+	}
+	applied := make(map[int64]*SchemaMigration, len(schemaMigrations))
+	for _, schemaMigration := range schemaMigrations {
+		migration, ok := known[schemaMigration.Version]
+		if !ok {
+			return nil, fmt.Errorf("%w: %04d_%s", ErrUnknownVersion, schemaMigration.Version, schemaMigration.Name)
+		}
+		if migration.Checksum != schemaMigration.Checksum {
+			return nil, fmt.Errorf("%w: %04d_%s", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+		applied[schemaMigration.Version] = schemaMigration
+	}
+	return applied, nil
+}
+
+// locked runs migrate on a connection of its own while holding the migration lock: a session advisory lock on
+// PostgreSQL and MySQL, a row of the schema_migrations_lock table on SQLite
+func (migrator *Migrator) locked(migrate func(db *gorm.DB) error) error {
+	return migrator.db.Connection(func(db *gorm.DB) error {
+		deadline := time.Now().Add(migrationLockTimeout)
+		for {
+			acquired, err := migrator.lock(db)
+			if err != nil {
+				return fmt.Errorf("migration lock: %w", err)
+			}
+			if acquired {
+				break
+			}
+			if time.Now().After(deadline) {
+				return ErrLocked
+			}
+			log.Debugf("waiting for another migrator to release the migration lock")
+			time.Sleep(migrationLockRetry)
+		}
+		defer func() {
+			if err := migrator.unlock(db); err != nil {
+				log.Errorf("failed to release the migration lock: %v", err)
+			}
+		}()
+		return migrate(db)
+	})
+}
+
+// lock tries to take the migration lock once
+func (migrator *Migrator) lock(db *gorm.DB) (bool, error) {
+	switch migrator.driver {
+	case config.DatabaseDriverPostgres:
+		var acquired bool
+		err := db.Raw("SELECT pg_try_advisory_lock(?)", migrationLockID).Scan(&acquired).Error
+		return acquired, err
+	case config.DatabaseDriverMySQL:
+		var acquired *int
+		err := db.Raw("SELECT GET_LOCK(?, 0)", migrationLockKey).Scan(&acquired).Error
+		return acquired != nil && *acquired == 1, err
+	}
+	// SQLite has no sessions to lock for, writers queue on the database file instead
+	if err := db.Exec(fmt.Sprintf("PRAGMA busy_timeout = %d", migrationLockTimeout.Milliseconds())).Error; err != nil {
+		return false, err
+	}
+	if err := db.Exec("CREATE TABLE IF NOT EXISTS `" + migrationLockName + "` (`id` integer PRIMARY KEY, `locked_at` datetime NOT NULL)").Error; err != nil {
+		return false, err
+	}
+	now := time.Now().UTC()
+	if err := db.Exec("DELETE FROM `"+migrationLockName+"` WHERE `locked_at` < ?", now.Add(-migrationLockTimeout)).Error; err != nil {
+		return false, err
+	}
+	result := db.Exec("INSERT OR IGNORE INTO `"+migrationLockName+"` (`id`, `locked_at`) VALUES (1, ?)", now)
+	return result.RowsAffected == 1, result.Error
+}
+
+// refresh stamps the SQLite lock row with the commit of the migration of tx, so that it is not taken for the one of
+// a migrator that died, and fails when the row is no longer there. Advisory locks last as long as their session.
+func (migrator *Migrator) refresh(tx *gorm.DB) error {
+	if migrator.driver != config.DatabaseDriverSQLite {
+		return nil
+	}
+	result := tx.Exec("UPDATE `"+migrationLockName+"` SET `locked_at` = ? WHERE `id` = 1", time.Now().UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// unlock releases the migration lock taken by lock
+func (migrator *Migrator) unlock(db *gorm.DB) error {
+	switch migrator.driver {
+	case config.DatabaseDriverPostgres:
+		return db.Exec("SELECT pg_advisory_unlock(?)", migrationLockID).Error
+	case config.DatabaseDriverMySQL:
+		return db.Exec("SELECT RELEASE_LOCK(?)", migrationLockKey).Error
+	}
+	return db.Exec("DELETE FROM `" + migrationLockName + "` WHERE `id` = 1").Error
+}
+
+// loadMigrations reads the embedded scripts of a driver ordered by version
+func loadMigrations(driver string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(scripts, driver)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for driver %s: %w", driver, err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		matches := scriptName.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("unexpected migration file %s/%s", driver, entry.Name())
+		}
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		content, err := fs.ReadFile(scripts, path.Join(driver, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration %04d has two names, %s and %s", version, migration.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if len(migration.Up) == 0 || len(migration.Down) == 0 {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down script", migration.Version, migration.Name)
+		}
+		sum := sha256.Sum256([]byte(migration.Up + "\x00" + migration.Down))
+		migration.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// execScript runs the statements of a script one by one, since not every driver accepts several per call
+func execScript(tx *gorm.DB, script string) error {
+	for _, statement := range splitStatements(script) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements splits a script on semicolons ending a line, keeping BEGIN ... END blocks
+// (triggers) and $$ quoted bodies (postgres functions) together
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	depth := 0
+	inDollarQuote := false
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if len(trimmed) == 0 || (current.Len() == 0 && strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		inDollarQuote = inDollarQuote != (strings.Count(trimmed, "$$")%2 == 1)
+		upper := strings.ToUpper(trimmed)
+		if strings.HasSuffix(upper, "BEGIN") {
+			depth++
+		}
+		if depth > 0 && (upper == "END;" || upper == "END") {
+			depth--
+		}
+		if depth == 0 && !inDollarQuote && strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); len(rest) > 0 {
+		statements = append(statements, rest)
+	}
+	return statements
+}
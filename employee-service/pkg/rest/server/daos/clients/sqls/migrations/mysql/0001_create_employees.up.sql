@@ -0,0 +1,12 @@
+-- matches the table previously created by gorm AutoMigrate so existing databases are adopted as is
+CREATE TABLE IF NOT EXISTS `employees` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `name` longtext,
+    `position` longtext,
+    `salary` double,
+    PRIMARY KEY (`id`),
+    INDEX `idx_employees_deleted_at` (`deleted_at`)
+);
@@ -0,0 +1,2 @@
+-- bumped on every write, backs the ETag of an employee
+ALTER TABLE `employees` ADD COLUMN `version` bigint unsigned NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+ALTER TABLE `employees` DROP FOREIGN KEY `fk_employees_department`;
+ALTER TABLE `employees` DROP INDEX `idx_employees_department_id`, DROP COLUMN `department_id`;
+DROP TABLE IF EXISTS `departments`;
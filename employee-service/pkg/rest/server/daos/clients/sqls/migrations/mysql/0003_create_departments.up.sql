@@ -0,0 +1,13 @@
+-- organizational units, an employee belongs to at most one
+CREATE TABLE IF NOT EXISTS `departments` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `name` varchar(191) NOT NULL,
+    `description` longtext,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_departments_name` (`name`)
+);
+ALTER TABLE `employees` ADD COLUMN `department_id` bigint unsigned NULL,
+    ADD INDEX `idx_employees_department_id` (`department_id`),
+    ADD CONSTRAINT `fk_employees_department` FOREIGN KEY (`department_id`) REFERENCES `departments` (`id`) ON DELETE RESTRICT;
@@ -0,0 +1,2 @@
+ALTER TABLE `employees` DROP FOREIGN KEY `fk_employees_manager`;
+ALTER TABLE `employees` DROP INDEX `idx_employees_manager_id`, DROP COLUMN `manager_id`;
@@ -0,0 +1,4 @@
+-- the employee's manager, purging a manager detaches the reports first
+ALTER TABLE `employees` ADD COLUMN `manager_id` bigint unsigned NULL,
+    ADD INDEX `idx_employees_manager_id` (`manager_id`),
+    ADD CONSTRAINT `fk_employees_manager` FOREIGN KEY (`manager_id`) REFERENCES `employees` (`id`) ON DELETE RESTRICT;
@@ -0,0 +1,17 @@
+-- every salary an employee had or is scheduled to have, employees.salary holds the latest one effective today
+CREATE TABLE IF NOT EXISTS `salary_changes` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `employee_id` bigint unsigned NOT NULL,
+    `salary` double NOT NULL,
+    `effective_date` date NOT NULL,
+    `reason` longtext,
+    `author` longtext,
+    PRIMARY KEY (`id`),
+    INDEX `idx_salary_changes_employee_id` (`employee_id`, `effective_date`),
+    CONSTRAINT `fk_salary_changes_employee` FOREIGN KEY (`employee_id`) REFERENCES `employees` (`id`) ON DELETE CASCADE
+);
+-- the history starts with the salaries as of the last update of each employee
+INSERT INTO `salary_changes` (`created_at`, `employee_id`, `salary`, `effective_date`, `reason`)
+SELECT UTC_TIMESTAMP(3), `id`, COALESCE(`salary`, 0), DATE(COALESCE(`updated_at`, UTC_TIMESTAMP())), 'recorded when the salary history was introduced'
+FROM `employees`;
@@ -0,0 +1,4 @@
+ALTER TABLE `salary_changes` MODIFY `salary` double NOT NULL, DROP COLUMN `currency`;
+UPDATE `salary_changes` SET `salary` = `salary` / 10000;
+ALTER TABLE `employees` MODIFY `salary` double NULL DEFAULT NULL, DROP COLUMN `currency`;
+UPDATE `employees` SET `salary` = `salary` / 10000;
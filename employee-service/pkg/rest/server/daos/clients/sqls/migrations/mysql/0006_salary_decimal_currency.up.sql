@@ -0,0 +1,6 @@
+-- salaries become exact: an integer count of ten-thousandths of their currency, which has an ISO 4217 code.
+-- Salaries recorded so far are taken to be US dollars.
+UPDATE `employees` SET `salary` = ROUND(COALESCE(`salary`, 0) * 10000);
+ALTER TABLE `employees` MODIFY `salary` bigint NOT NULL DEFAULT 0, ADD COLUMN `currency` varchar(3) NOT NULL DEFAULT 'USD';
+UPDATE `salary_changes` SET `salary` = ROUND(`salary` * 10000);
+ALTER TABLE `salary_changes` MODIFY `salary` bigint NOT NULL, ADD COLUMN `currency` varchar(3) NOT NULL DEFAULT 'USD';
@@ -0,0 +1,21 @@
+-- every change of an employee, kept after the employee is purged and never updated nor deleted
+CREATE TABLE IF NOT EXISTS `audit_entries` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NOT NULL,
+    `employee_id` bigint unsigned NOT NULL,
+    `operation` varchar(16) NOT NULL,
+    `actor` varchar(191),
+    `request_id` varchar(191),
+    `changes` longtext NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_audit_entries_employee_id` (`employee_id`, `id`),
+    INDEX `idx_audit_entries_created_at` (`created_at`)
+);
+CREATE TRIGGER `audit_entries_no_update` BEFORE UPDATE ON `audit_entries` FOR EACH ROW
+BEGIN
+    SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit entries are append-only';
+END;
+CREATE TRIGGER `audit_entries_no_delete` BEFORE DELETE ON `audit_entries` FOR EACH ROW
+BEGIN
+    SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit entries are append-only';
+END;
@@ -0,0 +1,11 @@
+-- matches the table previously created by gorm AutoMigrate so existing databases are adopted as is
+CREATE TABLE IF NOT EXISTS "employees" (
+    "id" bigserial PRIMARY KEY,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" text,
+    "position" text,
+    "salary" decimal
+);
+CREATE INDEX IF NOT EXISTS "idx_employees_deleted_at" ON "employees" ("deleted_at");
@@ -0,0 +1,11 @@
+-- organizational units, an employee belongs to at most one
+CREATE TABLE IF NOT EXISTS "departments" (
+    "id" bigserial PRIMARY KEY,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "name" text NOT NULL,
+    "description" text
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_departments_name" ON "departments" ("name");
+ALTER TABLE "employees" ADD COLUMN IF NOT EXISTS "department_id" bigint REFERENCES "departments" ("id") ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS "idx_employees_department_id" ON "employees" ("department_id");
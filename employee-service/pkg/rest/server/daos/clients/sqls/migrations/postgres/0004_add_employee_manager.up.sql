@@ -0,0 +1,3 @@
+-- the employee's manager, purging a manager detaches the reports first
+ALTER TABLE "employees" ADD COLUMN IF NOT EXISTS "manager_id" bigint REFERENCES "employees" ("id") ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS "idx_employees_manager_id" ON "employees" ("manager_id");
@@ -0,0 +1,6 @@
+ALTER TABLE "salary_changes" DROP COLUMN IF EXISTS "currency";
+ALTER TABLE "salary_changes" ALTER COLUMN "salary" TYPE decimal USING "salary" / 10000.0;
+ALTER TABLE "employees" DROP COLUMN IF EXISTS "currency";
+ALTER TABLE "employees" ALTER COLUMN "salary" DROP NOT NULL;
+ALTER TABLE "employees" ALTER COLUMN "salary" DROP DEFAULT;
+ALTER TABLE "employees" ALTER COLUMN "salary" TYPE decimal USING "salary" / 10000.0;
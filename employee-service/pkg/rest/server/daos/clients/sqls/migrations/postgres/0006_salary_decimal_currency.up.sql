@@ -0,0 +1,8 @@
+-- salaries become exact: an integer count of ten-thousandths of their currency, which has an ISO 4217 code.
+-- Salaries recorded so far are taken to be US dollars.
+ALTER TABLE "employees" ALTER COLUMN "salary" TYPE bigint USING ROUND(COALESCE("salary", 0) * 10000);
+ALTER TABLE "employees" ALTER COLUMN "salary" SET DEFAULT 0;
+ALTER TABLE "employees" ALTER COLUMN "salary" SET NOT NULL;
+ALTER TABLE "employees" ADD COLUMN "currency" varchar(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE "salary_changes" ALTER COLUMN "salary" TYPE bigint USING ROUND("salary" * 10000);
+ALTER TABLE "salary_changes" ADD COLUMN "currency" varchar(3) NOT NULL DEFAULT 'USD';
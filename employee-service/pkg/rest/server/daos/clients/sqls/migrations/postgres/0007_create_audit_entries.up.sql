@@ -0,0 +1,19 @@
+-- every change of an employee, kept after the employee is purged and never updated nor deleted
+CREATE TABLE IF NOT EXISTS "audit_entries" (
+    "id" bigserial PRIMARY KEY,
+    "created_at" timestamptz NOT NULL,
+    "employee_id" bigint NOT NULL,
+    "operation" text NOT NULL,
+    "actor" text,
+    "request_id" text,
+    "changes" text NOT NULL
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_entries_employee_id" ON "audit_entries" ("employee_id", "id");
+CREATE INDEX IF NOT EXISTS "idx_audit_entries_created_at" ON "audit_entries" ("created_at");
+CREATE OR REPLACE FUNCTION "audit_entries_append_only"() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit entries are append-only';
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER "audit_entries_append_only" BEFORE UPDATE OR DELETE ON "audit_entries"
+    FOR EACH ROW EXECUTE FUNCTION "audit_entries_append_only"();
@@ -0,0 +1,11 @@
+-- matches the table previously created by gorm AutoMigrate so existing databases are adopted as is
+CREATE TABLE IF NOT EXISTS `employees` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `name` text,
+    `position` text,
+    `salary` real
+);
+CREATE INDEX IF NOT EXISTS `idx_employees_deleted_at` ON `employees` (`deleted_at`);
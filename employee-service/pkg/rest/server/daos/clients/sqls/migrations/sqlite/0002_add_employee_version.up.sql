@@ -0,0 +1,2 @@
+-- bumped on every write, backs the ETag of an employee
+ALTER TABLE `employees` ADD COLUMN `version` integer NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS `idx_employees_department_id`;
+ALTER TABLE `employees` DROP COLUMN `department_id`;
+DROP TABLE IF EXISTS `departments`;
@@ -0,0 +1,13 @@
+-- organizational units, an employee belongs to at most one
+CREATE TABLE IF NOT EXISTS `departments` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `name` text NOT NULL,
+    `description` text
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_departments_name` ON `departments` (`name`);
+-- no REFERENCES clause, SQLite cannot drop a column used by a foreign key and only checks them when asked to,
+-- the daos enforce the reference instead
+ALTER TABLE `employees` ADD COLUMN `department_id` integer;
+CREATE INDEX IF NOT EXISTS `idx_employees_department_id` ON `employees` (`department_id`);
@@ -0,0 +1,3 @@
+-- the employee's manager, no REFERENCES clause for the same reason as department_id
+ALTER TABLE `employees` ADD COLUMN `manager_id` integer;
+CREATE INDEX IF NOT EXISTS `idx_employees_manager_id` ON `employees` (`manager_id`);
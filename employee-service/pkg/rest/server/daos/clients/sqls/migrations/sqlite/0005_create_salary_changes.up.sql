@@ -0,0 +1,15 @@
+-- every salary an employee had or is scheduled to have, employees.salary holds the latest one effective today
+CREATE TABLE IF NOT EXISTS `salary_changes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `employee_id` integer NOT NULL REFERENCES `employees` (`id`) ON DELETE CASCADE,
+    `salary` real NOT NULL,
+    `effective_date` date NOT NULL,
+    `reason` text,
+    `author` text
+);
+CREATE INDEX IF NOT EXISTS `idx_salary_changes_employee_id` ON `salary_changes` (`employee_id`, `effective_date`);
+-- the history starts with the salaries as of the last update of each employee
+INSERT INTO `salary_changes` (`created_at`, `employee_id`, `salary`, `effective_date`, `reason`)
+SELECT CURRENT_TIMESTAMP, `id`, COALESCE(`salary`, 0), DATE(COALESCE(`updated_at`, CURRENT_TIMESTAMP)), 'recorded when the salary history was introduced'
+FROM `employees`;
@@ -0,0 +1,10 @@
+ALTER TABLE `salary_changes` DROP COLUMN `currency`;
+ALTER TABLE `salary_changes` ADD COLUMN `salary_real` real NOT NULL DEFAULT 0;
+UPDATE `salary_changes` SET `salary_real` = `salary` / 10000.0;
+ALTER TABLE `salary_changes` DROP COLUMN `salary`;
+ALTER TABLE `salary_changes` RENAME COLUMN `salary_real` TO `salary`;
+ALTER TABLE `employees` DROP COLUMN `currency`;
+ALTER TABLE `employees` ADD COLUMN `salary_real` real;
+UPDATE `employees` SET `salary_real` = `salary` / 10000.0;
+ALTER TABLE `employees` DROP COLUMN `salary`;
+ALTER TABLE `employees` RENAME COLUMN `salary_real` TO `salary`;
@@ -0,0 +1,12 @@
+-- salaries become exact: an integer count of ten-thousandths of their currency, which has an ISO 4217 code.
+-- Salaries recorded so far are taken to be US dollars.
+ALTER TABLE `employees` ADD COLUMN `salary_units` integer NOT NULL DEFAULT 0;
+UPDATE `employees` SET `salary_units` = CAST(ROUND(COALESCE(`salary`, 0) * 10000) AS integer);
+ALTER TABLE `employees` DROP COLUMN `salary`;
+ALTER TABLE `employees` RENAME COLUMN `salary_units` TO `salary`;
+ALTER TABLE `employees` ADD COLUMN `currency` text NOT NULL DEFAULT 'USD';
+ALTER TABLE `salary_changes` ADD COLUMN `salary_units` integer NOT NULL DEFAULT 0;
+UPDATE `salary_changes` SET `salary_units` = CAST(ROUND(`salary` * 10000) AS integer);
+ALTER TABLE `salary_changes` DROP COLUMN `salary`;
+ALTER TABLE `salary_changes` RENAME COLUMN `salary_units` TO `salary`;
+ALTER TABLE `salary_changes` ADD COLUMN `currency` text NOT NULL DEFAULT 'USD';
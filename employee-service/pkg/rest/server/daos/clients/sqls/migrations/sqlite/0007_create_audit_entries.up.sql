@@ -0,0 +1,20 @@
+-- every change of an employee, kept after the employee is purged and never updated nor deleted
+CREATE TABLE IF NOT EXISTS `audit_entries` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime NOT NULL,
+    `employee_id` integer NOT NULL,
+    `operation` text NOT NULL,
+    `actor` text,
+    `request_id` text,
+    `changes` text NOT NULL
+);
+CREATE INDEX IF NOT EXISTS `idx_audit_entries_employee_id` ON `audit_entries` (`employee_id`, `id`);
+CREATE INDEX IF NOT EXISTS `idx_audit_entries_created_at` ON `audit_entries` (`created_at`);
+CREATE TRIGGER IF NOT EXISTS `audit_entries_no_update` BEFORE UPDATE ON `audit_entries`
+BEGIN
+    SELECT RAISE(ABORT, 'audit entries are append-only');
+END;
+CREATE TRIGGER IF NOT EXISTS `audit_entries_no_delete` BEFORE DELETE ON `audit_entries`
+BEGIN
+    SELECT RAISE(ABORT, 'audit entries are append-only');
+END;
@@ -0,0 +1,12 @@
+package sqls
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/config"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// openMySQL returns the dialector for a DSN such as "root:password@tcp(localhost:3306)/employees?parseTime=true"
+func openMySQL(databaseConfig *config.DatabaseConfig) gorm.Dialector {
+	return mysql.Open(databaseConfig.DSN)
+}
@@ -0,0 +1,12 @@
+package sqls
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// openPostgres returns the dialector for a DSN such as "host=localhost user=postgres dbname=employees sslmode=disable"
+func openPostgres(databaseConfig *config.DatabaseConfig) gorm.Dialector {
+	return postgres.Open(databaseConfig.DSN)
+}
@@ -0,0 +1,119 @@
+package sqls
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/config"
+	log "github.com/sirupsen/logrus"
+	"github.com/uptrace/opentelemetry-go-extra/otelgorm"
+	"gorm.io/gorm"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDuplicate    = errors.New("record already exists")
+	ErrNotExists    = errors.New("row not exists")
+	ErrUpdateFailed = errors.New("update failed")
+	ErrDeleteFailed = errors.New("delete failed")
+	// ErrVersionMismatch means the row was changed since the version the write was based on
+	ErrVersionMismatch = errors.New("version mismatch")
+	// ErrDeleted means the row exists but was soft deleted, ErrNotDeleted that it was not
+	ErrDeleted    = errors.New("row deleted")
+	ErrNotDeleted = errors.New("row not deleted")
+)
+
+var o sync.Once
+
+// SQLClient wraps the gorm connection of whichever backend was configured
+type SQLClient struct {
+	DB          *gorm.DB
+	Driver      config.DatabaseDriver
+	Mode        config.DatabaseMode
+	AutoMigrate bool
+	// FullTextSearch is set when the database supports SQLite FTS5, see the sqlite_fts5 build tag
+	FullTextSearch bool
+}
+
+// ReadOnly reports whether the database was opened without write access
+func (sqlClient *SQLClient) ReadOnly() bool {
+	return sqlClient.Mode == config.DatabaseModeReadOnly
+}
+
+var err error
+var sqlClient *SQLClient
+
+// InitGORMDB opens the database described by the environment once and shares it afterwards
+func InitGORMDB() (*SQLClient, error) {
+	o.Do(func() {
+		var databaseConfig *config.DatabaseConfig
+		databaseConfig, err = config.LoadDatabaseConfig()
+		if err != nil {
+			log.Errorf("invalid database configuration, %v", err)
+			return
+		}
+		sqlClient, err = NewGORMDB(databaseConfig)
+	})
+
+	return sqlClient, err
+}
+
+// NewGORMDB opens a new connection pool for the given configuration
+func NewGORMDB(databaseConfig *config.DatabaseConfig) (*SQLClient, error) {
+	var dialector gorm.Dialector
+	var err error
+	switch databaseConfig.Driver {
+	case config.DatabaseDriverSQLite:
+		dialector, err = openSQLite(databaseConfig)
+	case config.DatabaseDriverPostgres:
+		dialector = openPostgres(databaseConfig)
+	case config.DatabaseDriverMySQL:
+		dialector = openMySQL(databaseConfig)
+	default:
+		err = fmt.Errorf("unsupported database driver %q", databaseConfig.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// timestamps are kept in UTC so that they compare correctly with filter bounds, unique violations
+	// come back as gorm.ErrDuplicatedKey whatever the driver
+	db, err := gorm.Open(dialector, &gorm.Config{
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+		TranslateError: true,
+	})
+	if err != nil {
+		log.Debugf("database connection error, %v", err)
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(databaseConfig.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(databaseConfig.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(databaseConfig.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(databaseConfig.ConnMaxIdleTime)
+
+	serviceName := os.Getenv("SERVICE_NAME")
+	collectorURL := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if len(serviceName) > 0 && len(collectorURL) > 0 {
+		if err := db.Use(otelgorm.NewPlugin()); err != nil {
+			log.Debugf("unable to attach opentel plugin error, %v", err)
+			return nil, err
+		}
+	}
+	log.Infof("using %s database in %s mode", databaseConfig.Driver, databaseConfig.Mode)
+
+	return &SQLClient{
+		DB:             db,
+		Driver:         databaseConfig.Driver,
+		Mode:           databaseConfig.Mode,
+		AutoMigrate:    databaseConfig.AutoMigrate,
+		FullTextSearch: databaseConfig.Driver == config.DatabaseDriverSQLite && sqliteHasFTS5(db),
+	}, nil
+}
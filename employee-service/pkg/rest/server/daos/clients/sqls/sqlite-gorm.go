@@ -1,63 +1,68 @@
 package sqls
 
 import (
-	"errors"
-	log "github.com/sirupsen/logrus"
-	"github.com/uptrace/opentelemetry-go-extra/otelgorm"
+	"database/sql"
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/config"
+	"github.com/mattn/go-sqlite3"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"os"
-	"sync"
+	"strings"
 )
 
-var (
-	ErrDuplicate    = errors.New("record already exists")
-	ErrNotExists    = errors.New("row not exists")
-	ErrUpdateFailed = errors.New("update failed")
-	ErrDeleteFailed = errors.New("delete failed")
-)
-
-var o sync.Once
+// sqliteDriverName is go-sqlite3 with LOWER folding case by the Unicode rules like strings.ToLower, the built-in
+// one only folds ASCII, so that case-insensitive filters and searches agree with PostgreSQL, MySQL and the memory dao
+const sqliteDriverName = "sqlite3_unicode"
 
-const FileName = "rest-sqlite.db"
+func init() {
+	sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("lower", strings.ToLower, true)
+		},
+	})
+}
 
-type SQLiteClient struct {
-	DB *gorm.DB
+// openSQLiteDSN returns the dialector of dsn on sqliteDriverName
+func openSQLiteDSN(dsn string) gorm.Dialector {
+	return &sqlite.Dialector{DriverName: sqliteDriverName, DSN: dsn}
 }
 
-var err error
-var sqliteClient *SQLiteClient
+// openSQLite applies the configured mode to the database file and returns its dialector
+func openSQLite(databaseConfig *config.DatabaseConfig) (gorm.Dialector, error) {
+	dsn := databaseConfig.FilePath
+	if len(databaseConfig.DSN) > 0 {
+		dsn = databaseConfig.DSN
+	}
+	// a DSN may carry driver parameters after the file name, like file:employees.db?_busy_timeout=5000
+	filePath, _, hasParams := strings.Cut(strings.TrimPrefix(dsn, "file:"), "?")
 
-func InitGORMSQLiteDB() (*SQLiteClient, error) {
-	o.Do(func() {
-		if _, err = os.Stat(FileName); err == nil {
-			err = os.Remove(FileName)
-			if err != nil {
-				log.Debugf("unable to remove database file, %v", err)
-				os.Exit(1)
+	switch databaseConfig.Mode {
+	case config.DatabaseModeEphemeral:
+		if _, err := os.Stat(filePath); err == nil {
+			if err := os.Remove(filePath); err != nil {
+				return nil, fmt.Errorf("unable to remove database file: %w", err)
 			}
 		}
-
-		var db *gorm.DB
-		db, err = gorm.Open(sqlite.Open(FileName), &gorm.Config{})
-		if err != nil {
-			log.Debugf("database connection error, %v", err)
-			os.Exit(1)
+	case config.DatabaseModeReadOnly:
+		if _, err := os.Stat(filePath); err != nil {
+			return nil, fmt.Errorf("readonly mode needs an existing database file: %w", err)
 		}
-		serviceName := os.Getenv("SERVICE_NAME")
-		collectorURL := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
-		if len(serviceName) > 0 && len(collectorURL) > 0 {
-			if err := db.Use(otelgorm.NewPlugin()); err != nil {
-				log.Debugf("unable to attach opentel plugin error, %v", err)
-				os.Exit(1)
-			}
+		separator := "?"
+		if hasParams {
+			separator = "&"
 		}
+		return openSQLiteDSN(fmt.Sprintf("file:%s%smode=ro", strings.TrimPrefix(dsn, "file:"), separator)), nil
+	}
+	return openSQLiteDSN(dsn), nil
+}
 
-		sqliteClient = &SQLiteClient{
-			DB: db,
-		}
-
-	})
-
-	return sqliteClient, err
+// sqliteHasFTS5 reports whether the linked SQLite was compiled with FTS5,
+// which go-sqlite3 only does with the sqlite_fts5 build tag
+func sqliteHasFTS5(db *gorm.DB) bool {
+	var enabled int
+	if err := db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled).Error; err != nil {
+		return false
+	}
+	return enabled == 1
 }
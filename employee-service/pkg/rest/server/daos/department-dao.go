@@ -0,0 +1,186 @@
+package daos
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type DepartmentDao struct {
+	db *gorm.DB
+}
+
+// NewDepartmentDao expects the schema to be migrated already, see the migrations package
+func NewDepartmentDao(sqlClient *sqls.SQLClient) *DepartmentDao {
+	return &DepartmentDao{
+		db: sqlClient.DB,
+	}
+}
+
+func (departmentDao *DepartmentDao) CreateDepartment(m *models.Department) (*models.Department, error) {
+	if err := departmentDao.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkDepartmentName(tx, 0, m.Name); err != nil {
+			return err
+		}
+		return duplicateError(tx.Create(&m).Error)
+	}); err != nil {
+		log.Debugf("failed to create department: %v", err)
+		return nil, err
+	}
+
+	log.Debugf("department created")
+	return m, nil
+}
+
+func (departmentDao *DepartmentDao) GetDepartment(id int64) (*models.Department, error) {
+	m, err := findDepartment(departmentDao.db, id)
+	if err != nil {
+		log.Debugf("failed to get department: %v", err)
+		return nil, err
+	}
+	log.Debugf("department retrieved")
+	return m, nil
+}
+
+func (departmentDao *DepartmentDao) GetDepartments(page int, limit int) ([]*models.Department, int64, error) {
+	var total int64
+	if err := departmentDao.db.Model(&models.Department{}).Count(&total).Error; err != nil {
+		log.Debugf("failed to count departments: %v", err)
+		return nil, 0, err
+	}
+	var departments []*models.Department
+	if err := departmentDao.db.Order("name").Order("id").Offset((page - 1) * limit).Limit(limit).Find(&departments).Error; err != nil {
+		log.Debugf("failed to get departments: %v", err)
+		return nil, 0, err
+	}
+	log.Debugf("departments retrieved")
+	return departments, total, nil
+}
+
+func (departmentDao *DepartmentDao) UpdateDepartment(id int64, m *models.Department) (*models.Department, error) {
+	if err := departmentDao.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findDepartment(tx, id); err != nil {
+			return err
+		}
+		if err := checkDepartmentName(tx, id, m.Name); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Department{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"name":        m.Name,
+			"description": m.Description,
+		}).Error; err != nil {
+			return duplicateError(err)
+		}
+		return tx.Where("id = ?", id).First(m).Error
+	}); err != nil {
+		log.Debugf("failed to update department: %v", err)
+		return nil, err
+	}
+	log.Debugf("department updated")
+	return m, nil
+}
+
+func (departmentDao *DepartmentDao) DeleteDepartment(id int64, reassignTo int64, audit models.AuditContext) error {
+	if err := departmentDao.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findDepartment(tx, id); err != nil {
+			return err
+		}
+		// every write to an employee bumps its version, moving it to another department included
+		var memberIDs []uint
+		if err := tx.Unscoped().Model(&models.Employee{}).Where("department_id = ?", id).Pluck("id", &memberIDs).Error; err != nil {
+			return err
+		}
+		members := tx.Unscoped().Model(&models.Employee{}).Where("id IN ?", memberIDs)
+		if reassignTo != 0 {
+			if err := checkReassignTarget(tx, id, reassignTo); err != nil {
+				return err
+			}
+			if err := auditUpdates(tx, audit, memberIDs, func() error {
+				return members.Updates(map[string]interface{}{"department_id": reassignTo, "version": gorm.Expr("version + 1")}).Error
+			}); err != nil {
+				return err
+			}
+		} else {
+			var count int64
+			if err := tx.Model(&models.Employee{}).Where("department_id = ?", id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return fmt.Errorf("%w: %d employees belong to department %d", models.ErrDepartmentNotEmpty, count, id)
+			}
+			if err := auditUpdates(tx, audit, memberIDs, func() error {
+				return members.Updates(map[string]interface{}{"department_id": nil, "version": gorm.Expr("version + 1")}).Error
+			}); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("id = ?", id).Delete(&models.Department{}).Error; err != nil {
+			return fmt.Errorf("%w: %v", sqls.ErrDeleteFailed, err)
+		}
+		return nil
+	}); err != nil {
+		log.Debugf("failed to delete department: %v", err)
+		return err
+	}
+
+	log.Debugf("department deleted")
+	return nil
+}
+
+func findDepartment(tx *gorm.DB, id int64) (*models.Department, error) {
+	var department *models.Department
+	if err := tx.Where("id = ?", id).First(&department).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sqls.ErrNotExists
+		}
+		return nil, err
+	}
+	return department, nil
+}
+
+// checkDepartmentName fails with sqls.ErrDuplicate when a department other than id is called name
+func checkDepartmentName(tx *gorm.DB, id int64, name string) error {
+	var count int64
+	if err := tx.Model(&models.Department{}).Where("name = ? AND id <> ?", name, id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: a department is called %q already", sqls.ErrDuplicate, name)
+	}
+	return nil
+}
+
+// checkEmployeeDepartment fails with a validation error when departmentID names a department that does not exist
+func checkEmployeeDepartment(tx *gorm.DB, departmentID *uint) error {
+	if departmentID == nil {
+		return nil
+	}
+	if _, err := findDepartment(tx, int64(*departmentID)); err != nil {
+		if errors.Is(err, sqls.ErrNotExists) {
+			return unknownDepartmentError(*departmentID)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkReassignTarget fails with a validation error unless reassignTo is another existing department
+func checkReassignTarget(tx *gorm.DB, id int64, reassignTo int64) error {
+	if reassignTo == id {
+		return models.NewDepartmentFieldValidationError("reassign_to", models.FieldErrorInvalid, "reassign_to must be another department")
+	}
+	if _, err := findDepartment(tx, reassignTo); err != nil {
+		if errors.Is(err, sqls.ErrNotExists) {
+			return models.NewDepartmentFieldValidationError("reassign_to", models.FieldErrorUnknownDepartment, fmt.Sprintf("department %d does not exist", reassignTo))
+		}
+		return err
+	}
+	return nil
+}
+
+func unknownDepartmentError(departmentID uint) error {
+	return models.NewFieldValidationError("department_id", models.FieldErrorUnknownDepartment, fmt.Sprintf("department %d does not exist", departmentID))
+}
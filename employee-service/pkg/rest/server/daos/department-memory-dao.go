@@ -0,0 +1,155 @@
+package daos
+
+import (
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"sort"
+	"time"
+)
+
+func (employeeMemoryDao *EmployeeMemoryDao) CreateDepartment(m *models.Department) (*models.Department, error) {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	if err := employeeMemoryDao.checkDepartmentName(0, m.Name); err != nil {
+		return nil, err
+	}
+	if m.ID == 0 {
+		employeeMemoryDao.lastDepartmentID++
+		m.ID = employeeMemoryDao.lastDepartmentID
+	} else if _, ok := employeeMemoryDao.departments[m.ID]; ok {
+		return nil, sqls.ErrDuplicate
+	} else if m.ID > employeeMemoryDao.lastDepartmentID {
+		employeeMemoryDao.lastDepartmentID = m.ID
+	}
+	now := time.Now()
+	m.CreatedAt, m.UpdatedAt = now, now
+	employeeMemoryDao.departments[m.ID] = copyDepartment(m)
+	return m, nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) GetDepartment(id int64) (*models.Department, error) {
+	employeeMemoryDao.mu.RLock()
+	defer employeeMemoryDao.mu.RUnlock()
+
+	department, ok := employeeMemoryDao.findDepartment(id)
+	if !ok {
+		return nil, sqls.ErrNotExists
+	}
+	return copyDepartment(department), nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) GetDepartments(page int, limit int) ([]*models.Department, int64, error) {
+	employeeMemoryDao.mu.RLock()
+	defer employeeMemoryDao.mu.RUnlock()
+
+	departments := make([]*models.Department, 0, len(employeeMemoryDao.departments))
+	for _, department := range employeeMemoryDao.departments {
+		departments = append(departments, copyDepartment(department))
+	}
+	sort.Slice(departments, func(i, j int) bool {
+		if departments[i].Name != departments[j].Name {
+			return departments[i].Name < departments[j].Name
+		}
+		return departments[i].ID < departments[j].ID
+	})
+	total := int64(len(departments))
+	offset := min(max((page-1)*limit, 0), len(departments))
+	return departments[offset:min(offset+limit, len(departments))], total, nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) UpdateDepartment(id int64, m *models.Department) (*models.Department, error) {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	department, ok := employeeMemoryDao.findDepartment(id)
+	if !ok {
+		return nil, sqls.ErrNotExists
+	}
+	if err := employeeMemoryDao.checkDepartmentName(id, m.Name); err != nil {
+		return nil, err
+	}
+	m.ID = department.ID
+	m.CreatedAt = department.CreatedAt
+	m.UpdatedAt = time.Now()
+	employeeMemoryDao.departments[m.ID] = copyDepartment(m)
+	return m, nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) DeleteDepartment(id int64, reassignTo int64, audit models.AuditContext) error {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	if _, ok := employeeMemoryDao.findDepartment(id); !ok {
+		return sqls.ErrNotExists
+	}
+	var members []*models.Employee
+	live := 0
+	for _, employee := range employeeMemoryDao.all() {
+		if employee.DepartmentID != nil && int64(*employee.DepartmentID) == id {
+			members = append(members, employee)
+			if !employee.DeletedAt.Valid {
+				live++
+			}
+		}
+	}
+
+	var departmentID *uint
+	if reassignTo != 0 {
+		if reassignTo == id {
+			return models.NewDepartmentFieldValidationError("reassign_to", models.FieldErrorInvalid, "reassign_to must be another department")
+		}
+		if _, ok := employeeMemoryDao.findDepartment(reassignTo); !ok {
+			return models.NewDepartmentFieldValidationError("reassign_to", models.FieldErrorUnknownDepartment, fmt.Sprintf("department %d does not exist", reassignTo))
+		}
+		target := uint(reassignTo)
+		departmentID = &target
+	} else if live > 0 {
+		return fmt.Errorf("%w: %d employees belong to department %d", models.ErrDepartmentNotEmpty, live, id)
+	}
+	now := time.Now()
+	for _, employee := range members {
+		before := copyEmployee(employee)
+		employee.DepartmentID = copyID(departmentID)
+		employee.UpdatedAt = now
+		employee.Version++
+		employeeMemoryDao.recordAudit(audit, models.AuditUpdate, before, employee, now)
+	}
+	delete(employeeMemoryDao.departments, uint(id))
+	return nil
+}
+
+// checkDepartment fails with a validation error when departmentID names a department that does not exist, the caller holds the lock
+func (employeeMemoryDao *EmployeeMemoryDao) checkDepartment(departmentID *uint) error {
+	if departmentID == nil {
+		return nil
+	}
+	if _, ok := employeeMemoryDao.findDepartment(int64(*departmentID)); !ok {
+		return unknownDepartmentError(*departmentID)
+	}
+	return nil
+}
+
+// checkDepartmentName fails with sqls.ErrDuplicate when a department other than id is called name, the caller holds the lock
+func (employeeMemoryDao *EmployeeMemoryDao) checkDepartmentName(id int64, name string) error {
+	for _, department := range employeeMemoryDao.departments {
+		if department.Name == name && int64(department.ID) != id {
+			return fmt.Errorf("%w: a department is called %q already", sqls.ErrDuplicate, name)
+		}
+	}
+	return nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) findDepartment(id int64) (*models.Department, bool) {
+	if id <= 0 {
+		return nil, false
+	}
+	department, ok := employeeMemoryDao.departments[uint(id)]
+	return department, ok
+}
+
+func copyDepartment(m *models.Department) *models.Department {
+	department := *m
+	return &department
+}
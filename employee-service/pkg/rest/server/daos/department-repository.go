@@ -0,0 +1,23 @@
+package daos
+
+import "github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+
+// DepartmentRepository is the storage the department service works against.
+// DepartmentDao implements it on top of gorm, EmployeeMemoryDao keeps departments next to its employees.
+type DepartmentRepository interface {
+	// CreateDepartment and UpdateDepartment fail with sqls.ErrDuplicate when another department has the same name
+	CreateDepartment(m *models.Department) (*models.Department, error)
+	GetDepartment(id int64) (*models.Department, error)
+	// GetDepartments returns a page of the departments ordered by name, and how many there are
+	GetDepartments(page int, limit int) ([]*models.Department, int64, error)
+	UpdateDepartment(id int64, m *models.Department) (*models.Department, error)
+	// DeleteDepartment fails with models.ErrDepartmentNotEmpty while live employees belong to the department,
+	// unless reassignTo names the department every employee of it is moved to. Deleted employees are detached otherwise.
+	// The changes of the employees are attributed to audit.
+	DeleteDepartment(id int64, reassignTo int64, audit models.AuditContext) error
+}
+
+var (
+	_ DepartmentRepository = (*DepartmentDao)(nil)
+	_ DepartmentRepository = (*EmployeeMemoryDao)(nil)
+)
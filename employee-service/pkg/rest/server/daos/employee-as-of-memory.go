@@ -0,0 +1,61 @@
+package daos
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"slices"
+	"time"
+)
+
+func (employeeMemoryDao *EmployeeMemoryDao) GetEmployeeAsOf(id int64, asOf time.Time) (*models.Employee, error) {
+	employeeMemoryDao.applyDueSalaryChanges()
+	employeeMemoryDao.mu.RLock()
+	defer employeeMemoryDao.mu.RUnlock()
+
+	employee, ok := employeeMemoryDao.employees[uint(id)]
+	if !ok || id <= 0 {
+		return nil, sqls.ErrNotExists
+	}
+	since, last := employeeMemoryDao.auditEntriesAround(uint(id), asOf)
+	m, ok, err := models.EmployeeAsOf(copyEmployee(employee), since, firstAuditEntry(last), asOf)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, sqls.ErrNotExists
+	}
+	return m, nil
+}
+
+// getEmployeesAsOf rewinds every employee, the caller holds the lock
+func (employeeMemoryDao *EmployeeMemoryDao) getEmployeesAsOf(query *models.EmployeeQuery) (*models.EmployeePage, error) {
+	since, last := employeeMemoryDao.auditEntriesAround(0, *query.AsOf)
+	employees, err := employeesAsOf(copyEmployees(employeeMemoryDao.all()), since, last, *query.AsOf, &query.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return offsetEmployeePage(employees, query), nil
+}
+
+// auditEntriesAround splits the audit trail of employee id, or of every employee for 0, into the entries made after
+// asOf and the last one by then bumping the version of each employee, the caller holds the lock
+func (employeeMemoryDao *EmployeeMemoryDao) auditEntriesAround(id uint, asOf time.Time) ([]*models.AuditEntry, []*models.AuditEntry) {
+	var since, last []*models.AuditEntry
+	lastByEmployee := map[uint]int{}
+	for _, entry := range employeeMemoryDao.auditEntries {
+		if id != 0 && entry.EmployeeID != id {
+			continue
+		}
+		if entry.CreatedAt.After(asOf) {
+			since = append(since, entry)
+		} else if slices.Contains(versionAuditOperations, entry.Operation) {
+			if i, ok := lastByEmployee[entry.EmployeeID]; ok {
+				last[i] = entry
+			} else {
+				lastByEmployee[entry.EmployeeID] = len(last)
+				last = append(last, entry)
+			}
+		}
+	}
+	return since, last
+}
@@ -0,0 +1,134 @@
+package daos
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"time"
+)
+
+// versionAuditOperations are the audit operations that bump the version of an employee
+var versionAuditOperations = []string{models.AuditCreate, models.AuditUpdate, models.AuditRestore}
+
+func (employeeDao *EmployeeDao) GetEmployeeAsOf(id int64, asOf time.Time) (*models.Employee, error) {
+	if err := employeeDao.applyDueSalaryChanges(); err != nil {
+		log.Debugf("failed to get employee: %v", err)
+		return nil, err
+	}
+	employee, err := findEmployeeUnscoped(employeeDao.db, id)
+	if err != nil {
+		log.Debugf("failed to get employee: %v", err)
+		return nil, err
+	}
+	var since []*models.AuditEntry
+	if err := employeeDao.db.Where("employee_id = ? AND created_at > ?", id, asOf).Find(&since).Error; err != nil {
+		log.Debugf("failed to get employee: %v", err)
+		return nil, err
+	}
+	var last []*models.AuditEntry
+	if err := employeeDao.db.Where("employee_id = ? AND created_at <= ? AND operation IN ?", id, asOf, versionAuditOperations).
+		Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+		log.Debugf("failed to get employee: %v", err)
+		return nil, err
+	}
+	m, ok, err := models.EmployeeAsOf(employee, since, firstAuditEntry(last), asOf)
+	if err != nil {
+		log.Debugf("failed to get employee: %v", err)
+		return nil, err
+	}
+	if !ok {
+		return nil, sqls.ErrNotExists
+	}
+	log.Debugf("employee retrieved")
+	return m, nil
+}
+
+// getEmployeesAsOf lists the employees as they were at query.AsOf. Employees without audit entries since are as they
+// were, so SQL filters, sorts and pages those; only the employees changed since are rewound, and merged in.
+func (employeeDao *EmployeeDao) getEmployeesAsOf(query *models.EmployeeQuery) (*models.EmployeePage, error) {
+	asOf := *query.AsOf
+	sort := models.EffectiveEmployeeSort(query.Sort)
+	changedIDs := employeeDao.db.Model(&models.AuditEntry{}).Distinct("employee_id").Where("created_at > ?", asOf)
+	unchanged := func() *gorm.DB {
+		return applyEmployeeFilter(employeeDao.db.Model(&models.Employee{}), &query.Filter).
+			Where("created_at <= ? AND id NOT IN (?)", asOf, changedIDs)
+	}
+	var total int64
+	if err := unchanged().Count(&total).Error; err != nil {
+		return nil, err
+	}
+	// every unchanged employee up to the end of the page, the changed ones may come before any of them
+	db := applyEmployeeSort(unchanged(), sort)
+	if query.Limit >= 0 {
+		db = db.Limit(query.Page * query.Limit)
+	}
+	var employees []*models.Employee
+	if err := db.Find(&employees).Error; err != nil {
+		return nil, err
+	}
+
+	var changed []*models.Employee
+	if err := employeeDao.db.Unscoped().Where("created_at <= ? AND id IN (?)", asOf, changedIDs).Find(&changed).Error; err != nil {
+		return nil, err
+	}
+	var since, last []*models.AuditEntry
+	if err := employeeDao.db.Where("created_at > ?", asOf).Find(&since).Error; err != nil {
+		return nil, err
+	}
+	lastIDs := employeeDao.db.Model(&models.AuditEntry{}).Select("MAX(id)").
+		Where("created_at <= ? AND operation IN ? AND employee_id IN (?)", asOf, versionAuditOperations, changedIDs).Group("employee_id")
+	if err := employeeDao.db.Where("id IN (?)", lastIDs).Find(&last).Error; err != nil {
+		return nil, err
+	}
+	rewound, err := employeesAsOf(changed, since, last, asOf, &query.Filter)
+	if err != nil {
+		return nil, err
+	}
+	sortEmployees(rewound, sort)
+	return employeePageAt(mergeEmployees(employees, rewound, sort), total+int64(len(rewound)), query), nil
+}
+
+// employeesAsOf rewinds employees with the audit entries made since asOf and the last ones before that bumped
+// their version, and returns those matching filter at the time
+func employeesAsOf(employees []*models.Employee, since []*models.AuditEntry, last []*models.AuditEntry, asOf time.Time, filter *models.EmployeeFilter) ([]*models.Employee, error) {
+	sinceByEmployee := map[uint][]*models.AuditEntry{}
+	for _, entry := range since {
+		sinceByEmployee[entry.EmployeeID] = append(sinceByEmployee[entry.EmployeeID], entry)
+	}
+	lastByEmployee := map[uint]*models.AuditEntry{}
+	for _, entry := range last {
+		lastByEmployee[entry.EmployeeID] = entry
+	}
+	var matching []*models.Employee
+	for _, employee := range employees {
+		m, ok, err := models.EmployeeAsOf(employee, sinceByEmployee[employee.ID], lastByEmployee[employee.ID], asOf)
+		if err != nil {
+			return nil, err
+		}
+		if ok && matchesEmployeeFilter(m, filter) {
+			matching = append(matching, m)
+		}
+	}
+	return matching, nil
+}
+
+// mergeEmployees merges two listings ordered by sort, keeping the order of a among its own employees
+func mergeEmployees(a, b []*models.Employee, sort []models.EmployeeSort) []*models.Employee {
+	merged := make([]*models.Employee, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if compareEmployeeSort(b[0], a[0], sort) < 0 {
+			merged, b = append(merged, b[0]), b[1:]
+		} else {
+			merged, a = append(merged, a[0]), a[1:]
+		}
+	}
+	return append(append(merged, a...), b...)
+}
+
+func firstAuditEntry(entries []*models.AuditEntry) *models.AuditEntry {
+	if len(entries) == 0 {
+		return nil
+	}
+	return entries[0]
+}
@@ -0,0 +1,69 @@
+package daos
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"slices"
+	"time"
+)
+
+func (employeeMemoryDao *EmployeeMemoryDao) GetAuditEntries(query *models.AuditQuery) (*models.AuditPage, error) {
+	employeeMemoryDao.mu.RLock()
+	defer employeeMemoryDao.mu.RUnlock()
+
+	var entries []*models.AuditEntry
+	for _, entry := range employeeMemoryDao.auditEntries {
+		if matchesAuditQuery(entry, query) {
+			entries = append(entries, entry)
+		}
+	}
+	page := &models.AuditPage{Total: int64(len(entries))}
+	offset := (query.Page - 1) * query.Limit
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(entries) {
+		offset = len(entries)
+	}
+	end := len(entries)
+	if query.Limit >= 0 && offset+query.Limit < end {
+		end = offset + query.Limit
+	}
+	for _, entry := range entries[offset:end] {
+		copied := *entry
+		page.Entries = append(page.Entries, &copied)
+	}
+	return page, nil
+}
+
+// matchesAuditQuery evaluates query the same way applyAuditQuery does in SQL
+func matchesAuditQuery(entry *models.AuditEntry, query *models.AuditQuery) bool {
+	if len(query.EmployeeIDs) > 0 && !slices.Contains(query.EmployeeIDs, entry.EmployeeID) {
+		return false
+	}
+	if len(query.Actor) > 0 && entry.Actor != query.Actor {
+		return false
+	}
+	if len(query.Operations) > 0 && !slices.Contains(query.Operations, entry.Operation) {
+		return false
+	}
+	if len(query.RequestID) > 0 && entry.RequestID != query.RequestID {
+		return false
+	}
+	if query.From != nil && entry.CreatedAt.Before(*query.From) {
+		return false
+	}
+	if query.To != nil && entry.CreatedAt.After(*query.To) {
+		return false
+	}
+	return true
+}
+
+// recordAudit adds operation on an employee that was before and became after to the audit trail, assigning an ID
+// and a creation time the same way gorm does. The caller holds the lock.
+func (employeeMemoryDao *EmployeeMemoryDao) recordAudit(audit models.AuditContext, operation string, before, after *models.Employee, now time.Time) {
+	entry := models.NewAuditEntry(audit, operation, before, after)
+	employeeMemoryDao.lastAuditEntryID++
+	entry.ID = employeeMemoryDao.lastAuditEntryID
+	entry.CreatedAt = now
+	employeeMemoryDao.auditEntries = append(employeeMemoryDao.auditEntries, entry)
+}
@@ -0,0 +1,73 @@
+package daos
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func (employeeDao *EmployeeDao) GetAuditEntries(query *models.AuditQuery) (*models.AuditPage, error) {
+	page := &models.AuditPage{}
+	if err := applyAuditQuery(employeeDao.db.Model(&models.AuditEntry{}), query).Count(&page.Total).Error; err != nil {
+		log.Debugf("failed to count audit entries: %v", err)
+		return nil, err
+	}
+	db := applyAuditQuery(employeeDao.db, query).Order("id").Offset((query.Page - 1) * query.Limit).Limit(query.Limit)
+	if err := db.Find(&page.Entries).Error; err != nil {
+		log.Debugf("failed to get audit entries: %v", err)
+		return nil, err
+	}
+	log.Debugf("audit entries retrieved")
+	return page, nil
+}
+
+// applyAuditQuery narrows db down to the audit entries matching query
+func applyAuditQuery(db *gorm.DB, query *models.AuditQuery) *gorm.DB {
+	if len(query.EmployeeIDs) > 0 {
+		db = db.Where("employee_id IN ?", query.EmployeeIDs)
+	}
+	if len(query.Actor) > 0 {
+		db = db.Where("actor = ?", query.Actor)
+	}
+	if len(query.Operations) > 0 {
+		db = db.Where("operation IN ?", query.Operations)
+	}
+	if len(query.RequestID) > 0 {
+		db = db.Where("request_id = ?", query.RequestID)
+	}
+	if query.From != nil {
+		db = db.Where("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("created_at <= ?", *query.To)
+	}
+	return db
+}
+
+// recordAudit adds operation on an employee that was before and became after to the audit trail
+func recordAudit(tx *gorm.DB, audit models.AuditContext, operation string, before, after *models.Employee) error {
+	return tx.Create(models.NewAuditEntry(audit, operation, before, after)).Error
+}
+
+// auditUpdates runs update, which changes the employees ids in bulk, and records an update of each of them.
+// Soft deleted employees are audited as well.
+func auditUpdates(tx *gorm.DB, audit models.AuditContext, ids []uint, update func() error) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var before, after []*models.Employee
+	if err := tx.Unscoped().Where("id IN ?", ids).Order("id").Find(&before).Error; err != nil {
+		return err
+	}
+	if err := update(); err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("id IN ?", ids).Order("id").Find(&after).Error; err != nil {
+		return err
+	}
+	entries := make([]*models.AuditEntry, 0, len(after))
+	for i := range after {
+		entries = append(entries, models.NewAuditEntry(audit, models.AuditUpdate, before[i], after[i]))
+	}
+	return tx.Create(&entries).Error
+}
@@ -0,0 +1,103 @@
+package daos
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// employeeWriter is what a batch is made of, bound to a transaction or to a locked in-memory store
+type employeeWriter interface {
+	create(m *models.Employee) error
+	update(id int64, m *models.Employee) error
+	remove(id int64, version uint) error
+}
+
+// BatchEmployees applies operations in order. An atomic batch runs in a single transaction and stops at
+// the first failing operation, otherwise every operation is committed on its own.
+func (employeeDao *EmployeeDao) BatchEmployees(operations []*models.EmployeeBatchOperation, atomic bool) ([]*models.EmployeeBatchResult, error) {
+	results := models.NewEmployeeBatchResults(operations)
+	if !atomic {
+		for i, operation := range operations {
+			if err := employeeDao.db.Transaction(func(tx *gorm.DB) error {
+				return applyBatchOperation(&txEmployeeWriter{employeeDao: employeeDao, tx: tx}, operation, results[i])
+			}); err != nil && results[i].Status == models.EmployeeBatchApplied {
+				// the commit failed
+				results[i].Status, results[i].Err, results[i].Employee = models.EmployeeBatchFailed, err, nil
+			}
+		}
+		log.Debugf("employee batch applied")
+		return results, nil
+	}
+
+	if err := employeeDao.db.Transaction(func(tx *gorm.DB) error {
+		writer := &txEmployeeWriter{employeeDao: employeeDao, tx: tx}
+		for i, operation := range operations {
+			if err := applyBatchOperation(writer, operation, results[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		models.RollBackEmployeeBatch(results)
+		if !failedBatch(results) {
+			log.Debugf("failed to commit employee batch: %v", err)
+			return nil, err
+		}
+		log.Debugf("employee batch rolled back: %v", err)
+		return results, nil
+	}
+	log.Debugf("employee batch applied")
+	return results, nil
+}
+
+// txEmployeeWriter writes employees within a transaction
+type txEmployeeWriter struct {
+	employeeDao *EmployeeDao
+	tx          *gorm.DB
+}
+
+func (writer *txEmployeeWriter) create(m *models.Employee) error {
+	return writer.employeeDao.createEmployee(writer.tx, m)
+}
+
+func (writer *txEmployeeWriter) update(id int64, m *models.Employee) error {
+	return writer.employeeDao.updateEmployee(writer.tx, id, m)
+}
+
+func (writer *txEmployeeWriter) remove(id int64, version uint) error {
+	return writer.employeeDao.deleteEmployee(writer.tx, id, version)
+}
+
+// applyBatchOperation validates operation and runs it, recording the outcome in result
+func applyBatchOperation(writer employeeWriter, operation *models.EmployeeBatchOperation, result *models.EmployeeBatchResult) error {
+	err := operation.Validate()
+	if err == nil {
+		switch operation.Op {
+		case models.EmployeeBatchCreate:
+			err = writer.create(operation.Employee)
+		case models.EmployeeBatchUpdate:
+			err = writer.update(int64(operation.ID), operation.Employee)
+		case models.EmployeeBatchDelete:
+			err = writer.remove(int64(operation.ID), operation.Version)
+		}
+	}
+	if err != nil {
+		result.Status, result.Err = models.EmployeeBatchFailed, err
+		return err
+	}
+	result.Status = models.EmployeeBatchApplied
+	if operation.Op != models.EmployeeBatchDelete {
+		result.Employee = operation.Employee
+	}
+	return nil
+}
+
+func failedBatch(results []*models.EmployeeBatchResult) bool {
+	for _, result := range results {
+		if result.Status == models.EmployeeBatchFailed {
+			return true
+		}
+	}
+	return false
+}
@@ -2,32 +2,63 @@ package daos
 
 import (
 	"errors"
+	"fmt"
 	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
 	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
 	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"sync"
+	"time"
 )
 
 type EmployeeDao struct {
-	db *gorm.DB
+	db             *gorm.DB
+	fullTextSearch bool
+	readOnly       bool
+	salaries       *appliedSalaries
+	// audit is who the changes made through the dao are attributed to
+	audit models.AuditContext
 }
 
-func NewEmployeeDao() (*EmployeeDao, error) {
-	sqlClient, err := sqls.InitGORMSQLiteDB()
-	if err != nil {
-		return nil, err
+// duplicateError turns the unique violations gorm translates into sqls.ErrDuplicate, like the memory daos report them
+func duplicateError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return sqls.ErrDuplicate
 	}
-	err = sqlClient.DB.AutoMigrate(models.Employee{})
-	if err != nil {
+	return err
+}
+
+// appliedSalaries holds the day the salaries effective by then were last applied, shared by the copies of a dao
+type appliedSalaries struct {
+	mu sync.Mutex
+	on models.Date
+}
+
+// NewEmployeeDao expects the schema to be migrated already, see the migrations package
+func NewEmployeeDao(sqlClient *sqls.SQLClient) (*EmployeeDao, error) {
+	employeeDao := &EmployeeDao{
+		db:             sqlClient.DB,
+		fullTextSearch: sqlClient.FullTextSearch,
+		readOnly:       sqlClient.ReadOnly(),
+		salaries:       &appliedSalaries{},
+	}
+	if err := employeeDao.prepareSearchIndex(sqlClient.ReadOnly()); err != nil {
 		return nil, err
 	}
-	return &EmployeeDao{
-		db: sqlClient.DB,
-	}, nil
+	return employeeDao, nil
+}
+
+// WithAudit returns a copy of the dao attributing the changes made through it to audit
+func (employeeDao *EmployeeDao) WithAudit(audit models.AuditContext) EmployeeRepository {
+	scoped := *employeeDao
+	scoped.audit = audit
+	return &scoped
 }
 
 func (employeeDao *EmployeeDao) CreateEmployee(m *models.Employee) (*models.Employee, error) {
-	if err := employeeDao.db.Create(&m).Error; err != nil {
+	if err := employeeDao.db.Transaction(func(tx *gorm.DB) error {
+		return employeeDao.createEmployee(tx, m)
+	}); err != nil {
 		log.Debugf("failed to create employee: %v", err)
 		return nil, err
 	}
@@ -37,6 +68,10 @@ func (employeeDao *EmployeeDao) CreateEmployee(m *models.Employee) (*models.Empl
 }
 
 func (employeeDao *EmployeeDao) GetEmployee(id int64) (*models.Employee, error) {
+	if err := employeeDao.applyDueSalaryChanges(); err != nil {
+		log.Debugf("failed to get employee: %v", err)
+		return nil, err
+	}
 	var m *models.Employee
 	if err := employeeDao.db.Where("id = ?", id).First(&m).Error; err != nil {
 		log.Debugf("failed to get employee: %v", err)
@@ -49,14 +84,90 @@ func (employeeDao *EmployeeDao) GetEmployee(id int64) (*models.Employee, error)
 	return m, nil
 }
 
-func (employeeDao *EmployeeDao) GetEmployees(page int, limit int) ([]*models.Employee, error) {
-	var m []*models.Employee
-	if err := employeeDao.db.Offset((page - 1) * limit).Limit(limit).Find(&m).Error; err != nil {
+func (employeeDao *EmployeeDao) GetEmployees(query *models.EmployeeQuery) (*models.EmployeePage, error) {
+	if err := employeeDao.applyDueSalaryChanges(); err != nil {
 		log.Debugf("failed to get employees: %v", err)
 		return nil, err
 	}
+	if query.AsOf != nil {
+		page, err := employeeDao.getEmployeesAsOf(query)
+		if err != nil {
+			log.Debugf("failed to get employees: %v", err)
+			return nil, err
+		}
+		log.Debugf("employees retrieved")
+		return page, nil
+	}
+	page := &models.EmployeePage{}
+	if err := applyEmployeeFilter(employeeDao.db.Model(&models.Employee{}), &query.Filter).Count(&page.Total).Error; err != nil {
+		log.Debugf("failed to count employees: %v", err)
+		return nil, err
+	}
+
+	sort := models.EffectiveEmployeeSort(query.Sort)
+	db := applyEmployeeFilter(employeeDao.db, &query.Filter)
+	if query.Cursor == nil {
+		db = applyEmployeeSort(db, sort).Offset((query.Page - 1) * query.Limit).Limit(query.Limit)
+		if err := db.Find(&page.Employees).Error; err != nil {
+			log.Debugf("failed to get employees: %v", err)
+			return nil, err
+		}
+		page.HasPrev = query.Page > 1
+		page.HasNext = int64(query.Page*query.Limit) < page.Total
+		log.Debugf("employees retrieved")
+		return page, nil
+	}
+
+	// keyset pagination, walking backwards means reversing the order and the page afterwards
+	if query.Cursor.Backward {
+		sort = models.ReverseEmployeeSort(sort)
+	}
+	if !query.Cursor.IsStart() {
+		position, err := query.Cursor.Employee(query.Sort)
+		if err != nil {
+			return nil, err
+		}
+		db = applyEmployeeKeyset(db, sort, position)
+	}
+	if err := applyEmployeeSort(db, sort).Limit(query.Limit + 1).Find(&page.Employees).Error; err != nil {
+		log.Debugf("failed to get employees: %v", err)
+		return nil, err
+	}
+	setKeysetPage(page, query)
 	log.Debugf("employees retrieved")
-	return m, nil
+	return page, nil
+}
+
+// ExportEmployees reads the employees through a database cursor, so only one row is held in memory at a time
+func (employeeDao *EmployeeDao) ExportEmployees(filter *models.EmployeeFilter, sort []models.EmployeeSort, visit func(*models.Employee) error) error {
+	if err := employeeDao.applyDueSalaryChanges(); err != nil {
+		log.Debugf("failed to export employees: %v", err)
+		return err
+	}
+	db := applyEmployeeFilter(employeeDao.db.Model(&models.Employee{}), filter)
+	rows, err := applyEmployeeSort(db, models.EffectiveEmployeeSort(sort)).Rows()
+	if err != nil {
+		log.Debugf("failed to export employees: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m models.Employee
+		if err := employeeDao.db.ScanRows(rows, &m); err != nil {
+			log.Debugf("failed to export employees: %v", err)
+			return err
+		}
+		if err := visit(&m); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Debugf("failed to export employees: %v", err)
+		return err
+	}
+	log.Debugf("employees exported")
+	return nil
 }
 
 func (employeeDao *EmployeeDao) UpdateEmployee(id int64, m *models.Employee) (*models.Employee, error) {
@@ -67,16 +178,9 @@ func (employeeDao *EmployeeDao) UpdateEmployee(id int64, m *models.Employee) (*m
 		return nil, errors.New("id and payload don't match")
 	}
 
-	var employee *models.Employee
-	if err := employeeDao.db.Where("id = ?", id).First(&employee).Error; err != nil {
-		log.Debugf("failed to find employee for update: %v", err)
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, sqls.ErrNotExists
-		}
-		return nil, err
-	}
-
-	if err := employeeDao.db.Save(&m).Error; err != nil {
+	if err := employeeDao.db.Transaction(func(tx *gorm.DB) error {
+		return employeeDao.updateEmployee(tx, id, m)
+	}); err != nil {
 		log.Debugf("failed to update employee: %v", err)
 		return nil, err
 	}
@@ -84,9 +188,51 @@ func (employeeDao *EmployeeDao) UpdateEmployee(id int64, m *models.Employee) (*m
 	return m, nil
 }
 
-func (employeeDao *EmployeeDao) DeleteEmployee(id int64) error {
+func (employeeDao *EmployeeDao) PatchEmployee(id int64, version uint, changes map[string]interface{}) (*models.Employee, error) {
 	var m *models.Employee
-	if err := employeeDao.db.Where("id = ?", id).Delete(&m).Error; err != nil {
+	if err := employeeDao.db.Transaction(func(tx *gorm.DB) error {
+		if departmentID, ok := changes["department_id"].(*uint); ok {
+			if err := checkEmployeeDepartment(tx, departmentID); err != nil {
+				return err
+			}
+		}
+		if managerID, ok := changes["manager_id"].(*uint); ok {
+			if err := checkEmployeeManager(tx, id, managerID); err != nil {
+				return err
+			}
+		}
+		before, err := findEmployee(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := compareAndUpdate(tx, id, version, changes); err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", id).First(&m).Error; err != nil {
+			return err
+		}
+		_, salary := changes["salary"]
+		if _, currency := changes["currency"]; salary || currency {
+			if err := recordSalaries(tx, models.SalaryReasonUpdate, m); err != nil {
+				return err
+			}
+		}
+		if err := recordAudit(tx, employeeDao.audit, models.AuditUpdate, before, m); err != nil {
+			return err
+		}
+		return employeeDao.indexEmployees(tx, m)
+	}); err != nil {
+		log.Debugf("failed to patch employee: %v", err)
+		return nil, err
+	}
+	log.Debugf("employee patched")
+	return m, nil
+}
+
+func (employeeDao *EmployeeDao) DeleteEmployee(id int64, version uint) error {
+	if err := employeeDao.db.Transaction(func(tx *gorm.DB) error {
+		return employeeDao.deleteEmployee(tx, id, version)
+	}); err != nil {
 		log.Debugf("failed to delete employee: %v", err)
 		return err
 	}
@@ -95,11 +241,305 @@ func (employeeDao *EmployeeDao) DeleteEmployee(id int64) error {
 	return nil
 }
 
+func (employeeDao *EmployeeDao) RestoreEmployee(id int64, version uint) (*models.Employee, error) {
+	var m *models.Employee
+	if err := employeeDao.db.Transaction(func(tx *gorm.DB) error {
+		employee, err := findEmployeeUnscoped(tx, id)
+		if err != nil {
+			return err
+		}
+		if !employee.DeletedAt.Valid {
+			return sqls.ErrNotDeleted
+		}
+		if version != 0 && version != employee.Version {
+			return sqls.ErrVersionMismatch
+		}
+		result := tx.Unscoped().Model(&models.Employee{}).Where("id = ? AND version = ?", id, employee.Version).
+			Updates(map[string]interface{}{"deleted_at": nil, "version": employee.Version + 1})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return sqls.ErrVersionMismatch
+		}
+		if err := tx.Where("id = ?", id).First(&m).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(tx, employeeDao.audit, models.AuditRestore, employee, m); err != nil {
+			return err
+		}
+		return employeeDao.indexEmployees(tx, m)
+	}); err != nil {
+		log.Debugf("failed to restore employee: %v", err)
+		return nil, err
+	}
+	log.Debugf("employee restored")
+	return m, nil
+}
+
+func (employeeDao *EmployeeDao) PurgeEmployee(id int64) error {
+	if err := employeeDao.db.Transaction(func(tx *gorm.DB) error {
+		employee, err := findEmployeeUnscoped(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := detachReports(tx, employeeDao.audit, id); err != nil {
+			return err
+		}
+		if err := tx.Where("employee_id = ?", id).Delete(&models.SalaryChange{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id = ?", id).Delete(&models.Employee{})
+		if result.Error != nil {
+			return fmt.Errorf("%w: %v", sqls.ErrDeleteFailed, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return sqls.ErrNotExists
+		}
+		if err := recordAudit(tx, employeeDao.audit, models.AuditPurge, employee, nil); err != nil {
+			return err
+		}
+		return employeeDao.unindexEmployee(tx, id)
+	}); err != nil {
+		log.Debugf("failed to purge employee: %v", err)
+		return err
+	}
+	log.Debugf("employee purged")
+	return nil
+}
+
 func (employeeDao *EmployeeDao) CreateEmployees(employees []*models.Employee) error {
-	if err := employeeDao.db.Create(&employees).Error; err != nil {
+	for _, m := range employees {
+		initEmployee(m)
+	}
+	if err := employeeDao.db.Transaction(func(tx *gorm.DB) error {
+		for _, m := range employees {
+			if err := checkEmployeeDepartment(tx, m.DepartmentID); err != nil {
+				return err
+			}
+			if err := checkEmployeeManager(tx, 0, m.ManagerID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&employees).Error; err != nil {
+			return duplicateError(err)
+		}
+		if err := recordSalaries(tx, models.SalaryReasonInitial, employees...); err != nil {
+			return err
+		}
+		for _, m := range employees {
+			if err := recordAudit(tx, employeeDao.audit, models.AuditCreate, nil, m); err != nil {
+				return err
+			}
+		}
+		return employeeDao.indexEmployees(tx, employees...)
+	}); err != nil {
 		log.Debugf("failed to create employees: %v", err)
 		return err
 	}
 	log.Debugf("employees created")
 	return nil
-}
\ No newline at end of file
+}
+
+func (employeeDao *EmployeeDao) CheckEmployeeReferences(employees []*models.Employee) ([][]*models.FieldError, error) {
+	fieldErrors, err := referenceErrors(employees, func(departmentID *uint) error {
+		return checkEmployeeDepartment(employeeDao.db, departmentID)
+	}, func(managerID *uint) error {
+		return checkEmployeeManager(employeeDao.db, 0, managerID)
+	})
+	if err != nil {
+		log.Debugf("failed to check employee references: %v", err)
+		return nil, err
+	}
+	return fieldErrors, nil
+}
+
+// referenceErrors collects the validation errors checkDepartment and checkManager report for employees,
+// checking every id once
+func referenceErrors(employees []*models.Employee, checkDepartment, checkManager func(*uint) error) ([][]*models.FieldError, error) {
+	departments, managers := map[uint]error{}, map[uint]error{}
+	check := func(checked map[uint]error, id *uint, check func(*uint) error) error {
+		if id == nil {
+			return nil
+		}
+		if err, ok := checked[*id]; ok {
+			return err
+		}
+		err := check(id)
+		checked[*id] = err
+		return err
+	}
+
+	fieldErrors := make([][]*models.FieldError, len(employees))
+	for i, m := range employees {
+		for _, err := range []error{check(departments, m.DepartmentID, checkDepartment), check(managers, m.ManagerID, checkManager)} {
+			var validationError *models.ValidationError
+			if errors.As(err, &validationError) {
+				fieldErrors[i] = append(fieldErrors[i], validationError.Errors...)
+			} else if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return fieldErrors, nil
+}
+
+// initEmployee sets the fields of a new employee the server owns. The timestamps are the ones of the insert
+// whatever the client sent, as_of listings trust created_at and a new employee cannot be deleted already.
+func initEmployee(m *models.Employee) {
+	m.Version = 1
+	m.CreatedAt, m.UpdatedAt, m.DeletedAt = time.Time{}, time.Time{}, gorm.DeletedAt{}
+	m.ResolveMaskedSalary(nil)
+	if len(m.Currency) == 0 {
+		m.Currency = models.DefaultCurrency
+	}
+}
+
+// createEmployee inserts m within the caller's transaction
+func (employeeDao *EmployeeDao) createEmployee(tx *gorm.DB, m *models.Employee) error {
+	initEmployee(m)
+	if err := checkEmployeeDepartment(tx, m.DepartmentID); err != nil {
+		return err
+	}
+	if err := checkEmployeeManager(tx, 0, m.ManagerID); err != nil {
+		return err
+	}
+	if err := tx.Create(&m).Error; err != nil {
+		return duplicateError(err)
+	}
+	if err := recordSalaries(tx, models.SalaryReasonInitial, m); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, employeeDao.audit, models.AuditCreate, nil, m); err != nil {
+		return err
+	}
+	return employeeDao.indexEmployees(tx, m)
+}
+
+// updateEmployee overwrites the fields of employee id with those of m within the caller's transaction
+func (employeeDao *EmployeeDao) updateEmployee(tx *gorm.DB, id int64, m *models.Employee) error {
+	var employee *models.Employee
+	if err := tx.Where("id = ?", id).First(&employee).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return sqls.ErrNotExists
+		}
+		return err
+	}
+	if m.Version != 0 && m.Version != employee.Version {
+		return sqls.ErrVersionMismatch
+	}
+	m.ResolveMaskedSalary(employee)
+	if len(m.Currency) == 0 {
+		m.Currency = employee.Currency
+	}
+	if err := checkEmployeeDepartment(tx, m.DepartmentID); err != nil {
+		return err
+	}
+	// a manager deleted since is kept as long as the update does not change it
+	if !models.SameID(m.ManagerID, employee.ManagerID) {
+		if err := checkEmployeeManager(tx, id, m.ManagerID); err != nil {
+			return err
+		}
+	}
+	// compare and swap on the version, a concurrent write in between fails the update
+	if err := compareAndUpdate(tx, id, employee.Version, map[string]interface{}{
+		"name":          m.Name,
+		"position":      m.Position,
+		"salary":        m.Salary,
+		"currency":      m.Currency,
+		"department_id": m.DepartmentID,
+		"manager_id":    m.ManagerID,
+	}); err != nil {
+		return err
+	}
+	if err := tx.Where("id = ?", id).First(m).Error; err != nil {
+		return err
+	}
+	if salaryChanged(m, employee) {
+		if err := recordSalaries(tx, models.SalaryReasonUpdate, m); err != nil {
+			return err
+		}
+	}
+	if err := recordAudit(tx, employeeDao.audit, models.AuditUpdate, employee, m); err != nil {
+		return err
+	}
+	return employeeDao.indexEmployees(tx, m)
+}
+
+// deleteEmployee soft deletes employee id within the caller's transaction
+func (employeeDao *EmployeeDao) deleteEmployee(tx *gorm.DB, id int64, version uint) error {
+	employee, err := findEmployeeUnscoped(tx, id)
+	if err != nil {
+		return err
+	}
+	if employee.DeletedAt.Valid {
+		return sqls.ErrDeleted
+	}
+	if version != 0 && version != employee.Version {
+		return sqls.ErrVersionMismatch
+	}
+	result := tx.Where("id = ? AND version = ?", id, employee.Version).Delete(&models.Employee{})
+	if result.Error != nil {
+		return fmt.Errorf("%w: %v", sqls.ErrDeleteFailed, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// deleted or changed since it was read
+		return sqls.ErrVersionMismatch
+	}
+	deleted, err := findEmployeeUnscoped(tx, id)
+	if err != nil {
+		return err
+	}
+	if err := recordAudit(tx, employeeDao.audit, models.AuditDelete, employee, deleted); err != nil {
+		return err
+	}
+	return employeeDao.unindexEmployee(tx, id)
+}
+
+// findEmployee finds an employee unless it was soft deleted
+func findEmployee(tx *gorm.DB, id int64) (*models.Employee, error) {
+	var employee *models.Employee
+	if err := tx.Where("id = ?", id).First(&employee).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sqls.ErrNotExists
+		}
+		return nil, err
+	}
+	return employee, nil
+}
+
+// findEmployeeUnscoped finds an employee whether or not it was soft deleted
+func findEmployeeUnscoped(tx *gorm.DB, id int64) (*models.Employee, error) {
+	var employee *models.Employee
+	if err := tx.Unscoped().Where("id = ?", id).First(&employee).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, sqls.ErrNotExists
+		}
+		return nil, err
+	}
+	return employee, nil
+}
+
+// compareAndUpdate writes changes and bumps the version of the employee unless its version differs from version
+func compareAndUpdate(tx *gorm.DB, id int64, version uint, changes map[string]interface{}) error {
+	values := make(map[string]interface{}, len(changes)+1)
+	for column, value := range changes {
+		values[column] = value
+	}
+	values["version"] = version + 1
+	result := tx.Model(&models.Employee{}).Where("id = ? AND version = ?", id, version).Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&models.Employee{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return sqls.ErrNotExists
+	}
+	return sqls.ErrVersionMismatch
+}
@@ -0,0 +1,119 @@
+package daos
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// managementChainQuery walks up from an employee to the top of the organization, nearest manager first.
+// A deleted manager ends the chain.
+const managementChainQuery = `WITH RECURSIVE chain (id, depth) AS (
+	SELECT manager_id, 1 FROM employees WHERE id = ? AND manager_id IS NOT NULL AND deleted_at IS NULL
+	UNION ALL
+	SELECT employees.manager_id, chain.depth + 1 FROM employees JOIN chain ON employees.id = chain.id
+	WHERE employees.manager_id IS NOT NULL AND employees.deleted_at IS NULL AND chain.depth < ?
+)
+SELECT employees.* FROM employees JOIN chain ON employees.id = chain.id
+WHERE employees.deleted_at IS NULL
+ORDER BY chain.depth`
+
+// reportingSubtreeQuery walks down from a manager to everyone reporting to them, level by level.
+// The reports of a deleted employee are left out along with them.
+const reportingSubtreeQuery = `WITH RECURSIVE subtree (id, depth) AS (
+	SELECT id, 1 FROM employees WHERE manager_id = ? AND deleted_at IS NULL
+	UNION ALL
+	SELECT employees.id, subtree.depth + 1 FROM employees JOIN subtree ON employees.manager_id = subtree.id
+	WHERE employees.deleted_at IS NULL AND subtree.depth < ?
+)
+SELECT employees.* FROM employees JOIN subtree ON employees.id = subtree.id
+ORDER BY subtree.depth, employees.id`
+
+func (employeeDao *EmployeeDao) GetManagementChain(id int64) ([]*models.Employee, error) {
+	if err := employeeDao.applyDueSalaryChanges(); err != nil {
+		log.Debugf("failed to get management chain: %v", err)
+		return nil, err
+	}
+	if _, err := findEmployee(employeeDao.db, id); err != nil {
+		log.Debugf("failed to get management chain: %v", err)
+		return nil, err
+	}
+	chain, err := managementChain(employeeDao.db, id)
+	if err != nil {
+		log.Debugf("failed to get management chain: %v", err)
+		return nil, err
+	}
+	log.Debugf("management chain retrieved")
+	return chain, nil
+}
+
+func (employeeDao *EmployeeDao) GetReportingSubtree(id int64) ([]*models.Employee, error) {
+	if err := employeeDao.applyDueSalaryChanges(); err != nil {
+		log.Debugf("failed to get reporting subtree: %v", err)
+		return nil, err
+	}
+	if _, err := findEmployee(employeeDao.db, id); err != nil {
+		log.Debugf("failed to get reporting subtree: %v", err)
+		return nil, err
+	}
+	var subtree []*models.Employee
+	if err := employeeDao.db.Raw(reportingSubtreeQuery, id, models.MaxManagementDepth).Scan(&subtree).Error; err != nil {
+		log.Debugf("failed to get reporting subtree: %v", err)
+		return nil, err
+	}
+	log.Debugf("reporting subtree retrieved")
+	return subtree, nil
+}
+
+func managementChain(tx *gorm.DB, id int64) ([]*models.Employee, error) {
+	var chain []*models.Employee
+	if err := tx.Raw(managementChainQuery, id, models.MaxManagementDepth).Scan(&chain).Error; err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// checkEmployeeManager fails with a validation error when employee id, 0 for a new one, cannot report to managerID
+// because the manager does not exist or reports to the employee already
+func checkEmployeeManager(tx *gorm.DB, id int64, managerID *uint) error {
+	if managerID == nil {
+		return nil
+	}
+	if _, err := findEmployee(tx, int64(*managerID)); err != nil {
+		if errors.Is(err, sqls.ErrNotExists) {
+			return unknownManagerError(*managerID)
+		}
+		return err
+	}
+	if id == 0 {
+		return nil
+	}
+	chain, err := managementChain(tx, int64(*managerID))
+	if err != nil {
+		return err
+	}
+	chainIDs := make([]uint, 0, len(chain))
+	for _, manager := range chain {
+		chainIDs = append(chainIDs, manager.ID)
+	}
+	return models.ValidateManager(id, *managerID, chainIDs)
+}
+
+// detachReports takes the employees reporting to employee id, deleted ones included, out of its hierarchy
+func detachReports(tx *gorm.DB, audit models.AuditContext, id int64) error {
+	var ids []uint
+	if err := tx.Unscoped().Model(&models.Employee{}).Where("manager_id = ?", id).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	return auditUpdates(tx, audit, ids, func() error {
+		return tx.Unscoped().Model(&models.Employee{}).Where("id IN ?", ids).
+			Updates(map[string]interface{}{"manager_id": nil, "version": gorm.Expr("version + 1")}).Error
+	})
+}
+
+func unknownManagerError(managerID uint) error {
+	return models.NewFieldValidationError("manager_id", models.FieldErrorUnknownManager, fmt.Sprintf("employee %d does not exist", managerID))
+}
@@ -0,0 +1,664 @@
+package daos
+
+import (
+	"errors"
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"gorm.io/gorm"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EmployeeMemoryDao is an EmployeeRepository that keeps employees in a map, for tests and demos.
+// It is the DepartmentRepository of its employees as well, so that the references between them hold.
+type EmployeeMemoryDao struct {
+	*employeeMemoryStore
+	// audit is who the changes made through the dao are attributed to
+	audit models.AuditContext
+}
+
+// employeeMemoryStore holds the data of an EmployeeMemoryDao, shared by its copies
+type employeeMemoryStore struct {
+	mu               sync.RWMutex
+	lastID           uint
+	employees        map[uint]*models.Employee
+	lastDepartmentID uint
+	departments      map[uint]*models.Department
+	// salaryChanges is the salary history of all employees in the order of creation
+	lastSalaryChangeID uint
+	salaryChanges      []*models.SalaryChange
+	salariesAppliedOn  models.Date
+	// auditEntries is the audit trail of all employees in the order of the changes
+	lastAuditEntryID uint
+	auditEntries     []*models.AuditEntry
+}
+
+func NewEmployeeMemoryDao() *EmployeeMemoryDao {
+	return &EmployeeMemoryDao{employeeMemoryStore: &employeeMemoryStore{
+		employees:   map[uint]*models.Employee{},
+		departments: map[uint]*models.Department{},
+	}}
+}
+
+// WithAudit returns a copy of the dao attributing the changes made through it to audit
+func (employeeMemoryDao *EmployeeMemoryDao) WithAudit(audit models.AuditContext) EmployeeRepository {
+	return &EmployeeMemoryDao{employeeMemoryStore: employeeMemoryDao.employeeMemoryStore, audit: audit}
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) CreateEmployee(m *models.Employee) (*models.Employee, error) {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	if err := employeeMemoryDao.insert(m, time.Now()); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) GetEmployee(id int64) (*models.Employee, error) {
+	employeeMemoryDao.applyDueSalaryChanges()
+	employeeMemoryDao.mu.RLock()
+	defer employeeMemoryDao.mu.RUnlock()
+
+	employee, ok := employeeMemoryDao.find(id)
+	if !ok {
+		return nil, sqls.ErrNotExists
+	}
+	return copyEmployee(employee), nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) GetEmployees(query *models.EmployeeQuery) (*models.EmployeePage, error) {
+	employeeMemoryDao.applyDueSalaryChanges()
+	employeeMemoryDao.mu.RLock()
+	defer employeeMemoryDao.mu.RUnlock()
+
+	if query.AsOf != nil {
+		return employeeMemoryDao.getEmployeesAsOf(query)
+	}
+	var employees []*models.Employee
+	for _, employee := range employeeMemoryDao.live() {
+		if matchesEmployeeFilter(employee, &query.Filter) {
+			employees = append(employees, employee)
+		}
+	}
+	if query.Cursor == nil {
+		return offsetEmployeePage(employees, query), nil
+	}
+	page := &models.EmployeePage{Total: int64(len(employees))}
+
+	sort := models.EffectiveEmployeeSort(query.Sort)
+
+	if query.Cursor.Backward {
+		sort = models.ReverseEmployeeSort(sort)
+	}
+	sortEmployees(employees, sort)
+	if !query.Cursor.IsStart() {
+		position, err := query.Cursor.Employee(query.Sort)
+		if err != nil {
+			return nil, err
+		}
+		for len(employees) > 0 && compareEmployeeSort(employees[0], position, sort) <= 0 {
+			employees = employees[1:]
+		}
+	}
+	if len(employees) > query.Limit+1 {
+		employees = employees[:query.Limit+1]
+	}
+	page.Employees = copyEmployees(employees)
+	setKeysetPage(page, query)
+	return page, nil
+}
+
+// offsetEmployeePage sorts employees and copies the page of them query addresses by page number
+func offsetEmployeePage(employees []*models.Employee, query *models.EmployeeQuery) *models.EmployeePage {
+	sortEmployees(employees, models.EffectiveEmployeeSort(query.Sort))
+	return employeePageAt(employees, int64(len(employees)), query)
+}
+
+// employeePageAt copies the page query addresses by page number out of employees, the start of a sorted listing
+// of total employees
+func employeePageAt(employees []*models.Employee, total int64, query *models.EmployeeQuery) *models.EmployeePage {
+	page := &models.EmployeePage{Total: total}
+	offset := (query.Page - 1) * query.Limit
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(employees) {
+		offset = len(employees)
+	}
+	end := len(employees)
+	if query.Limit >= 0 && offset+query.Limit < end {
+		end = offset + query.Limit
+	}
+	page.Employees = copyEmployees(employees[offset:end])
+	page.HasPrev = query.Page > 1
+	page.HasNext = int64(query.Page*query.Limit) < page.Total
+	return page
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) SearchEmployees(query *models.EmployeeSearchQuery) (*models.EmployeeSearchResult, error) {
+	employeeMemoryDao.applyDueSalaryChanges()
+	employeeMemoryDao.mu.RLock()
+	defer employeeMemoryDao.mu.RUnlock()
+
+	var employees []*models.Employee
+	for _, employee := range employeeMemoryDao.live() {
+		if matchesEmployeeFilter(employee, &query.Filter) && matchesSearchTerms(employee, query.Terms) {
+			employees = append(employees, copyEmployee(employee))
+		}
+	}
+	return rankEmployees(employees, query), nil
+}
+
+// ExportEmployees visits a snapshot, so visit may take its time without blocking writers
+func (employeeMemoryDao *EmployeeMemoryDao) ExportEmployees(filter *models.EmployeeFilter, sort []models.EmployeeSort, visit func(*models.Employee) error) error {
+	employeeMemoryDao.applyDueSalaryChanges()
+	employeeMemoryDao.mu.RLock()
+	var employees []*models.Employee
+	for _, employee := range employeeMemoryDao.live() {
+		if matchesEmployeeFilter(employee, filter) {
+			employees = append(employees, copyEmployee(employee))
+		}
+	}
+	employeeMemoryDao.mu.RUnlock()
+
+	sortEmployees(employees, models.EffectiveEmployeeSort(sort))
+	for _, employee := range employees {
+		if err := visit(employee); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) GetManagementChain(id int64) ([]*models.Employee, error) {
+	employeeMemoryDao.applyDueSalaryChanges()
+	employeeMemoryDao.mu.RLock()
+	defer employeeMemoryDao.mu.RUnlock()
+
+	if _, ok := employeeMemoryDao.find(id); !ok {
+		return nil, sqls.ErrNotExists
+	}
+	return copyEmployees(employeeMemoryDao.managementChain(id)), nil
+}
+
+// GetReportingSubtree walks the hierarchy breadth first, the same order reportingSubtreeQuery has
+func (employeeMemoryDao *EmployeeMemoryDao) GetReportingSubtree(id int64) ([]*models.Employee, error) {
+	employeeMemoryDao.applyDueSalaryChanges()
+	employeeMemoryDao.mu.RLock()
+	defer employeeMemoryDao.mu.RUnlock()
+
+	if _, ok := employeeMemoryDao.find(id); !ok {
+		return nil, sqls.ErrNotExists
+	}
+	reports := map[uint][]*models.Employee{}
+	for _, employee := range employeeMemoryDao.live() {
+		if employee.ManagerID != nil {
+			reports[*employee.ManagerID] = append(reports[*employee.ManagerID], employee)
+		}
+	}
+	var subtree []*models.Employee
+	level := reports[uint(id)]
+	for depth := 1; len(level) > 0 && depth <= models.MaxManagementDepth; depth++ {
+		subtree = append(subtree, level...)
+		var next []*models.Employee
+		for _, employee := range level {
+			next = append(next, reports[employee.ID]...)
+		}
+		sort.Slice(next, func(i, j int) bool {
+			return next[i].ID < next[j].ID
+		})
+		level = next
+	}
+	return copyEmployees(subtree), nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) UpdateEmployee(id int64, m *models.Employee) (*models.Employee, error) {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	if err := employeeMemoryDao.update(id, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) PatchEmployee(id int64, version uint, changes map[string]interface{}) (*models.Employee, error) {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	employee, ok := employeeMemoryDao.find(id)
+	if !ok {
+		return nil, sqls.ErrNotExists
+	}
+	if employee.Version != version {
+		return nil, sqls.ErrVersionMismatch
+	}
+	patched := copyEmployee(employee)
+	for column, value := range changes {
+		var ok bool
+		switch column {
+		case "name":
+			patched.Name, ok = value.(string)
+		case "position":
+			patched.Position, ok = value.(string)
+		case "salary":
+			patched.Salary, ok = value.(models.Amount)
+		case "currency":
+			patched.Currency, ok = value.(string)
+		case "department_id":
+			patched.DepartmentID, ok = value.(*uint)
+		case "manager_id":
+			patched.ManagerID, ok = value.(*uint)
+		}
+		if !ok {
+			return nil, fmt.Errorf("cannot set employee column %s to %v", column, value)
+		}
+	}
+	if err := employeeMemoryDao.checkDepartment(patched.DepartmentID); err != nil {
+		return nil, err
+	}
+	if _, ok := changes["manager_id"]; ok {
+		if err := employeeMemoryDao.checkManager(id, patched.ManagerID); err != nil {
+			return nil, err
+		}
+	}
+	patched.UpdatedAt = time.Now()
+	patched.Version++
+	employeeMemoryDao.employees[patched.ID] = patched
+	_, salary := changes["salary"]
+	if _, currency := changes["currency"]; salary || currency {
+		employeeMemoryDao.recordSalary(patched, models.SalaryReasonUpdate, patched.UpdatedAt)
+	}
+	employeeMemoryDao.recordAudit(employeeMemoryDao.audit, models.AuditUpdate, employee, patched, patched.UpdatedAt)
+	return copyEmployee(patched), nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) DeleteEmployee(id int64, version uint) error {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	return employeeMemoryDao.remove(id, version)
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) BatchEmployees(operations []*models.EmployeeBatchOperation, atomic bool) ([]*models.EmployeeBatchResult, error) {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	// an atomic batch restores this snapshot when an operation fails, the salary history and the audit trail
+	// are only appended to
+	lastID, employees := employeeMemoryDao.lastID, make(map[uint]*models.Employee, len(employeeMemoryDao.employees))
+	lastSalaryChangeID, salaryChanges := employeeMemoryDao.lastSalaryChangeID, len(employeeMemoryDao.salaryChanges)
+	lastAuditEntryID, auditEntries := employeeMemoryDao.lastAuditEntryID, len(employeeMemoryDao.auditEntries)
+	for id, employee := range employeeMemoryDao.employees {
+		employees[id] = copyEmployee(employee)
+	}
+
+	results := models.NewEmployeeBatchResults(operations)
+	for i, operation := range operations {
+		if err := applyBatchOperation(employeeMemoryDao, operation, results[i]); err != nil && atomic {
+			employeeMemoryDao.lastID, employeeMemoryDao.employees = lastID, employees
+			employeeMemoryDao.lastSalaryChangeID = lastSalaryChangeID
+			employeeMemoryDao.salaryChanges = employeeMemoryDao.salaryChanges[:salaryChanges]
+			employeeMemoryDao.lastAuditEntryID = lastAuditEntryID
+			employeeMemoryDao.auditEntries = employeeMemoryDao.auditEntries[:auditEntries]
+			models.RollBackEmployeeBatch(results)
+			break
+		}
+		if results[i].Employee != nil {
+			results[i].Employee = copyEmployee(results[i].Employee)
+		}
+	}
+	return results, nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) create(m *models.Employee) error {
+	return employeeMemoryDao.insert(m, time.Now())
+}
+
+// update overwrites the fields of employee id with those of m, the caller holds the lock
+func (employeeMemoryDao *EmployeeMemoryDao) update(id int64, m *models.Employee) error {
+	if id == 0 {
+		return errors.New("invalid employee ID")
+	}
+	if id != int64(m.ID) {
+		return errors.New("id and payload don't match")
+	}
+	employee, ok := employeeMemoryDao.find(id)
+	if !ok {
+		return sqls.ErrNotExists
+	}
+	if m.Version != 0 && m.Version != employee.Version {
+		return sqls.ErrVersionMismatch
+	}
+	m.ResolveMaskedSalary(employee)
+	if len(m.Currency) == 0 {
+		m.Currency = employee.Currency
+	}
+	if err := employeeMemoryDao.checkDepartment(m.DepartmentID); err != nil {
+		return err
+	}
+	if !models.SameID(m.ManagerID, employee.ManagerID) {
+		if err := employeeMemoryDao.checkManager(id, m.ManagerID); err != nil {
+			return err
+		}
+	}
+	m.CreatedAt = employee.CreatedAt
+	m.UpdatedAt = time.Now()
+	m.Version = employee.Version + 1
+	employeeMemoryDao.employees[m.ID] = copyEmployee(m)
+	if salaryChanged(m, employee) {
+		employeeMemoryDao.recordSalary(m, models.SalaryReasonUpdate, m.UpdatedAt)
+	}
+	employeeMemoryDao.recordAudit(employeeMemoryDao.audit, models.AuditUpdate, employee, m, m.UpdatedAt)
+	return nil
+}
+
+// remove soft deletes employee id, the caller holds the lock
+func (employeeMemoryDao *EmployeeMemoryDao) remove(id int64, version uint) error {
+	employee, ok := employeeMemoryDao.employees[uint(id)]
+	if !ok || id <= 0 {
+		return sqls.ErrNotExists
+	}
+	if employee.DeletedAt.Valid {
+		return sqls.ErrDeleted
+	}
+	if version != 0 && version != employee.Version {
+		return sqls.ErrVersionMismatch
+	}
+	before := copyEmployee(employee)
+	employee.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	employeeMemoryDao.recordAudit(employeeMemoryDao.audit, models.AuditDelete, before, employee, employee.DeletedAt.Time)
+	return nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) RestoreEmployee(id int64, version uint) (*models.Employee, error) {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	employee, ok := employeeMemoryDao.employees[uint(id)]
+	if !ok || id <= 0 {
+		return nil, sqls.ErrNotExists
+	}
+	if !employee.DeletedAt.Valid {
+		return nil, sqls.ErrNotDeleted
+	}
+	if version != 0 && version != employee.Version {
+		return nil, sqls.ErrVersionMismatch
+	}
+	before := copyEmployee(employee)
+	employee.DeletedAt = gorm.DeletedAt{}
+	employee.UpdatedAt = time.Now()
+	employee.Version++
+	employeeMemoryDao.recordAudit(employeeMemoryDao.audit, models.AuditRestore, before, employee, employee.UpdatedAt)
+	return copyEmployee(employee), nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) PurgeEmployee(id int64) error {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	purged, ok := employeeMemoryDao.employees[uint(id)]
+	if !ok || id <= 0 {
+		return sqls.ErrNotExists
+	}
+	now := time.Now()
+	for _, employee := range employeeMemoryDao.all() {
+		if employee.ManagerID != nil && int64(*employee.ManagerID) == id {
+			before := copyEmployee(employee)
+			employee.ManagerID = nil
+			employee.UpdatedAt = now
+			employee.Version++
+			employeeMemoryDao.recordAudit(employeeMemoryDao.audit, models.AuditUpdate, before, employee, now)
+		}
+	}
+	delete(employeeMemoryDao.employees, uint(id))
+	employeeMemoryDao.recordAudit(employeeMemoryDao.audit, models.AuditPurge, purged, nil, now)
+	employeeMemoryDao.salaryChanges = slices.DeleteFunc(employeeMemoryDao.salaryChanges, func(change *models.SalaryChange) bool {
+		return int64(change.EmployeeID) == id
+	})
+	return nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) CreateEmployees(employees []*models.Employee) error {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	for _, m := range employees {
+		if m.ID != 0 {
+			if _, ok := employeeMemoryDao.employees[m.ID]; ok {
+				return sqls.ErrDuplicate
+			}
+		}
+		if err := employeeMemoryDao.checkDepartment(m.DepartmentID); err != nil {
+			return err
+		}
+		if err := employeeMemoryDao.checkManager(0, m.ManagerID); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	for _, m := range employees {
+		if err := employeeMemoryDao.insert(m, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) CheckEmployeeReferences(employees []*models.Employee) ([][]*models.FieldError, error) {
+	employeeMemoryDao.mu.RLock()
+	defer employeeMemoryDao.mu.RUnlock()
+
+	return referenceErrors(employees, employeeMemoryDao.checkDepartment, func(managerID *uint) error {
+		return employeeMemoryDao.checkManager(0, managerID)
+	})
+}
+
+// insert stores a copy of m, assigning an ID and timestamps the same way gorm does
+func (employeeMemoryDao *EmployeeMemoryDao) insert(m *models.Employee, now time.Time) error {
+	if err := employeeMemoryDao.checkDepartment(m.DepartmentID); err != nil {
+		return err
+	}
+	if err := employeeMemoryDao.checkManager(0, m.ManagerID); err != nil {
+		return err
+	}
+	if m.ID == 0 {
+		employeeMemoryDao.lastID++
+		m.ID = employeeMemoryDao.lastID
+	} else if _, ok := employeeMemoryDao.employees[m.ID]; ok {
+		return sqls.ErrDuplicate
+	} else if m.ID > employeeMemoryDao.lastID {
+		employeeMemoryDao.lastID = m.ID
+	}
+	initEmployee(m)
+	m.CreatedAt, m.UpdatedAt = now, now
+	employeeMemoryDao.employees[m.ID] = copyEmployee(m)
+	employeeMemoryDao.recordSalary(m, models.SalaryReasonInitial, now)
+	employeeMemoryDao.recordAudit(employeeMemoryDao.audit, models.AuditCreate, nil, m, now)
+	return nil
+}
+
+// managementChain follows the managers of employee id like managementChainQuery, the caller holds the lock
+func (employeeMemoryDao *EmployeeMemoryDao) managementChain(id int64) []*models.Employee {
+	var chain []*models.Employee
+	employee, ok := employeeMemoryDao.find(id)
+	for depth := 1; ok && employee.ManagerID != nil && depth <= models.MaxManagementDepth; depth++ {
+		if employee, ok = employeeMemoryDao.find(int64(*employee.ManagerID)); ok {
+			chain = append(chain, employee)
+		}
+	}
+	return chain
+}
+
+// checkManager fails with a validation error when employee id, 0 for a new one, cannot report to managerID,
+// the caller holds the lock
+func (employeeMemoryDao *EmployeeMemoryDao) checkManager(id int64, managerID *uint) error {
+	if managerID == nil {
+		return nil
+	}
+	if _, ok := employeeMemoryDao.find(int64(*managerID)); !ok {
+		return unknownManagerError(*managerID)
+	}
+	if id == 0 {
+		return nil
+	}
+	var chainIDs []uint
+	for _, manager := range employeeMemoryDao.managementChain(int64(*managerID)) {
+		chainIDs = append(chainIDs, manager.ID)
+	}
+	return models.ValidateManager(id, *managerID, chainIDs)
+}
+
+// find returns the stored employee unless it was soft deleted
+func (employeeMemoryDao *EmployeeMemoryDao) find(id int64) (*models.Employee, bool) {
+	if id <= 0 {
+		return nil, false
+	}
+	employee, ok := employeeMemoryDao.employees[uint(id)]
+	if !ok || employee.DeletedAt.Valid {
+		return nil, false
+	}
+	return employee, true
+}
+
+// live returns the employees that are not soft deleted, ordered by ID
+func (employeeMemoryDao *EmployeeMemoryDao) live() []*models.Employee {
+	return slices.DeleteFunc(employeeMemoryDao.all(), func(employee *models.Employee) bool {
+		return employee.DeletedAt.Valid
+	})
+}
+
+// all returns the employees, soft deleted ones included, ordered by ID
+func (employeeMemoryDao *EmployeeMemoryDao) all() []*models.Employee {
+	employees := make([]*models.Employee, 0, len(employeeMemoryDao.employees))
+	for _, employee := range employeeMemoryDao.employees {
+		employees = append(employees, employee)
+	}
+	sort.Slice(employees, func(i, j int) bool {
+		return employees[i].ID < employees[j].ID
+	})
+	return employees
+}
+
+func copyEmployee(m *models.Employee) *models.Employee {
+	employee := *m
+	employee.DepartmentID = copyID(m.DepartmentID)
+	employee.ManagerID = copyID(m.ManagerID)
+	return &employee
+}
+
+func copyID(id *uint) *uint {
+	if id == nil {
+		return nil
+	}
+	copied := *id
+	return &copied
+}
+
+func copyEmployees(employees []*models.Employee) []*models.Employee {
+	m := make([]*models.Employee, 0, len(employees))
+	for _, employee := range employees {
+		m = append(m, copyEmployee(employee))
+	}
+	return m
+}
+
+// matchesEmployeeFilter evaluates filter the same way applyEmployeeFilter does in SQL
+func matchesEmployeeFilter(employee *models.Employee, filter *models.EmployeeFilter) bool {
+	name := strings.ToLower(employee.Name)
+	if len(filter.NamePrefix) > 0 && !strings.HasPrefix(name, strings.ToLower(filter.NamePrefix)) {
+		return false
+	}
+	if len(filter.NameContains) > 0 && !strings.Contains(name, strings.ToLower(filter.NameContains)) {
+		return false
+	}
+	if len(filter.Positions) > 0 {
+		found := false
+		for _, position := range filter.Positions {
+			found = found || position == employee.Position
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(filter.DepartmentIDs) > 0 && (employee.DepartmentID == nil || !slices.Contains(filter.DepartmentIDs, *employee.DepartmentID)) {
+		return false
+	}
+	if len(filter.ManagerIDs) > 0 && (employee.ManagerID == nil || !slices.Contains(filter.ManagerIDs, *employee.ManagerID)) {
+		return false
+	}
+	if len(filter.Currencies) > 0 && !slices.Contains(filter.Currencies, employee.Currency) {
+		return false
+	}
+	if filter.SalaryMin != nil && employee.Salary.LessThan(filter.SalaryMin.Decimal) {
+		return false
+	}
+	if filter.SalaryMax != nil && employee.Salary.GreaterThan(filter.SalaryMax.Decimal) {
+		return false
+	}
+	if filter.CreatedFrom != nil && employee.CreatedAt.Before(*filter.CreatedFrom) {
+		return false
+	}
+	if filter.CreatedTo != nil && employee.CreatedAt.After(*filter.CreatedTo) {
+		return false
+	}
+	if filter.UpdatedFrom != nil && employee.UpdatedAt.Before(*filter.UpdatedFrom) {
+		return false
+	}
+	if filter.UpdatedTo != nil && employee.UpdatedAt.After(*filter.UpdatedTo) {
+		return false
+	}
+	return true
+}
+
+// sortEmployees orders employees like applyEmployeeSort does in SQL
+func sortEmployees(employees []*models.Employee, sort []models.EmployeeSort) {
+	slices.SortStableFunc(employees, func(a, b *models.Employee) int {
+		return compareEmployeeSort(a, b, sort)
+	})
+}
+
+func compareEmployeeSort(a, b *models.Employee, sort []models.EmployeeSort) int {
+	for _, employeeSort := range sort {
+		if c := compareEmployees(a, b, employeeSort.Field); c != 0 {
+			if employeeSort.Desc {
+				return -c
+			}
+			return c
+		}
+	}
+	return 0
+}
+
+func compareEmployees(a, b *models.Employee, field string) int {
+	switch field {
+	case "id":
+		return compareOrdered(a.ID, b.ID)
+	case "name":
+		return strings.Compare(a.Name, b.Name)
+	case "position":
+		return strings.Compare(a.Position, b.Position)
+	case "salary":
+		return a.Salary.Cmp(b.Salary.Decimal)
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	}
+	return 0
+}
+
+func compareOrdered[T uint | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
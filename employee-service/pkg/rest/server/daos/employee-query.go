@@ -0,0 +1,115 @@
+package daos
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
+)
+
+// likeEscaper makes user input match literally inside a LIKE pattern with an ESCAPE '!' clause. The escape
+// character is not a backslash, which MySQL reads as escaping the closing quote of the clause.
+var likeEscaper = strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`)
+
+// applyEmployeeFilter adds the conditions of filter to db, only ever binding values as parameters
+func applyEmployeeFilter(db *gorm.DB, filter *models.EmployeeFilter) *gorm.DB {
+	if filter == nil {
+		return db
+	}
+	if len(filter.NamePrefix) > 0 {
+		db = db.Where(`LOWER(name) LIKE ? ESCAPE '!'`, likeEscaper.Replace(strings.ToLower(filter.NamePrefix))+"%")
+	}
+	if len(filter.NameContains) > 0 {
+		db = db.Where(`LOWER(name) LIKE ? ESCAPE '!'`, "%"+likeEscaper.Replace(strings.ToLower(filter.NameContains))+"%")
+	}
+	if len(filter.Positions) == 1 {
+		db = db.Where("position = ?", filter.Positions[0])
+	} else if len(filter.Positions) > 1 {
+		db = db.Where("position IN ?", filter.Positions)
+	}
+	if len(filter.DepartmentIDs) == 1 {
+		db = db.Where("department_id = ?", filter.DepartmentIDs[0])
+	} else if len(filter.DepartmentIDs) > 1 {
+		db = db.Where("department_id IN ?", filter.DepartmentIDs)
+	}
+	if len(filter.ManagerIDs) == 1 {
+		db = db.Where("manager_id = ?", filter.ManagerIDs[0])
+	} else if len(filter.ManagerIDs) > 1 {
+		db = db.Where("manager_id IN ?", filter.ManagerIDs)
+	}
+	if len(filter.Currencies) == 1 {
+		db = db.Where("currency = ?", filter.Currencies[0])
+	} else if len(filter.Currencies) > 1 {
+		db = db.Where("currency IN ?", filter.Currencies)
+	}
+	if filter.SalaryMin != nil {
+		db = db.Where("salary >= ?", *filter.SalaryMin)
+	}
+	if filter.SalaryMax != nil {
+		db = db.Where("salary <= ?", *filter.SalaryMax)
+	}
+	if filter.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		db = db.Where("created_at <= ?", *filter.CreatedTo)
+	}
+	if filter.UpdatedFrom != nil {
+		db = db.Where("updated_at >= ?", *filter.UpdatedFrom)
+	}
+	if filter.UpdatedTo != nil {
+		db = db.Where("updated_at <= ?", *filter.UpdatedTo)
+	}
+	return db
+}
+
+// applyEmployeeSort orders db by the whitelisted columns of sort, see models.EffectiveEmployeeSort
+func applyEmployeeSort(db *gorm.DB, sort []models.EmployeeSort) *gorm.DB {
+	for _, employeeSort := range sort {
+		if column, ok := models.EmployeeSortColumns[employeeSort.Field]; ok {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: employeeSort.Desc})
+		}
+	}
+	return db
+}
+
+// applyEmployeeKeyset keeps the rows that come after position in a listing ordered by sort,
+// i.e. (a > ?) OR (a = ? AND b > ?) OR ... with < for descending fields
+func applyEmployeeKeyset(db *gorm.DB, sort []models.EmployeeSort, position *models.Employee) *gorm.DB {
+	var conditions []string
+	var vars []interface{}
+	for i, employeeSort := range sort {
+		var parts []string
+		for _, previous := range sort[:i] {
+			parts = append(parts, models.EmployeeSortColumns[previous.Field]+" = ?")
+			vars = append(vars, models.EmployeeSortValue(position, previous.Field))
+		}
+		operator := " > ?"
+		if employeeSort.Desc {
+			operator = " < ?"
+		}
+		parts = append(parts, models.EmployeeSortColumns[employeeSort.Field]+operator)
+		vars = append(vars, models.EmployeeSortValue(position, employeeSort.Field))
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+	return db.Where("("+strings.Join(conditions, " OR ")+")", vars...)
+}
+
+// setKeysetPage trims the extra row fetched to detect more rows, and restores the listing
+// order of a page that was read backwards
+func setKeysetPage(page *models.EmployeePage, query *models.EmployeeQuery) {
+	more := len(page.Employees) > query.Limit
+	if more {
+		page.Employees = page.Employees[:query.Limit]
+	}
+	if !query.Cursor.Backward {
+		page.HasPrev = !query.Cursor.IsStart()
+		page.HasNext = more
+		return
+	}
+	for i, j := 0, len(page.Employees)-1; i < j; i, j = i+1, j-1 {
+		page.Employees[i], page.Employees[j] = page.Employees[j], page.Employees[i]
+	}
+	page.HasPrev = more
+	page.HasNext = true
+}
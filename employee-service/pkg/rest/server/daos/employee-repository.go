@@ -0,0 +1,63 @@
+package daos
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"time"
+)
+
+// EmployeeRepository is the storage the employee service works against.
+// EmployeeDao implements it on top of gorm, EmployeeMemoryDao keeps everything in memory.
+type EmployeeRepository interface {
+	// WithAudit returns a repository sharing the storage of this one that attributes the changes made through it to audit
+	WithAudit(audit models.AuditContext) EmployeeRepository
+	CreateEmployee(m *models.Employee) (*models.Employee, error)
+	GetEmployee(id int64) (*models.Employee, error)
+	// GetEmployeeAsOf returns employee id as it was at asOf, rewinding its changes in the audit trail since.
+	// It fails with sqls.ErrNotExists for employees that did not exist or were deleted then.
+	GetEmployeeAsOf(id int64, asOf time.Time) (*models.Employee, error)
+	GetEmployees(query *models.EmployeeQuery) (*models.EmployeePage, error)
+	SearchEmployees(query *models.EmployeeSearchQuery) (*models.EmployeeSearchResult, error)
+	// ExportEmployees hands every employee matching filter to visit in sort order, one at a time.
+	// It stops at the first error visit returns and returns that error.
+	ExportEmployees(filter *models.EmployeeFilter, sort []models.EmployeeSort, visit func(*models.Employee) error) error
+	// GetManagementChain returns the managers of employee id up to the top of the organization, nearest first.
+	// GetReportingSubtree returns everyone reporting to employee id directly or indirectly, level by level.
+	// Both fail with sqls.ErrNotExists for unknown and deleted employees.
+	GetManagementChain(id int64) ([]*models.Employee, error)
+	GetReportingSubtree(id int64) ([]*models.Employee, error)
+	// GetSalaryChanges returns the salary history of employee id by effective date, scheduled changes included.
+	// CreateSalaryChange records a change and applies it when it is effective by today. DeleteSalaryChange cancels
+	// a scheduled change, failing with models.ErrSalaryChangeEffective for one that took effect.
+	// All of them fail with sqls.ErrNotExists for unknown and deleted employees.
+	GetSalaryChanges(id int64) ([]*models.SalaryChange, error)
+	CreateSalaryChange(m *models.SalaryChange) (*models.SalaryChange, error)
+	DeleteSalaryChange(employeeID int64, id int64) error
+	// GetSalaryReport sums up the salaries of the employees matching the filter of query, group by group
+	GetSalaryReport(query *models.SalaryReportQuery) ([]*models.SalaryStats, error)
+	// UpdateEmployee and DeleteEmployee fail with sqls.ErrVersionMismatch when given a version other than the stored one,
+	// version 0 matches any
+	UpdateEmployee(id int64, m *models.Employee) (*models.Employee, error)
+	// PatchEmployee writes only the given columns if the stored employee is still at version and returns it
+	PatchEmployee(id int64, version uint, changes map[string]interface{}) (*models.Employee, error)
+	// DeleteEmployee soft deletes, failing with sqls.ErrNotExists for unknown and sqls.ErrDeleted for deleted employees
+	DeleteEmployee(id int64, version uint) error
+	// RestoreEmployee undoes a soft delete, failing with sqls.ErrNotDeleted for live employees
+	RestoreEmployee(id int64, version uint) (*models.Employee, error)
+	// PurgeEmployee removes an employee for good, deleted or not
+	PurgeEmployee(id int64) error
+	CreateEmployees(employees []*models.Employee) error
+	// CheckEmployeeReferences checks the departments and managers of employees about to be created exist, the way
+	// CreateEmployees does, and returns the invalid fields of every employee, none for the valid ones
+	CheckEmployeeReferences(employees []*models.Employee) ([][]*models.FieldError, error)
+	// BatchEmployees applies create, update and delete operations in order, all or nothing when atomic is set.
+	// Failing operations are reported in their result, the error is for a batch that could not be run at all.
+	BatchEmployees(operations []*models.EmployeeBatchOperation, atomic bool) ([]*models.EmployeeBatchResult, error)
+	// GetAuditEntries returns a page of the audit trail, every create, update, delete, restore and purge of an employee
+	// in the order they were made
+	GetAuditEntries(query *models.AuditQuery) (*models.AuditPage, error)
+}
+
+var (
+	_ EmployeeRepository = (*EmployeeDao)(nil)
+	_ EmployeeRepository = (*EmployeeMemoryDao)(nil)
+)
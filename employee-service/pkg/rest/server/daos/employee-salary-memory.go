@@ -0,0 +1,141 @@
+package daos
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+func (employeeMemoryDao *EmployeeMemoryDao) GetSalaryChanges(id int64) ([]*models.SalaryChange, error) {
+	employeeMemoryDao.applyDueSalaryChanges()
+	employeeMemoryDao.mu.RLock()
+	defer employeeMemoryDao.mu.RUnlock()
+
+	if _, ok := employeeMemoryDao.find(id); !ok {
+		return nil, sqls.ErrNotExists
+	}
+	var changes []*models.SalaryChange
+	for _, change := range employeeMemoryDao.salaryChanges {
+		if int64(change.EmployeeID) == id {
+			copied := *change
+			changes = append(changes, &copied)
+		}
+	}
+	slices.SortStableFunc(changes, compareSalaryChanges)
+	return changes, nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) CreateSalaryChange(m *models.SalaryChange) (*models.SalaryChange, error) {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	employee, ok := employeeMemoryDao.find(int64(m.EmployeeID))
+	if !ok {
+		return nil, sqls.ErrNotExists
+	}
+	if len(m.Currency) == 0 {
+		m.Currency = employee.Currency
+	}
+	now := time.Now()
+	employeeMemoryDao.appendSalaryChange(m, now)
+	if today := models.Today(); m.EffectiveDate <= today {
+		employeeMemoryDao.applySalaryChanges(employeeMemoryDao.audit, today, m.EmployeeID, now)
+	}
+	return m, nil
+}
+
+func (employeeMemoryDao *EmployeeMemoryDao) DeleteSalaryChange(employeeID int64, id int64) error {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	if _, ok := employeeMemoryDao.find(employeeID); !ok {
+		return sqls.ErrNotExists
+	}
+	i := slices.IndexFunc(employeeMemoryDao.salaryChanges, func(change *models.SalaryChange) bool {
+		return int64(change.ID) == id && int64(change.EmployeeID) == employeeID
+	})
+	if i < 0 {
+		return sqls.ErrNotExists
+	}
+	if employeeMemoryDao.salaryChanges[i].EffectiveDate <= models.Today() {
+		return models.ErrSalaryChangeEffective
+	}
+	employeeMemoryDao.salaryChanges = slices.Delete(employeeMemoryDao.salaryChanges, i, i+1)
+	return nil
+}
+
+// applyDueSalaryChanges applies the salaries that took effect since the last call, like EmployeeDao does
+func (employeeMemoryDao *EmployeeMemoryDao) applyDueSalaryChanges() {
+	employeeMemoryDao.mu.Lock()
+	defer employeeMemoryDao.mu.Unlock()
+
+	today := models.Today()
+	if employeeMemoryDao.salariesAppliedOn == today {
+		return
+	}
+	employeeMemoryDao.applySalaryChanges(models.AuditContext{}, today, 0, time.Now())
+	employeeMemoryDao.salariesAppliedOn = today
+}
+
+// applySalaryChanges sets the salary of employee id, or of every employee for 0, to the one effective today
+// the way applySalaryChangesQuery does and audits the changes, the caller holds the lock
+func (employeeMemoryDao *EmployeeMemoryDao) applySalaryChanges(audit models.AuditContext, today models.Date, id uint, now time.Time) {
+	effective := map[uint]*models.SalaryChange{}
+	for _, change := range employeeMemoryDao.salaryChanges {
+		if change.EffectiveDate > today || (id != 0 && change.EmployeeID != id) {
+			continue
+		}
+		if latest, ok := effective[change.EmployeeID]; !ok || compareSalaryChanges(change, latest) > 0 {
+			effective[change.EmployeeID] = change
+		}
+	}
+	for _, employee := range employeeMemoryDao.all() {
+		change, ok := effective[employee.ID]
+		if ok && salaryChanged(&models.Employee{Salary: change.Salary, Currency: change.Currency}, employee) {
+			before := copyEmployee(employee)
+			employee.Salary, employee.Currency = change.Salary, change.Currency
+			employee.UpdatedAt = now
+			employee.Version++
+			employeeMemoryDao.recordAudit(audit, models.AuditUpdate, before, employee, now)
+		}
+	}
+}
+
+// recordSalary adds a change effective today to the salary history of employee, the caller holds the lock
+func (employeeMemoryDao *EmployeeMemoryDao) recordSalary(employee *models.Employee, reason string, now time.Time) {
+	employeeMemoryDao.appendSalaryChange(&models.SalaryChange{
+		EmployeeID:    employee.ID,
+		Salary:        employee.Salary,
+		Currency:      employee.Currency,
+		EffectiveDate: models.Today(),
+		Reason:        reason,
+	}, now)
+}
+
+// appendSalaryChange stores a copy of m, assigning an ID and a creation time the same way gorm does
+func (employeeMemoryDao *EmployeeMemoryDao) appendSalaryChange(m *models.SalaryChange, now time.Time) {
+	employeeMemoryDao.lastSalaryChangeID++
+	m.ID = employeeMemoryDao.lastSalaryChangeID
+	m.CreatedAt = now
+	copied := *m
+	employeeMemoryDao.salaryChanges = append(employeeMemoryDao.salaryChanges, &copied)
+}
+
+// compareSalaryChanges orders changes by effective date, changes effective the same day in the order they were made
+func compareSalaryChanges(a, b *models.SalaryChange) int {
+	if c := strings.Compare(string(a.EffectiveDate), string(b.EffectiveDate)); c != 0 {
+		return c
+	}
+	return compareOrdered(a.ID, b.ID)
+}
+
+// GetSalaryReport sums up the salaries of the matching employees in memory
+func (employeeMemoryDao *EmployeeMemoryDao) GetSalaryReport(query *models.SalaryReportQuery) ([]*models.SalaryStats, error) {
+	report := models.NewSalaryReport(query)
+	if err := employeeMemoryDao.ExportEmployees(&query.Filter, nil, report.Add); err != nil {
+		return nil, err
+	}
+	return report.Stats(), nil
+}
@@ -0,0 +1,115 @@
+package daos
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+// salaryGroupRow is a group of a salary report as the database aggregates it
+type salaryGroupRow struct {
+	Position     string
+	DepartmentID *uint
+	HireYear     int
+	Currency     string
+	Headcount    int
+	Total        models.Amount
+	MinSalary    models.Amount
+	MaxSalary    models.Amount
+}
+
+// GetSalaryReport counts, sums and bounds the salaries of every group in SQL and reads only the salaries the median
+// lies between. Percentiles and bands take every salary, so those reports read the matching employees.
+func (employeeDao *EmployeeDao) GetSalaryReport(query *models.SalaryReportQuery) ([]*models.SalaryStats, error) {
+	report := models.NewSalaryReport(query)
+	if query.NeedsSalaries() {
+		if err := employeeDao.ExportEmployees(&query.Filter, nil, report.Add); err != nil {
+			return nil, err
+		}
+		return report.Stats(), nil
+	}
+	if err := employeeDao.applyDueSalaryChanges(); err != nil {
+		log.Debugf("failed to get salary report: %v", err)
+		return nil, err
+	}
+
+	// the columns grouped by, keyed by their name in salaryGroupRow
+	columns := [][2]string{{"currency", "currency"}}
+	for _, field := range query.GroupBy {
+		switch field {
+		case "position":
+			columns = append(columns, [2]string{"position", "position"})
+		case "department":
+			columns = append(columns, [2]string{"department_id", "department_id"})
+		case "hire_year":
+			columns = append(columns, [2]string{"hire_year", employeeDao.hireYearColumn()})
+		}
+	}
+	var selects, groups []string
+	for _, column := range columns {
+		selects = append(selects, column[1]+" AS "+column[0])
+		groups = append(groups, column[1])
+	}
+	selects = append(selects, "COUNT(*) AS headcount", "SUM(salary) AS total", "MIN(salary) AS min_salary", "MAX(salary) AS max_salary")
+	var rows []*salaryGroupRow
+	if err := applyEmployeeFilter(employeeDao.db.Model(&models.Employee{}), &query.Filter).
+		Select(strings.Join(selects, ", ")).Group(strings.Join(groups, ", ")).Scan(&rows).Error; err != nil {
+		log.Debugf("failed to get salary report: %v", err)
+		return nil, err
+	}
+
+	for _, row := range rows {
+		// the median is the middle salary of the group or the average of the two middle ones
+		db := applyEmployeeFilter(employeeDao.db.Model(&models.Employee{}), &query.Filter)
+		for _, column := range columns {
+			switch {
+			case column[0] == "department_id" && row.DepartmentID == nil:
+				db = db.Where("department_id IS NULL")
+			case column[0] == "department_id":
+				db = db.Where("department_id = ?", *row.DepartmentID)
+			case column[0] == "hire_year":
+				db = db.Where(column[1]+" = ?", row.HireYear)
+			case column[0] == "position":
+				db = db.Where("position = ?", row.Position)
+			default:
+				db = db.Where("currency = ?", row.Currency)
+			}
+		}
+		offset, count := models.MedianRows(row.Headcount)
+		var middle []models.Amount
+		if err := db.Order("salary").Offset(offset).Limit(count).Pluck("salary", &middle).Error; err != nil {
+			log.Debugf("failed to get salary report: %v", err)
+			return nil, err
+		}
+		if len(middle) == 0 {
+			// the group changed since it was aggregated
+			continue
+		}
+		salaries := make([]decimal.Decimal, 0, len(middle))
+		for _, salary := range middle {
+			salaries = append(salaries, salary.Decimal)
+		}
+		employee := &models.Employee{
+			Position:     row.Position,
+			DepartmentID: row.DepartmentID,
+			Currency:     row.Currency,
+		}
+		employee.CreatedAt = time.Date(row.HireYear, time.January, 1, 0, 0, 0, 0, time.UTC)
+		report.AddTotals(employee, models.SalaryTotals{Headcount: row.Headcount, Total: row.Total, Min: row.MinSalary, Max: row.MaxSalary}, salaries)
+	}
+	log.Debugf("salary report retrieved")
+	return report.Stats(), nil
+}
+
+// hireYearColumn is the UTC year employees were created in, in the SQL of the database
+func (employeeDao *EmployeeDao) hireYearColumn() string {
+	switch employeeDao.db.Dialector.Name() {
+	case "postgres":
+		return "CAST(EXTRACT(YEAR FROM created_at AT TIME ZONE 'UTC') AS INTEGER)"
+	case "mysql":
+		return "YEAR(created_at)"
+	}
+	return "CAST(strftime('%Y', created_at) AS INTEGER)"
+}
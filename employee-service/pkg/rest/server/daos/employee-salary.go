@@ -0,0 +1,162 @@
+package daos
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// effectiveSalaryChange picks the salary change of an employee effective as of @today, the latest one by then
+const effectiveSalaryChange = `FROM salary_changes
+	WHERE salary_changes.employee_id = employees.id AND salary_changes.effective_date <= @today
+	ORDER BY salary_changes.effective_date DESC, salary_changes.id DESC LIMIT 1`
+
+const (
+	effectiveSalary   = `(SELECT salary_changes.salary ` + effectiveSalaryChange + `)`
+	effectiveCurrency = `(SELECT salary_changes.currency ` + effectiveSalaryChange + `)`
+)
+
+// applySalaryChangesQuery brings the salaries of employees up to date with the changes effective by @today,
+// applySalaryChangesCondition picks the employees it changes. Employees without changes keep their salary,
+// the comparisons with no salary at all are never true.
+const (
+	applySalaryChangesCondition = `(salary <> ` + effectiveSalary + ` OR currency <> ` + effectiveCurrency + `)`
+	applySalaryChangesQuery     = `UPDATE employees
+SET salary = ` + effectiveSalary + `, currency = ` + effectiveCurrency + `, version = version + 1, updated_at = @now
+WHERE ` + applySalaryChangesCondition
+)
+
+func (employeeDao *EmployeeDao) GetSalaryChanges(id int64) ([]*models.SalaryChange, error) {
+	if err := employeeDao.applyDueSalaryChanges(); err != nil {
+		log.Debugf("failed to get salary changes: %v", err)
+		return nil, err
+	}
+	if _, err := findEmployee(employeeDao.db, id); err != nil {
+		log.Debugf("failed to get salary changes: %v", err)
+		return nil, err
+	}
+	var changes []*models.SalaryChange
+	if err := employeeDao.db.Where("employee_id = ?", id).Order("effective_date, id").Find(&changes).Error; err != nil {
+		log.Debugf("failed to get salary changes: %v", err)
+		return nil, err
+	}
+	log.Debugf("salary changes retrieved")
+	return changes, nil
+}
+
+func (employeeDao *EmployeeDao) CreateSalaryChange(m *models.SalaryChange) (*models.SalaryChange, error) {
+	if err := employeeDao.db.Transaction(func(tx *gorm.DB) error {
+		employee, err := findEmployee(tx, int64(m.EmployeeID))
+		if err != nil {
+			return err
+		}
+		if len(m.Currency) == 0 {
+			m.Currency = employee.Currency
+		}
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		// a change effective by today may change the salary right away, a scheduled one waits for its day
+		today := models.Today()
+		if m.EffectiveDate > today {
+			return nil
+		}
+		return applySalaryChanges(tx, employeeDao.audit, today, m.EmployeeID)
+	}); err != nil {
+		log.Debugf("failed to create salary change: %v", err)
+		return nil, err
+	}
+	log.Debugf("salary change created")
+	return m, nil
+}
+
+func (employeeDao *EmployeeDao) DeleteSalaryChange(employeeID int64, id int64) error {
+	if err := employeeDao.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := findEmployee(tx, employeeID); err != nil {
+			return err
+		}
+		var change *models.SalaryChange
+		result := tx.Where("id = ? AND employee_id = ?", id, employeeID).Limit(1).Find(&change)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return sqls.ErrNotExists
+		}
+		if change.EffectiveDate <= models.Today() {
+			return models.ErrSalaryChangeEffective
+		}
+		return tx.Delete(change).Error
+	}); err != nil {
+		log.Debugf("failed to delete salary change: %v", err)
+		return err
+	}
+	log.Debugf("salary change deleted")
+	return nil
+}
+
+// applyDueSalaryChanges writes the salaries that took effect since the last call to the employees, which it does
+// once a day as the changes take effect by the day. A read-only database keeps the salaries as they are.
+func (employeeDao *EmployeeDao) applyDueSalaryChanges() error {
+	if employeeDao.readOnly {
+		return nil
+	}
+	employeeDao.salaries.mu.Lock()
+	defer employeeDao.salaries.mu.Unlock()
+
+	today := models.Today()
+	if employeeDao.salaries.on == today {
+		return nil
+	}
+	// nobody in particular makes these changes, they are due
+	if err := employeeDao.db.Transaction(func(tx *gorm.DB) error {
+		return applySalaryChanges(tx, models.AuditContext{}, today, 0)
+	}); err != nil {
+		return err
+	}
+	employeeDao.salaries.on = today
+	return nil
+}
+
+// applySalaryChanges sets the salary of employee id, or of every employee for 0, to the one effective today
+// and records the update of each employee whose salary changed
+func applySalaryChanges(tx *gorm.DB, audit models.AuditContext, today models.Date, id uint) error {
+	args := map[string]interface{}{"today": today, "now": tx.NowFunc()}
+	due := tx.Unscoped().Model(&models.Employee{}).Where(applySalaryChangesCondition, args)
+	if id != 0 {
+		due = due.Where("id = ?", id)
+	}
+	var ids []uint
+	if err := due.Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	return auditUpdates(tx, audit, ids, func() error {
+		args["ids"] = ids
+		return tx.Exec(applySalaryChangesQuery+" AND id IN @ids", args).Error
+	})
+}
+
+// salaryChanged tells whether m pays another salary than employee, in amount or in currency
+func salaryChanged(m *models.Employee, employee *models.Employee) bool {
+	return !m.Salary.Equal(employee.Salary.Decimal) || m.Currency != employee.Currency
+}
+
+// recordSalaries adds a change effective today to the salary history of each of employees
+func recordSalaries(tx *gorm.DB, reason string, employees ...*models.Employee) error {
+	today := models.Today()
+	changes := make([]*models.SalaryChange, 0, len(employees))
+	for _, employee := range employees {
+		changes = append(changes, &models.SalaryChange{
+			EmployeeID:    employee.ID,
+			Salary:        employee.Salary,
+			Currency:      employee.Currency,
+			EffectiveDate: today,
+			Reason:        reason,
+		})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return tx.Create(&changes).Error
+}
@@ -0,0 +1,277 @@
+package daos
+
+import (
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	searchEngineFTS5 = "fts5"
+	searchEngineLike = "like"
+)
+
+// highlightOpen and highlightClose mark the matches in FTS5 highlights. The text between them is
+// HTML-escaped before they become the highlight tags, a name holding the control characters
+// themselves can only unbalance the tags.
+const (
+	highlightOpen  = "\x02"
+	highlightClose = "\x03"
+)
+
+var highlightReplacer = strings.NewReplacer(highlightOpen, models.HighlightStart, highlightClose, models.HighlightEnd)
+
+// employeeSearchRow is an employee joined with its full-text match details
+type employeeSearchRow struct {
+	models.Employee
+	Rank              float64
+	NameHighlight     string
+	PositionHighlight string
+}
+
+// prepareSearchIndex creates the employees_fts index and fills it from the employees table.
+// The index only holds derived data, so it is rebuilt at startup instead of being migrated;
+// that also catches up with writes made by a build without FTS5.
+func (employeeDao *EmployeeDao) prepareSearchIndex(readOnly bool) error {
+	if !employeeDao.fullTextSearch {
+		log.Info("full-text search unavailable, employee search falls back to LIKE queries")
+		return nil
+	}
+	if readOnly {
+		employeeDao.fullTextSearch = employeeDao.db.Migrator().HasTable("employees_fts")
+		return nil
+	}
+	return employeeDao.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS employees_fts USING fts5(name, position, tokenize = 'unicode61 remove_diacritics 2')").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM employees_fts").Error; err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO employees_fts (rowid, name, position) SELECT id, name, position FROM employees WHERE deleted_at IS NULL").Error
+	})
+}
+
+// indexEmployees adds or replaces employees in the search index, within the caller's transaction
+func (employeeDao *EmployeeDao) indexEmployees(tx *gorm.DB, employees ...*models.Employee) error {
+	if !employeeDao.fullTextSearch {
+		return nil
+	}
+	for _, employee := range employees {
+		if err := tx.Exec("DELETE FROM employees_fts WHERE rowid = ?", employee.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("INSERT INTO employees_fts (rowid, name, position) VALUES (?, ?, ?)", employee.ID, employee.Name, employee.Position).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unindexEmployee removes an employee from the search index, within the caller's transaction
+func (employeeDao *EmployeeDao) unindexEmployee(tx *gorm.DB, id int64) error {
+	if !employeeDao.fullTextSearch {
+		return nil
+	}
+	return tx.Exec("DELETE FROM employees_fts WHERE rowid = ?", id).Error
+}
+
+// SearchEmployees ranks employees by how well their name and position match the query terms,
+// using the FTS5 index when there is one and LIKE patterns otherwise
+func (employeeDao *EmployeeDao) SearchEmployees(query *models.EmployeeSearchQuery) (*models.EmployeeSearchResult, error) {
+	if err := employeeDao.applyDueSalaryChanges(); err != nil {
+		log.Debugf("failed to search employees: %v", err)
+		return nil, err
+	}
+	if !employeeDao.fullTextSearch {
+		return employeeDao.searchEmployeesLike(query)
+	}
+
+	// every term is a quoted prefix query, so matching starts at word boundaries and user input
+	// cannot use the FTS5 query syntax
+	var match []string
+	for _, term := range query.Terms {
+		match = append(match, `"`+term+`"*`)
+	}
+	search := employeeDao.db.Table("employees_fts").
+		Select("rowid, bm25(employees_fts, 10.0, 5.0) AS rank, highlight(employees_fts, 0, ?, ?) AS name_highlight, highlight(employees_fts, 1, ?, ?) AS position_highlight",
+			highlightOpen, highlightClose, highlightOpen, highlightClose).
+		Where("employees_fts MATCH ?", strings.Join(match, " "))
+
+	var rows []*employeeSearchRow
+	db := employeeDao.db.Model(&models.Employee{}).
+		Select("employees.*, search.rank, search.name_highlight, search.position_highlight").
+		Joins("JOIN (?) AS search ON search.rowid = employees.id", search)
+	db = applyEmployeeFilter(db, &query.Filter)
+	if err := db.Order("search.rank, employees.id").Limit(query.Limit).Scan(&rows).Error; err != nil {
+		log.Debugf("failed to search employees: %v", err)
+		return nil, err
+	}
+
+	result := &models.EmployeeSearchResult{Engine: searchEngineFTS5, Hits: []*models.EmployeeSearchHit{}}
+	for _, row := range rows {
+		employee := row.Employee
+		hit := &models.EmployeeSearchHit{Employee: &employee, Score: -row.Rank, Highlights: map[string]string{}}
+		if strings.Contains(row.NameHighlight, highlightOpen) {
+			hit.Highlights["name"] = highlightReplacer.Replace(html.EscapeString(row.NameHighlight))
+		}
+		if strings.Contains(row.PositionHighlight, highlightOpen) {
+			hit.Highlights["position"] = highlightReplacer.Replace(html.EscapeString(row.PositionHighlight))
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	log.Debugf("employees searched")
+	return result, nil
+}
+
+// employeeLikeSearchRow is an employee with the rank of its LIKE matches
+type employeeLikeSearchRow struct {
+	models.Employee
+	SearchRank float64
+}
+
+// searchEmployeesLike is the fallback for databases without FTS5, every term has to appear
+// in the name or the position. The rank is computed in SQL, so the limit keeps the best matches.
+func (employeeDao *EmployeeDao) searchEmployeesLike(query *models.EmployeeSearchQuery) (*models.EmployeeSearchResult, error) {
+	db := applyEmployeeFilter(employeeDao.db.Model(&models.Employee{}), &query.Filter)
+	var rank []string
+	var rankArgs []interface{}
+	for _, term := range query.Terms {
+		escaped := likeEscaper.Replace(term)
+		pattern := "%" + escaped + "%"
+		db = db.Where(`(LOWER(name) LIKE ? ESCAPE '!' OR LOWER(position) LIKE ? ESCAPE '!')`, pattern, pattern)
+		for _, column := range []string{"name", "position"} {
+			weight := likeColumnWeights[column]
+			rank = append(rank, fmt.Sprintf(`CASE WHEN LOWER(%[1]s) = ? THEN %[2]d WHEN LOWER(%[1]s) LIKE ? ESCAPE '!' OR LOWER(%[1]s) LIKE ? ESCAPE '!' THEN %[3]d WHEN LOWER(%[1]s) LIKE ? ESCAPE '!' THEN %[4]d ELSE 0 END`,
+				column, weight*likeExactScore, weight*likeWordScore, weight*likeInfixScore))
+			rankArgs = append(rankArgs, term, escaped+"%", "% "+escaped+"%", pattern)
+		}
+	}
+
+	var rows []*employeeLikeSearchRow
+	db = db.Select("employees.*, ("+strings.Join(rank, " + ")+") AS search_rank", rankArgs...)
+	if err := db.Order("search_rank DESC, employees.id").Limit(query.Limit).Scan(&rows).Error; err != nil {
+		log.Debugf("failed to search employees: %v", err)
+		return nil, err
+	}
+
+	result := &models.EmployeeSearchResult{Engine: searchEngineLike, Hits: []*models.EmployeeSearchHit{}}
+	for _, row := range rows {
+		employee := row.Employee
+		result.Hits = append(result.Hits, likeSearchHit(&employee, query.Terms, row.SearchRank))
+	}
+	log.Debugf("employees searched")
+	return result, nil
+}
+
+// a term scores likeExactScore when it is the whole field, likeWordScore when it starts the field
+// or a word of it and likeInfixScore otherwise, times the weight of the field
+const (
+	likeExactScore = 4
+	likeWordScore  = 2
+	likeInfixScore = 1
+)
+
+var likeColumnWeights = map[string]int{"name": 2, "position": 1}
+
+// rankEmployees scores and highlights employees already known to match like the SQL fallback, best first
+func rankEmployees(employees []*models.Employee, query *models.EmployeeSearchQuery) *models.EmployeeSearchResult {
+	result := &models.EmployeeSearchResult{Engine: searchEngineLike, Hits: []*models.EmployeeSearchHit{}}
+	for _, employee := range employees {
+		score := 0
+		for _, term := range query.Terms {
+			score += likeColumnWeights["name"]*likeTermScore(employee.Name, term) + likeColumnWeights["position"]*likeTermScore(employee.Position, term)
+		}
+		result.Hits = append(result.Hits, likeSearchHit(employee, query.Terms, float64(score)))
+	}
+	sort.SliceStable(result.Hits, func(i, j int) bool {
+		return result.Hits[i].Score > result.Hits[j].Score
+	})
+	if len(result.Hits) > query.Limit {
+		result.Hits = result.Hits[:query.Limit]
+	}
+	return result
+}
+
+// likeTermScore scores one term against one field the way the CASE expressions of searchEmployeesLike do
+func likeTermScore(text, term string) int {
+	lower := strings.ToLower(text)
+	switch {
+	case lower == term:
+		return likeExactScore
+	case strings.HasPrefix(lower, term) || strings.Contains(lower, " "+term):
+		return likeWordScore
+	case strings.Contains(lower, term):
+		return likeInfixScore
+	}
+	return 0
+}
+
+// likeSearchHit highlights the terms in the name and position of a matching employee
+func likeSearchHit(employee *models.Employee, terms []string, score float64) *models.EmployeeSearchHit {
+	hit := &models.EmployeeSearchHit{Employee: employee, Score: score, Highlights: map[string]string{}}
+	if highlighted, ok := highlightTerms(employee.Name, terms); ok {
+		hit.Highlights["name"] = highlighted
+	}
+	if highlighted, ok := highlightTerms(employee.Position, terms); ok {
+		hit.Highlights["position"] = highlighted
+	}
+	return hit
+}
+
+// matchesSearchTerms reports whether every term appears in the name or the position
+func matchesSearchTerms(employee *models.Employee, terms []string) bool {
+	name, position := strings.ToLower(employee.Name), strings.ToLower(employee.Position)
+	for _, term := range terms {
+		if !strings.Contains(name, term) && !strings.Contains(position, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// highlightTerms wraps every case-insensitive occurrence of the terms in text, HTML-escaping the
+// rest, and reports whether there was one
+func highlightTerms(text string, terms []string) (string, bool) {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// lower casing changed the length, fall back to per rune lower casing
+		lower = make([]rune, len(runes))
+		for i, r := range runes {
+			lower[i] = unicode.ToLower(r)
+		}
+	}
+
+	marked := make([]bool, len(runes))
+	found := false
+	for _, term := range terms {
+		needle := []rune(term)
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) != term {
+				continue
+			}
+			found = true
+			for j := i; j < i+len(needle); j++ {
+				marked[j] = true
+			}
+		}
+	}
+
+	var highlighted strings.Builder
+	for i, r := range runes {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			highlighted.WriteString(models.HighlightStart)
+		}
+		highlighted.WriteString(html.EscapeString(string(r)))
+		if marked[i] && (i == len(runes)-1 || !marked[i+1]) {
+			highlighted.WriteString(models.HighlightEnd)
+		}
+	}
+	return highlighted.String(), found
+}
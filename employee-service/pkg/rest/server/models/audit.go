@@ -0,0 +1,145 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Audit operations, one per kind of change of an employee
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+)
+
+// AuditOperations lists every audit operation
+var AuditOperations = []string{AuditCreate, AuditUpdate, AuditDelete, AuditRestore, AuditPurge}
+
+// auditedFields are the fields of an employee whose changes are audited, in the order they are reported
+var auditedFields = []string{"name", "position", "salary", "currency", "department_id", "manager_id", "deleted_at"}
+
+// AuditContext tells who makes changes and as part of which request. Changes the service makes on its own,
+// like applying scheduled salaries, have no actor.
+type AuditContext struct {
+	Actor     string
+	RequestID string
+}
+
+// AuditEntry records one change of an employee. Entries are only ever added, they outlive purged employees.
+type AuditEntry struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	EmployeeID uint `json:"employee_id"`
+
+	// Operation is one of create, update, delete, restore and purge
+	Operation string `json:"operation" example:"update"`
+
+	Actor     string `json:"actor,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+
+	Changes AuditChanges `json:"changes"`
+}
+
+// AuditChange is a field of an employee before and after a change, null where the employee did not exist
+type AuditChange struct {
+	Field  string          `json:"field" example:"salary"`
+	Before json.RawMessage `json:"before" swaggertype:"object"`
+	After  json.RawMessage `json:"after" swaggertype:"object"`
+}
+
+// AuditChanges are the changed fields of an audit entry, stored as JSON
+type AuditChanges []*AuditChange
+
+func (changes AuditChanges) Value() (driver.Value, error) {
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (changes *AuditChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*changes = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), changes)
+	case []byte:
+		return json.Unmarshal(v, changes)
+	}
+	return fmt.Errorf("cannot scan %T into audit changes", value)
+}
+
+// NewAuditEntry records operation on an employee that was before and became after, before being nil for
+// creations and after for purges. Updates list the fields that changed, the others every field the employee has.
+func NewAuditEntry(audit AuditContext, operation string, before, after *Employee) *AuditEntry {
+	entry := &AuditEntry{Operation: operation, Actor: audit.Actor, RequestID: audit.RequestID, Changes: AuditChanges{}}
+	if after != nil {
+		entry.EmployeeID = after.ID
+	} else if before != nil {
+		entry.EmployeeID = before.ID
+	}
+	beforeFields, afterFields := auditFields(before), auditFields(after)
+	for _, field := range auditedFields {
+		if !bytes.Equal(beforeFields[field], afterFields[field]) {
+			entry.Changes = append(entry.Changes, &AuditChange{Field: field, Before: beforeFields[field], After: afterFields[field]})
+		}
+	}
+	return entry
+}
+
+// auditFields maps the audited fields of employee to their JSON values, all of them null for no employee
+func auditFields(employee *Employee) map[string]json.RawMessage {
+	fields := make(map[string]json.RawMessage, len(auditedFields))
+	if employee == nil {
+		for _, field := range auditedFields {
+			fields[field] = json.RawMessage("null")
+		}
+		return fields
+	}
+	var deletedAt *time.Time
+	if employee.DeletedAt.Valid {
+		utc := employee.DeletedAt.Time.UTC()
+		deletedAt = &utc
+	}
+	fields["name"] = auditValue(employee.Name)
+	fields["position"] = auditValue(employee.Position)
+	fields["salary"] = auditValue(employee.Salary)
+	fields["currency"] = auditValue(employee.Currency)
+	fields["department_id"] = auditValue(employee.DepartmentID)
+	fields["manager_id"] = auditValue(employee.ManagerID)
+	fields["deleted_at"] = auditValue(deletedAt)
+	return fields
+}
+
+// auditValue encodes the value of an audited field, which never fails for their types
+func auditValue(value interface{}) json.RawMessage {
+	data, _ := json.Marshal(value)
+	return data
+}
+
+// AuditQuery describes a page of the audit trail, oldest entries first. Empty fields are ignored.
+type AuditQuery struct {
+	EmployeeIDs []uint
+	Actor       string
+	Operations  []string
+	RequestID   string
+	// From and To are inclusive bounds of the time of the change
+	From  *time.Time
+	To    *time.Time
+	Page  int
+	Limit int
+}
+
+// AuditPage is a page of the audit trail with the number of entries matching the query
+type AuditPage struct {
+	Entries []*AuditEntry
+	Total   int64
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrDepartmentNotEmpty means a department still has employees, who have to be reassigned before it can be deleted
+var ErrDepartmentNotEmpty = errors.New("department has employees")
+
+// Department is an organizational unit employees belong to. Departments are deleted for good, there is no restore.
+type Department struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Name string `json:"name,omitempty"`
+
+	Description string `json:"description,omitempty"`
+}
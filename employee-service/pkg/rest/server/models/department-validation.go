@@ -0,0 +1,15 @@
+package models
+
+// departmentRules are the validation rules of the fields of a department, see employeeRules
+type departmentRules struct {
+	Name        string `json:"name" validate:"required,notblank,max=100"`
+	Description string `json:"description" validate:"max=1000"`
+}
+
+// Validate checks the name and description of department and returns a *ValidationError listing every invalid field
+func (department *Department) Validate() error {
+	return validationErrorOf(rulesValidator.Struct(&departmentRules{
+		Name:        department.Name,
+		Description: department.Description,
+	}), ErrInvalidDepartment)
+}
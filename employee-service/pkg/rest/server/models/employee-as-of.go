@@ -0,0 +1,84 @@
+package models
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"gorm.io/gorm"
+	"slices"
+	"time"
+)
+
+// EmployeeAsOf takes employee back to how it was at asOf, given the audit entries of the employee made since then
+// and the last entry before then that bumped its version, nil for none. It returns false for an employee that did
+// not exist yet or was deleted at asOf.
+//
+// The entries made since are undone newest first, restoring the values their fields had before. Changes made before
+// the audit trail existed cannot be undone, such employees go back to the way the trail found them at most.
+func EmployeeAsOf(employee *Employee, since []*AuditEntry, last *AuditEntry, asOf time.Time) (*Employee, bool, error) {
+	if employee.CreatedAt.After(asOf) {
+		return nil, false, nil
+	}
+	rewound := *employee
+	since = slices.Clone(since)
+	slices.SortFunc(since, func(a, b *AuditEntry) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
+	bumped := false
+	for _, entry := range since {
+		// the employee exists by its creation time already, however late its creation was recorded
+		if entry.Operation == AuditCreate {
+			continue
+		}
+		for _, change := range entry.Changes {
+			if err := rewound.setAuditedField(change.Field, change.Before); err != nil {
+				return nil, false, fmt.Errorf("audit entry %d: %w", entry.ID, err)
+			}
+		}
+		if entry.Operation == AuditUpdate || entry.Operation == AuditRestore {
+			rewound.Version--
+			bumped = true
+		}
+	}
+	if bumped {
+		rewound.UpdatedAt = rewound.CreatedAt
+		if last != nil && last.Operation != AuditCreate {
+			rewound.UpdatedAt = last.CreatedAt
+		}
+	}
+	if rewound.DeletedAt.Valid {
+		return nil, false, nil
+	}
+	return &rewound, true, nil
+}
+
+// setAuditedField sets one of the audited fields to its value in an audit change
+func (employee *Employee) setAuditedField(field string, value json.RawMessage) error {
+	switch field {
+	case "name":
+		return json.Unmarshal(value, &employee.Name)
+	case "position":
+		return json.Unmarshal(value, &employee.Position)
+	case "salary":
+		return json.Unmarshal(value, &employee.Salary.Decimal)
+	case "currency":
+		return json.Unmarshal(value, &employee.Currency)
+	case "department_id":
+		employee.DepartmentID = nil
+		return json.Unmarshal(value, &employee.DepartmentID)
+	case "manager_id":
+		employee.ManagerID = nil
+		return json.Unmarshal(value, &employee.ManagerID)
+	case "deleted_at":
+		var deletedAt *time.Time
+		if err := json.Unmarshal(value, &deletedAt); err != nil {
+			return err
+		}
+		employee.DeletedAt = gorm.DeletedAt{}
+		if deletedAt != nil {
+			employee.DeletedAt = gorm.DeletedAt{Time: *deletedAt, Valid: true}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown audited field %q", field)
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxEmployeeBatchOperations bounds the size of a single batch request
+const MaxEmployeeBatchOperations = 1000
+
+var ErrInvalidBatchOperation = errors.New("invalid batch operation")
+
+// EmployeeBatchOp names what a batch operation does
+type EmployeeBatchOp string
+
+const (
+	EmployeeBatchCreate EmployeeBatchOp = "create"
+	EmployeeBatchUpdate EmployeeBatchOp = "update"
+	EmployeeBatchDelete EmployeeBatchOp = "delete"
+)
+
+// EmployeeBatchStatus tells what became of a batch operation
+type EmployeeBatchStatus string
+
+const (
+	EmployeeBatchApplied EmployeeBatchStatus = "applied"
+	EmployeeBatchFailed  EmployeeBatchStatus = "failed"
+	// EmployeeBatchRolledBack operations were applied, then undone because a later operation of an atomic batch failed
+	EmployeeBatchRolledBack EmployeeBatchStatus = "rolled_back"
+	// EmployeeBatchSkipped operations were not attempted because an earlier operation of an atomic batch failed
+	EmployeeBatchSkipped EmployeeBatchStatus = "skipped"
+)
+
+// EmployeeBatchOperation is one create, update or delete of a batch
+type EmployeeBatchOperation struct {
+	Op EmployeeBatchOp `json:"op" enums:"create,update,delete"`
+	// ID of the employee to update or delete
+	ID uint `json:"id,omitempty"`
+	// Version, when set, has to be the stored version of the employee to update or delete
+	Version uint `json:"version,omitempty"`
+	// Employee holds the new fields for create and update
+	Employee *Employee `json:"employee,omitempty"`
+}
+
+// EmployeeBatchResult is the outcome of the operation at Index
+type EmployeeBatchResult struct {
+	Index  int
+	Op     EmployeeBatchOp
+	Status EmployeeBatchStatus
+	// Employee is the created or updated employee
+	Employee *Employee
+	// Err is why the operation failed
+	Err error
+}
+
+// Validate checks that the operation carries what its op needs and a valid employee, and aligns the employee with ID and Version
+func (operation *EmployeeBatchOperation) Validate() error {
+	switch operation.Op {
+	case EmployeeBatchCreate:
+		if operation.Employee == nil {
+			return fmt.Errorf("%w: create needs an employee", ErrInvalidBatchOperation)
+		}
+		if operation.ID != 0 {
+			return fmt.Errorf("%w: create takes no id", ErrInvalidBatchOperation)
+		}
+	case EmployeeBatchUpdate:
+		if operation.ID == 0 || operation.Employee == nil {
+			return fmt.Errorf("%w: update needs an id and an employee", ErrInvalidBatchOperation)
+		}
+		if operation.Employee.ID != 0 && operation.Employee.ID != operation.ID {
+			return fmt.Errorf("%w: id and employee don't match", ErrInvalidBatchOperation)
+		}
+		operation.Employee.ID = operation.ID
+		if operation.Version != 0 {
+			operation.Employee.Version = operation.Version
+		}
+	case EmployeeBatchDelete:
+		if operation.ID == 0 {
+			return fmt.Errorf("%w: delete needs an id", ErrInvalidBatchOperation)
+		}
+	default:
+		return fmt.Errorf("%w: unknown op %q, expected create, update or delete", ErrInvalidBatchOperation, operation.Op)
+	}
+	if operation.Op != EmployeeBatchDelete {
+		return operation.Employee.Validate()
+	}
+	return nil
+}
+
+// NewEmployeeBatchResults prepares one skipped result per operation
+func NewEmployeeBatchResults(operations []*EmployeeBatchOperation) []*EmployeeBatchResult {
+	results := make([]*EmployeeBatchResult, 0, len(operations))
+	for i, operation := range operations {
+		results = append(results, &EmployeeBatchResult{Index: i, Op: operation.Op, Status: EmployeeBatchSkipped})
+	}
+	return results
+}
+
+// RollBackEmployeeBatch marks the applied results as rolled back after an atomic batch failed
+func RollBackEmployeeBatch(results []*EmployeeBatchResult) {
+	for _, result := range results {
+		if result.Status == EmployeeBatchApplied {
+			result.Status = EmployeeBatchRolledBack
+			result.Employee = nil
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EmployeeCursor is a keyset position in a sorted employee listing.
+// It holds the sort values and the id of the row it points at, so that the next page starts
+// right after (or before) that row however many rows were inserted or deleted in between.
+type EmployeeCursor struct {
+	// Sort is the canonical sort the cursor was created for, see EmployeeSortString
+	Sort string `json:"s"`
+	// Values holds the sort field values of the row, in sort order
+	Values []json.RawMessage `json:"v"`
+	// ID breaks ties between rows with equal sort values
+	ID uint `json:"id"`
+	// Backward asks for the rows before the position instead of after it
+	Backward bool `json:"b,omitempty"`
+}
+
+// EmployeePage is one page of an employee listing
+type EmployeePage struct {
+	Employees []*Employee
+	// Total counts every employee matching the filter
+	Total int64
+	// HasNext and HasPrev tell whether there are rows after the last and before the first employee
+	HasNext bool
+	HasPrev bool
+}
+
+// EmployeeSortString renders sort canonically, e.g. "-salary,name"
+func EmployeeSortString(sort []EmployeeSort) string {
+	fields := make([]string, 0, len(sort))
+	for _, employeeSort := range sort {
+		if employeeSort.Desc {
+			fields = append(fields, "-"+employeeSort.Field)
+		} else {
+			fields = append(fields, employeeSort.Field)
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// EffectiveEmployeeSort appends id as the final tie breaker unless sort already contains it,
+// giving every listing a total order
+func EffectiveEmployeeSort(sort []EmployeeSort) []EmployeeSort {
+	for _, employeeSort := range sort {
+		if employeeSort.Field == "id" {
+			return sort
+		}
+	}
+	effective := make([]EmployeeSort, 0, len(sort)+1)
+	effective = append(effective, sort...)
+	return append(effective, EmployeeSort{Field: "id"})
+}
+
+// ReverseEmployeeSort flips the direction of every field, used to walk a listing backwards
+func ReverseEmployeeSort(sort []EmployeeSort) []EmployeeSort {
+	reversed := make([]EmployeeSort, 0, len(sort))
+	for _, employeeSort := range sort {
+		reversed = append(reversed, EmployeeSort{Field: employeeSort.Field, Desc: !employeeSort.Desc})
+	}
+	return reversed
+}
+
+// NewEmployeeCursor points at employee within a listing sorted by sort
+func NewEmployeeCursor(employee *Employee, sort []EmployeeSort, backward bool) *EmployeeCursor {
+	cursor := &EmployeeCursor{
+		Sort:     EmployeeSortString(sort),
+		ID:       employee.ID,
+		Backward: backward,
+	}
+	for _, employeeSort := range sort {
+		value, _ := json.Marshal(EmployeeSortValue(employee, employeeSort.Field))
+		cursor.Values = append(cursor.Values, value)
+	}
+	return cursor
+}
+
+// EmployeeSortValue returns the value of a whitelisted sort field
+func EmployeeSortValue(employee *Employee, field string) interface{} {
+	switch field {
+	case "id":
+		return employee.ID
+	case "name":
+		return employee.Name
+	case "position":
+		return employee.Position
+	case "salary":
+		return employee.Salary
+	case "created_at":
+		return employee.CreatedAt
+	case "updated_at":
+		return employee.UpdatedAt
+	}
+	return nil
+}
+
+// NewEmployeeStartCursor points before the first row of a listing sorted by sort
+func NewEmployeeStartCursor(sort []EmployeeSort) *EmployeeCursor {
+	return &EmployeeCursor{Sort: EmployeeSortString(sort)}
+}
+
+// IsStart reports whether the cursor points before the first row
+func (cursor *EmployeeCursor) IsStart() bool {
+	return cursor.ID == 0 && len(cursor.Values) == 0 && !cursor.Backward
+}
+
+// Employee returns an employee holding the cursor's sort values, to compare rows against
+func (cursor *EmployeeCursor) Employee(sort []EmployeeSort) (*Employee, error) {
+	if cursor.Sort != EmployeeSortString(sort) || len(cursor.Values) != len(sort) {
+		return nil, ErrInvalidCursor
+	}
+	employee := &Employee{}
+	employee.ID = cursor.ID
+	for i, employeeSort := range sort {
+		var err error
+		switch employeeSort.Field {
+		case "id":
+			err = json.Unmarshal(cursor.Values[i], &employee.ID)
+		case "name":
+			err = json.Unmarshal(cursor.Values[i], &employee.Name)
+		case "position":
+			err = json.Unmarshal(cursor.Values[i], &employee.Position)
+		case "salary":
+			err = json.Unmarshal(cursor.Values[i], &employee.Salary)
+		case "created_at":
+			err = json.Unmarshal(cursor.Values[i], &employee.CreatedAt)
+		case "updated_at":
+			err = json.Unmarshal(cursor.Values[i], &employee.UpdatedAt)
+		default:
+			err = ErrInvalidCursor
+		}
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+	}
+	employee.CreatedAt = employee.CreatedAt.UTC()
+	employee.UpdatedAt = employee.UpdatedAt.UTC()
+	return employee, nil
+}
+
+// Encode returns the opaque token handed out to clients
+func (cursor *EmployeeCursor) Encode() string {
+	token, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+// DecodeEmployeeCursor parses a token created by Encode
+func DecodeEmployeeCursor(token string) (*EmployeeCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor EmployeeCursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MaxManagementDepth bounds how many levels of managers are followed, so that a cycle
+// stored before cycles were rejected cannot send a query around forever
+const MaxManagementDepth = 100
+
+// OrgChartNode is an employee in an org chart with the employees reporting to them
+type OrgChartNode struct {
+	ID           uint            `json:"id"`
+	Name         string          `json:"name"`
+	Position     string          `json:"position,omitempty"`
+	DepartmentID *uint           `json:"department_id,omitempty"`
+	Reports      []*OrgChartNode `json:"reports,omitempty"`
+}
+
+// NewOrgChart arranges employees as trees by their managers. Employees whose manager is not
+// among them are at the top, reports keep the order of employees.
+func NewOrgChart(employees []*Employee) []*OrgChartNode {
+	nodes := make(map[uint]*OrgChartNode, len(employees))
+	for _, employee := range employees {
+		nodes[employee.ID] = &OrgChartNode{
+			ID:           employee.ID,
+			Name:         employee.Name,
+			Position:     employee.Position,
+			DepartmentID: employee.DepartmentID,
+		}
+	}
+	var roots []*OrgChartNode
+	for _, employee := range employees {
+		node := nodes[employee.ID]
+		if employee.ManagerID != nil {
+			if manager, ok := nodes[*employee.ManagerID]; ok && *employee.ManagerID != employee.ID {
+				manager.Reports = append(manager.Reports, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// ValidateManager fails with a *ValidationError when employee id reporting to managerID would close a cycle,
+// chain being the management chain of managerID
+func ValidateManager(id int64, managerID uint, chain []uint) error {
+	if int64(managerID) == id {
+		return NewFieldValidationError("manager_id", FieldErrorManagerCycle, "an employee cannot be their own manager")
+	}
+	if slices.Contains(chain, uint(id)) {
+		return NewFieldValidationError("manager_id", FieldErrorManagerCycle, fmt.Sprintf("employee %d reports to employee %d already", managerID, id))
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+	"io"
+)
+
+// MaxEmployeeImportSize bounds the size of an uploaded import file
+const MaxEmployeeImportSize = 10 << 20
+
+// MaxEmployeeImportRows bounds the number of data rows a single import may have
+const MaxEmployeeImportRows = 10000
+
+// ErrInvalidImport means the file or the column mapping cannot be used at all, as opposed to single invalid rows
+var ErrInvalidImport = errors.New("invalid import")
+
+// ErrReadOnlyImportField means the file sets a field the caller may not, see EmployeeImportRequest.ReadOnlyFields
+var ErrReadOnlyImportField = errors.New("read-only import field")
+
+// EmployeeImportFields lists the employee fields file columns can be mapped to
+var EmployeeImportFields = []string{"name", "position", "salary", "currency", "department_id", "manager_id"}
+
+// FormulaPrefixes are the first characters that make spreadsheet applications evaluate a cell.
+// Exports put a quote in front of text starting with one of them, imports drop it again.
+const FormulaPrefixes = "=+-@\t\r"
+
+// EmployeeImportFormat is the file format of an import
+type EmployeeImportFormat string
+
+const (
+	EmployeeImportCSV  EmployeeImportFormat = "csv"
+	EmployeeImportXLSX EmployeeImportFormat = "xlsx"
+)
+
+// EmployeeImportRequest describes a spreadsheet of employees, one per row below a header row
+type EmployeeImportRequest struct {
+	Format EmployeeImportFormat
+	File   io.Reader
+	// Sheet is the XLSX sheet to read, the first one when empty
+	Sheet string
+	// Mapping maps header names of the file to EmployeeImportFields, case-insensitive.
+	// Without a mapping, headers named like the fields are used.
+	Mapping map[string]string
+	// Commit stores the employees when every row is valid, otherwise the import is a dry run
+	Commit bool
+	// ReadOnlyFields are fields the caller may not set, a file with a value for one of them is refused
+	ReadOnlyFields []string
+}
+
+// EmployeeImportRowError tells why a row of an import is invalid
+type EmployeeImportRowError struct {
+	// Row is the line of the row in the file, the header being row 1
+	Row    int    `json:"row"`
+	Column string `json:"column,omitempty"`
+	// Code is one of the codes of FieldError
+	Code  string `json:"code,omitempty"`
+	Error string `json:"error"`
+}
+
+// EmployeeImportResult reports what an import found and did
+type EmployeeImportResult struct {
+	// Rows counts the non-empty data rows
+	Rows      int                       `json:"rows"`
+	Valid     int                       `json:"valid"`
+	Invalid   int                       `json:"invalid"`
+	Errors    []*EmployeeImportRowError `json:"errors"`
+	Committed bool                      `json:"committed"`
+	// Created counts the stored employees, 0 for dry runs and imports with invalid rows
+	Created int `json:"created"`
+}
@@ -8,5 +8,19 @@ type Employee struct {
 
 	Position string `json:"position,omitempty"`
 
-	Salary float64 `json:"salary,omitempty"`
+	// Salary is null for callers without the salaries:read permission, an update with a null salary keeps the
+	// stored salary and currency
+	Salary Amount `json:"salary" swaggertype:"string" example:"76000.25"`
+
+	// Currency is the ISO 4217 code of the salary, USD unless given on creation and kept by updates without one
+	Currency string `json:"currency,omitempty" example:"USD"`
+
+	// DepartmentID is the department the employee belongs to, if any
+	DepartmentID *uint `json:"department_id,omitempty"`
+
+	// ManagerID is the employee this one reports to, none for the top of the organization
+	ManagerID *uint `json:"manager_id,omitempty"`
+
+	// Version is bumped on every write and sent as the ETag, a non-zero version in an update must match the stored one
+	Version uint `json:"version,omitempty" gorm:"not null;default:1"`
 }
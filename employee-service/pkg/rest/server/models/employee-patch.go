@@ -0,0 +1,185 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"strings"
+)
+
+const (
+	// MergePatchContentType is a JSON Merge Patch (RFC 7396)
+	MergePatchContentType = "application/merge-patch+json"
+	// JSONPatchContentType is a JSON Patch (RFC 6902)
+	JSONPatchContentType = "application/json-patch+json"
+)
+
+var (
+	// ErrInvalidPatch means the patch document is malformed
+	ErrInvalidPatch = errors.New("invalid patch document")
+	// ErrPatchTestFailed means a JSON Patch test operation did not hold for the stored employee
+	ErrPatchTestFailed = errors.New("patch test failed")
+	// ErrUnprocessablePatch means the patch is well formed but does not fit an employee,
+	// e.g. it targets an unknown or read-only field or sets a value of the wrong type
+	ErrUnprocessablePatch = errors.New("patch cannot be applied")
+)
+
+// EmployeePatch is a partial update of an employee in one of the supported patch formats
+type EmployeePatch struct {
+	// ContentType is MergePatchContentType or JSONPatchContentType
+	ContentType string
+	Document    []byte
+}
+
+// employeePatchDocument is the part of an employee a patch works on.
+// Removing a field resets it to its zero value, which is DefaultCurrency for the currency.
+type employeePatchDocument struct {
+	Name     string `json:"name"`
+	Position string `json:"position"`
+	Salary   Amount `json:"salary"`
+	Currency string `json:"currency"`
+	// DepartmentID is null for employees outside of any department
+	DepartmentID *uint `json:"department_id"`
+	ManagerID    *uint `json:"manager_id"`
+}
+
+// employeePatchFields are the JSON names of the fields of employeePatchDocument
+var employeePatchFields = []string{"name", "position", "salary", "currency", "department_id", "manager_id"}
+
+// Fields returns the fields of the employee the patch writes and those it reads, by JSON Patch test, copy and move
+// operations. A patch of the whole document names every field, a malformed one none as applying it fails anyway.
+func (patch *EmployeePatch) Fields() (written, read []string) {
+	switch patch.ContentType {
+	case MergePatchContentType:
+		if !json.Valid(patch.Document) {
+			return nil, nil
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(patch.Document, &fields); err != nil {
+			// anything but an object replaces the whole document
+			return employeePatchFields, nil
+		}
+		for field := range fields {
+			written = append(written, field)
+		}
+	case JSONPatchContentType:
+		operations, err := jsonpatch.DecodePatch(patch.Document)
+		if err != nil {
+			return nil, nil
+		}
+		for _, operation := range operations {
+			path, _ := operation.Path()
+			from, _ := operation.From()
+			switch operation.Kind() {
+			case "test":
+				read = append(read, patchPathFields(path)...)
+			case "copy":
+				read = append(read, patchPathFields(from)...)
+				written = append(written, patchPathFields(path)...)
+			case "move":
+				read = append(read, patchPathFields(from)...)
+				written = append(append(written, patchPathFields(from)...), patchPathFields(path)...)
+			default:
+				written = append(written, patchPathFields(path)...)
+			}
+		}
+	}
+	return written, read
+}
+
+// patchPathFields returns the field a JSON Pointer points into, every field for the whole document
+func patchPathFields(pointer string) []string {
+	if len(pointer) == 0 {
+		return employeePatchFields
+	}
+	field, _, _ := strings.Cut(strings.TrimPrefix(pointer, "/"), "/")
+	return []string{strings.NewReplacer("~1", "/", "~0", "~").Replace(field)}
+}
+
+// Apply patches employee in place and returns the changed columns with their new values
+func (patch *EmployeePatch) Apply(employee *Employee) (map[string]interface{}, error) {
+	original, err := json.Marshal(employeePatchDocument{
+		Name:         employee.Name,
+		Position:     employee.Position,
+		Salary:       employee.Salary,
+		Currency:     employee.Currency,
+		DepartmentID: employee.DepartmentID,
+		ManagerID:    employee.ManagerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch patch.ContentType {
+	case MergePatchContentType:
+		if patched, err = jsonpatch.MergePatch(original, patch.Document); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+	case JSONPatchContentType:
+		operations, err := jsonpatch.DecodePatch(patch.Document)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		if patched, err = operations.Apply(original); err != nil {
+			if errors.Is(err, jsonpatch.ErrTestFailed) {
+				return nil, fmt.Errorf("%w: %v", ErrPatchTestFailed, err)
+			}
+			return nil, fmt.Errorf("%w: %v", ErrUnprocessablePatch, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported content type %q", ErrInvalidPatch, patch.ContentType)
+	}
+
+	var document employeePatchDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnprocessablePatch, FieldTypeError(err))
+	}
+
+	// a null salary resets it like removing it does
+	if document.Salary.masked {
+		document.Salary = Amount{}
+	}
+
+	changes := map[string]interface{}{}
+	if document.Name != employee.Name {
+		employee.Name = document.Name
+		changes["name"] = document.Name
+	}
+	if document.Position != employee.Position {
+		employee.Position = document.Position
+		changes["position"] = document.Position
+	}
+	if len(document.Salary.invalid) > 0 || !document.Salary.Equal(employee.Salary.Decimal) {
+		employee.Salary = document.Salary
+		changes["salary"] = document.Salary
+	}
+	if len(document.Currency) == 0 {
+		document.Currency = DefaultCurrency
+	}
+	if document.Currency != employee.Currency {
+		employee.Currency = document.Currency
+		changes["currency"] = document.Currency
+	}
+	if !SameID(document.DepartmentID, employee.DepartmentID) {
+		employee.DepartmentID = document.DepartmentID
+		changes["department_id"] = document.DepartmentID
+	}
+	if !SameID(document.ManagerID, employee.ManagerID) {
+		employee.ManagerID = document.ManagerID
+		changes["manager_id"] = document.ManagerID
+	}
+	return changes, nil
+}
+
+// SameID reports whether two optional references name the same row, or are both unset
+func SameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// EmployeeSortColumns whitelists the fields employees can be sorted by, mapped to their columns
+var EmployeeSortColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"position":   "position",
+	"salary":     "salary",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// EmployeeFilter narrows down a listing of employees, empty fields are ignored
+type EmployeeFilter struct {
+	// NamePrefix matches names starting with it, case-insensitive
+	NamePrefix string
+	// NameContains matches names containing it, case-insensitive
+	NameContains string
+	// Positions matches any of the given positions exactly
+	Positions []string
+	// DepartmentIDs matches employees of any of the given departments
+	DepartmentIDs []uint
+	// ManagerIDs matches the direct reports of any of the given managers
+	ManagerIDs []uint
+	// Currencies matches salaries in any of the given currencies
+	Currencies []string
+	// SalaryMin and SalaryMax are inclusive bounds, compared by amount whatever the currency
+	SalaryMin *Amount
+	SalaryMax *Amount
+	// CreatedFrom, CreatedTo, UpdatedFrom and UpdatedTo are inclusive bounds
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+}
+
+// EmployeeSort orders a listing by one whitelisted field
+type EmployeeSort struct {
+	Field string
+	Desc  bool
+}
+
+// EmployeeQuery describes a page of a filtered and sorted employee listing.
+// Pages are addressed by Page, or by Cursor when keyset pagination is used.
+type EmployeeQuery struct {
+	Filter EmployeeFilter
+	Sort   []EmployeeSort
+	Page   int
+	Limit  int
+	Cursor *EmployeeCursor
+	// AsOf lists the employees as they were at that time instead of now, addressed by Page only
+	AsOf *time.Time
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// SensitiveEmployeeFields are the fields of employees that only callers allowed to read salaries see,
+// the others get them masked by Redact, which has to mask any field added here
+var SensitiveEmployeeFields = []string{"salary"}
+
+// SalaryFields are the fields of employees that only callers allowed to write salaries set
+var SalaryFields = []string{"salary", "currency"}
+
+// Redact returns a copy of employee with the sensitive fields masked
+func (employee *Employee) Redact() *Employee {
+	redacted := *employee
+	redacted.Salary = Amount{masked: true}
+	return &redacted
+}
+
+// ResolveMaskedSalary replaces a masked salary, the null shown to callers without salaries:read, before employee
+// is written: an update keeps the salary and the currency of stored, a creation, with a nil stored, starts at zero
+func (employee *Employee) ResolveMaskedSalary(stored *Employee) {
+	if !employee.Salary.masked {
+		return
+	}
+	if stored == nil {
+		employee.Salary = Amount{}
+		return
+	}
+	employee.Salary, employee.Currency = stored.Salary, stored.Currency
+}
+
+// Redact returns a copy of entry with the values of the sensitive fields it changed masked, the changes are kept
+// to tell that they happened
+func (entry *AuditEntry) Redact() *AuditEntry {
+	redacted := *entry
+	redacted.Changes = make(AuditChanges, 0, len(entry.Changes))
+	for _, change := range entry.Changes {
+		if slices.Contains(SensitiveEmployeeFields, change.Field) {
+			change = &AuditChange{Field: change.Field, Before: json.RawMessage("null"), After: json.RawMessage("null")}
+		}
+		redacted.Changes = append(redacted.Changes, change)
+	}
+	return &redacted
+}
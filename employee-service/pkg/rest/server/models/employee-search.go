@@ -0,0 +1,59 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// HighlightStart and HighlightEnd surround the matched fragments of search highlights
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+
+	maxSearchTerms = 10
+)
+
+// EmployeeSearchQuery asks for the employees best matching Text by name and position
+type EmployeeSearchQuery struct {
+	// Terms are the normalised words of the search text, see SearchTerms
+	Terms  []string
+	Filter EmployeeFilter
+	Limit  int
+}
+
+// EmployeeSearchHit is one ranked search match
+type EmployeeSearchHit struct {
+	Employee *Employee `json:"employee"`
+	// Score is higher for better matches, only comparable within one result
+	Score float64 `json:"score"`
+	// Highlights holds the matched fields as HTML, every matched fragment wrapped in <mark></mark>
+	// and the text HTML-escaped
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+// EmployeeSearchResult holds the hits and the engine that produced them
+type EmployeeSearchResult struct {
+	Hits []*EmployeeSearchHit
+	// Engine is "fts5" when a full-text index was used and "like" for the pattern matching fallback
+	Engine string
+}
+
+// SearchTerms splits text into lower case words, dropping punctuation and duplicates,
+// so that user input never reaches the full-text query syntax as is
+func SearchTerms(text string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, term := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
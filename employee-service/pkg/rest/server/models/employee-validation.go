@@ -0,0 +1,234 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/go-playground/validator/v10/non-standard/validators"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// EmployeePositions is the catalog of positions an employee may hold, an empty position is allowed too
+var EmployeePositions = []string{
+	"Accountant",
+	"Architect",
+	"Customer Service Representative",
+	"Human Resources Manager",
+	"Marketing Specialist",
+	"Senior Accountant",
+	"Senior Software Developer",
+	"Software Developer",
+}
+
+// ErrInvalidEmployee and ErrInvalidDepartment are wrapped by the ValidationError of an employee or a department,
+// ErrInvalidSalaryChange by the one of a salary change
+var (
+	ErrInvalidEmployee   = errors.New("invalid employee")
+	ErrInvalidDepartment = errors.New("invalid department")
+)
+
+// Field error codes, stable for clients to switch on
+const (
+	FieldErrorRequired        = "required"
+	FieldErrorTooLong         = "too_long"
+	FieldErrorTooSmall        = "too_small"
+	FieldErrorUnknownPosition = "unknown_position"
+	// FieldErrorUnknownCurrency is a currency that is not an ISO 4217 code
+	FieldErrorUnknownCurrency = "unknown_currency"
+	// FieldErrorUnknownDepartment refers to a department that does not exist
+	FieldErrorUnknownDepartment = "unknown_department"
+	// FieldErrorUnknownManager refers to an employee that does not exist or was deleted
+	FieldErrorUnknownManager = "unknown_manager"
+	// FieldErrorManagerCycle would make an employee report to themselves, directly or through others
+	FieldErrorManagerCycle = "manager_cycle"
+	FieldErrorInvalidType  = "invalid_type"
+	FieldErrorInvalid      = "invalid"
+)
+
+// FieldError tells what is wrong with one field of an employee, a department or a salary change
+type FieldError struct {
+	// Field is the JSON name of the field
+	Field string `json:"field"`
+	// Code is one of required, too_long, too_small, unknown_position, unknown_currency, unknown_department, unknown_manager, manager_cycle,
+	// invalid_type and invalid
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid field of an employee, a department or a salary change
+type ValidationError struct {
+	Errors []*FieldError
+	// subject is ErrInvalidDepartment or ErrInvalidSalaryChange, employees leave it nil
+	subject error
+}
+
+func (validationError *ValidationError) Error() string {
+	messages := make([]string, 0, len(validationError.Errors))
+	for _, fieldError := range validationError.Errors {
+		messages = append(messages, fieldError.Message)
+	}
+	return validationError.Unwrap().Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (validationError *ValidationError) Unwrap() error {
+	if validationError.subject != nil {
+		return validationError.subject
+	}
+	return ErrInvalidEmployee
+}
+
+// NewFieldValidationError reports a single invalid field of an employee
+func NewFieldValidationError(field, code, message string) *ValidationError {
+	return &ValidationError{Errors: []*FieldError{{Field: field, Code: code, Message: message}}}
+}
+
+// NewDepartmentFieldValidationError reports a single invalid field of a department
+func NewDepartmentFieldValidationError(field, code, message string) *ValidationError {
+	return &ValidationError{Errors: []*FieldError{{Field: field, Code: code, Message: message}}, subject: ErrInvalidDepartment}
+}
+
+// employeeRules are the validation rules of the fields of an employee, kept apart from Employee
+// so gin's binding does not pick them up. Lengths are counted in characters.
+type employeeRules struct {
+	Name     string `json:"name" validate:"required,notblank,max=100"`
+	Position string `json:"position" validate:"omitempty,max=100,position"`
+	Salary   string `json:"salary" validate:"decimal,nonnegative,amount"`
+	Currency string `json:"currency" validate:"omitempty,iso4217"`
+}
+
+var rulesValidator = newRulesValidator()
+
+func newRulesValidator() *validator.Validate {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	})
+	if err := validate.RegisterValidation("notblank", validators.NotBlank); err != nil {
+		panic(err)
+	}
+	if err := validate.RegisterValidation("position", func(field validator.FieldLevel) bool {
+		return slices.Contains(EmployeePositions, field.Field().String())
+	}); err != nil {
+		panic(err)
+	}
+	if err := validate.RegisterValidation("decimal", func(field validator.FieldLevel) bool {
+		_, err := NewAmount(field.Field().String())
+		return err == nil
+	}); err != nil {
+		panic(err)
+	}
+	if err := validate.RegisterValidation("nonnegative", func(field validator.FieldLevel) bool {
+		amount, err := NewAmount(field.Field().String())
+		return err == nil && !amount.IsNegative()
+	}); err != nil {
+		panic(err)
+	}
+	if err := validate.RegisterValidation("amount", func(field validator.FieldLevel) bool {
+		amount, err := NewAmount(field.Field().String())
+		return err == nil && amount.Valid()
+	}); err != nil {
+		panic(err)
+	}
+	if err := validate.RegisterValidation("date", func(field validator.FieldLevel) bool {
+		return Date(field.Field().String()).Valid()
+	}); err != nil {
+		panic(err)
+	}
+	return validate
+}
+
+// Validate checks employee against the rules for names, positions, salaries and currencies and returns a *ValidationError
+// listing every invalid field
+func (employee *Employee) Validate() error {
+	return validationErrorOf(rulesValidator.Struct(&employeeRules{
+		Name:     employee.Name,
+		Position: employee.Position,
+		Salary:   employee.Salary.text(),
+		Currency: employee.Currency,
+	}), nil)
+}
+
+// ValidateFields is Validate restricted to the errors of the given JSON fields
+func (employee *Employee) ValidateFields(fields ...string) error {
+	err := employee.Validate()
+	var validationError *ValidationError
+	if !errors.As(err, &validationError) {
+		return err
+	}
+	var fieldErrors []*FieldError
+	for _, fieldError := range validationError.Errors {
+		if slices.Contains(fields, fieldError.Field) {
+			fieldErrors = append(fieldErrors, fieldError)
+		}
+	}
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: fieldErrors}
+}
+
+// FieldTypeError turns a JSON value of the wrong type into a *ValidationError, other errors are returned as they are
+func FieldTypeError(err error) error {
+	var typeError *json.UnmarshalTypeError
+	if !errors.As(err, &typeError) || len(typeError.Field) == 0 {
+		return err
+	}
+	return NewFieldValidationError(typeError.Field, FieldErrorInvalidType, fmt.Sprintf("%s must be %s", typeError.Field, jsonTypeName(typeError.Type)))
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a non-negative integer"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
+
+// validationErrorOf turns the errors of the validator into a *ValidationError about subject
+func validationErrorOf(err error, subject error) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+	validationError := &ValidationError{subject: subject}
+	for _, fieldError := range validationErrors {
+		validationError.Errors = append(validationError.Errors, newFieldError(fieldError))
+	}
+	return validationError
+}
+
+func newFieldError(fieldError validator.FieldError) *FieldError {
+	field := fieldError.Field()
+	switch fieldError.Tag() {
+	case "required", "notblank":
+		return &FieldError{Field: field, Code: FieldErrorRequired, Message: field + " is required"}
+	case "max":
+		return &FieldError{Field: field, Code: FieldErrorTooLong, Message: fmt.Sprintf("%s must be at most %s characters long", field, fieldError.Param())}
+	case "decimal":
+		return &FieldError{Field: field, Code: FieldErrorInvalidType, Message: field + " must be a decimal number"}
+	case "nonnegative":
+		return &FieldError{Field: field, Code: FieldErrorTooSmall, Message: field + " must not be negative"}
+	case "amount":
+		return &FieldError{Field: field, Code: FieldErrorInvalid, Message: fmt.Sprintf("%s must have at most %d decimal places and be less than %s", field, AmountScale, maxAmount)}
+	case "iso4217":
+		return &FieldError{Field: field, Code: FieldErrorUnknownCurrency, Message: fmt.Sprintf("%s %q is not an ISO 4217 currency code", field, fieldError.Value())}
+	case "date":
+		return &FieldError{Field: field, Code: FieldErrorInvalid, Message: field + " must be a date like 2006-01-02"}
+	case "position":
+		return &FieldError{Field: field, Code: FieldErrorUnknownPosition, Message: fmt.Sprintf("%s %q is not in the position catalog", field, fieldError.Value())}
+	}
+	return &FieldError{Field: field, Code: FieldErrorInvalid, Message: fieldError.Error()}
+}
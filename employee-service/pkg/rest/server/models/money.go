@@ -0,0 +1,105 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+// AmountScale is how many decimal places an amount of money keeps, enough for every ISO 4217 currency
+const AmountScale = 4
+
+// DefaultCurrency is the currency of salaries given without one, and of those recorded before there were currencies
+const DefaultCurrency = "USD"
+
+// maxAmount bounds amounts, so that their ten-thousandths fit a 64-bit integer column with room to sum them up
+var maxAmount = decimal.New(1, 14)
+
+// Amount is an exact decimal amount of money. JSON carries it as a string like "76000.25" and takes numbers too,
+// databases store it as an integer count of ten-thousandths, which every backend keeps and compares exactly.
+type Amount struct {
+	decimal.Decimal
+	// invalid is the JSON the amount was decoded from when it is not a decimal number, left to validation to report
+	invalid string
+	// masked amounts are hidden from callers not allowed to see them, JSON carries them as null both ways
+	masked bool
+}
+
+// NewAmount parses a decimal amount like 76000.25
+func NewAmount(value string) (Amount, error) {
+	parsed, err := decimal.NewFromString(value)
+	if err != nil {
+		return Amount{}, fmt.Errorf("%q is not a decimal number", value)
+	}
+	return Amount{Decimal: parsed}, nil
+}
+
+// RequireAmount is NewAmount for amounts known to be valid, it panics otherwise
+func RequireAmount(value string) Amount {
+	return Amount{Decimal: decimal.RequireFromString(value)}
+}
+
+// Valid tells whether amount has at most AmountScale decimal places and stays below 10^14
+func (amount Amount) Valid() bool {
+	return amount.Equal(amount.Truncate(AmountScale)) && amount.Abs().LessThan(maxAmount)
+}
+
+// Masked tells whether the amount is hidden from the caller, see Employee.Redact
+func (amount Amount) Masked() bool {
+	return amount.masked
+}
+
+// MarshalJSON writes the amount as a string, null when it is masked
+func (amount Amount) MarshalJSON() ([]byte, error) {
+	if amount.masked {
+		return []byte("null"), nil
+	}
+	return amount.Decimal.MarshalJSON()
+}
+
+// UnmarshalJSON takes a number or a string holding one, null is a masked amount sent back as it was shown.
+// Anything else is kept for validation to report along with the other invalid fields, like dates are.
+func (amount *Amount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*amount = Amount{masked: true}
+		return nil
+	}
+	if err := amount.Decimal.UnmarshalJSON(data); err != nil {
+		*amount = Amount{invalid: string(data)}
+	}
+	return nil
+}
+
+// text is the amount as validation sees it, the JSON it came from if that is not a decimal number
+func (amount Amount) text() string {
+	if len(amount.invalid) > 0 {
+		return amount.invalid
+	}
+	return amount.String()
+}
+
+// Value writes the amount in ten-thousandths, rounding any further decimal places
+func (amount Amount) Value() (driver.Value, error) {
+	return amount.Shift(AmountScale).Round(0).IntPart(), nil
+}
+
+// Scan reads an amount in ten-thousandths, whichever type the driver hands it out as
+func (amount *Amount) Scan(value interface{}) error {
+	var units decimal.Decimal
+	switch v := value.(type) {
+	case nil:
+		units = decimal.Zero
+	case int64:
+		units = decimal.NewFromInt(v)
+	case float64:
+		units = decimal.NewFromFloat(v)
+	case string, []byte:
+		if err := units.Scan(v); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot scan %T into an amount", value)
+	}
+	amount.Decimal = units.Shift(-AmountScale)
+	return nil
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidSalaryChange is wrapped by the ValidationError of a salary change
+	ErrInvalidSalaryChange = errors.New("invalid salary change")
+	// ErrSalaryChangeEffective means a salary change took effect already, only scheduled ones can be canceled
+	ErrSalaryChangeEffective = errors.New("salary change is effective already")
+)
+
+// Reasons of the salary changes recorded along with the writes to employees
+const (
+	SalaryReasonInitial = "initial salary"
+	SalaryReasonUpdate  = "employee update"
+)
+
+// Date is a calendar day in UTC written like 2006-01-02, so that dates compare as strings
+type Date string
+
+// Today is the current day in UTC
+func Today() Date {
+	return Date(time.Now().UTC().Format(time.DateOnly))
+}
+
+// Valid tells whether date is an existing day in the 2006-01-02 layout
+func (date Date) Valid() bool {
+	_, err := time.Parse(time.DateOnly, string(date))
+	return err == nil
+}
+
+// Scan reads a date column, which drivers hand out as time.Time or as text
+func (date *Date) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*date = ""
+	case time.Time:
+		*date = Date(v.Format(time.DateOnly))
+	case string:
+		*date = Date(v)
+	case []byte:
+		*date = Date(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a date", value)
+	}
+	return nil
+}
+
+// Value writes the date as text, which every database takes for a date column
+func (date Date) Value() (driver.Value, error) {
+	return string(date), nil
+}
+
+// SalaryChange sets the salary of an employee from its effective date on. Changes are never updated, the salary of
+// an employee is the one of the latest change effective today, the ones effective later are scheduled.
+type SalaryChange struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	EmployeeID uint `json:"employee_id"`
+
+	Salary Amount `json:"salary" swaggertype:"string" example:"76000.25"`
+
+	// Currency is the ISO 4217 code of the salary, the current currency of the employee unless given
+	Currency string `json:"currency,omitempty" example:"USD"`
+
+	// EffectiveDate is the first day of the salary, in UTC
+	EffectiveDate Date `json:"effective_date" example:"2024-01-01"`
+
+	Reason string `json:"reason,omitempty"`
+
+	// Author is who made the change, the actor of the request that created it, changes recorded along with
+	// employee writes have none
+	Author string `json:"author,omitempty" readonly:"true"`
+
+	// Scheduled is set for changes that take effect after today
+	Scheduled bool `json:"scheduled,omitempty" gorm:"-"`
+}
+
+// salaryChangeRules are the validation rules of the fields of a salary change, see employeeRules
+type salaryChangeRules struct {
+	Salary        string `json:"salary" validate:"required,decimal,nonnegative,amount"`
+	Currency      string `json:"currency" validate:"omitempty,iso4217"`
+	EffectiveDate string `json:"effective_date" validate:"required,date"`
+	Reason        string `json:"reason" validate:"max=200"`
+}
+
+// Validate checks salary change and returns a *ValidationError listing every invalid field
+func (change *SalaryChange) Validate() error {
+	// a null salary is missing, there is no stored salary for it to stand for
+	salary := change.Salary.text()
+	if change.Salary.masked {
+		salary = ""
+	}
+	return validationErrorOf(rulesValidator.Struct(&salaryChangeRules{
+		Salary:        salary,
+		Currency:      change.Currency,
+		EffectiveDate: string(change.EffectiveDate),
+		Reason:        change.Reason,
+	}), ErrInvalidSalaryChange)
+}
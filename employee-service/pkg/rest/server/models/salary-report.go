@@ -0,0 +1,219 @@
+package models
+
+import (
+	"cmp"
+	"github.com/shopspring/decimal"
+	"slices"
+	"strconv"
+)
+
+// SalaryGroupFields whitelists the fields salary reports group employees by, mapped to their key in SalaryStats.Group
+var SalaryGroupFields = map[string]string{
+	"position":   "position",
+	"department": "department_id",
+	"hire_year":  "hire_year",
+}
+
+// SalaryReportQuery describes the salary statistics of the employees matching Filter
+type SalaryReportQuery struct {
+	Filter EmployeeFilter
+	// GroupBy are fields out of SalaryGroupFields, salaries are grouped by currency in any case
+	GroupBy []string
+	// Percentiles are reported along with the median, each between 0 and 100
+	Percentiles []float64
+	// BandWidth splits the salaries of every group into bands that wide, nil for no bands
+	BandWidth *Amount
+}
+
+// NeedsSalaries reports whether the report takes every salary of a group rather than totals and the middle salaries,
+// which it does for percentiles other than the median and for bands
+func (query *SalaryReportQuery) NeedsSalaries() bool {
+	return len(query.Percentiles) > 0 || query.BandWidth != nil
+}
+
+// SalaryTotals are the statistics of a group a database aggregates, see SalaryReport.AddTotals
+type SalaryTotals struct {
+	Headcount int
+	Total     Amount
+	Min       Amount
+	Max       Amount
+}
+
+// MedianRows returns the offset into the sorted salaries of a group of headcount employees and the number of
+// salaries from there the median lies between
+func MedianRows(headcount int) (offset, count int) {
+	if headcount%2 == 0 {
+		return headcount/2 - 1, 2
+	}
+	return headcount / 2, 1
+}
+
+// SalaryStats sums up the salaries of a group of employees paid in the same currency.
+// Averages and percentiles are rounded to AmountScale decimal places.
+type SalaryStats struct {
+	// Group holds the value of each field grouped by, the department_id of employees outside of any department is null
+	Group     map[string]interface{} `json:"group"`
+	Currency  string                 `json:"currency" example:"USD"`
+	Headcount int                    `json:"headcount"`
+	Total     Amount                 `json:"total" swaggertype:"string" example:"152000.5"`
+	Average   Amount                 `json:"average" swaggertype:"string" example:"76000.25"`
+	Median    Amount                 `json:"median" swaggertype:"string" example:"76000.25"`
+	Min       Amount                 `json:"min" swaggertype:"string" example:"53000"`
+	Max       Amount                 `json:"max" swaggertype:"string" example:"99000.5"`
+	// Percentiles maps each requested percentile, like "90", to the salary below which that percentage of the group is paid
+	Percentiles map[string]Amount `json:"percentiles,omitempty" swaggertype:"object,string"`
+	// Bands are the salary bands with employees in them, lowest first
+	Bands []*SalaryBand `json:"bands,omitempty"`
+}
+
+// SalaryBand counts the employees paid from From, inclusive, up to To, exclusive
+type SalaryBand struct {
+	From      Amount `json:"from" swaggertype:"string" example:"70000"`
+	To        Amount `json:"to" swaggertype:"string" example:"80000"`
+	Headcount int    `json:"headcount"`
+}
+
+// salaryGroupKey tells groups apart, the fields not grouped by stay zero. Department ids start at 1.
+type salaryGroupKey struct {
+	position     string
+	departmentID uint
+	hireYear     int
+	currency     string
+}
+
+// SalaryReport adds employees up into the SalaryStats of their group
+type SalaryReport struct {
+	query    *SalaryReportQuery
+	groups   map[salaryGroupKey]*SalaryStats
+	salaries map[salaryGroupKey][]decimal.Decimal
+}
+
+// NewSalaryReport starts an empty report for query
+func NewSalaryReport(query *SalaryReportQuery) *SalaryReport {
+	return &SalaryReport{
+		query:    query,
+		groups:   map[salaryGroupKey]*SalaryStats{},
+		salaries: map[salaryGroupKey][]decimal.Decimal{},
+	}
+}
+
+// Add counts employee in the report, it never fails so that it can visit an export
+func (report *SalaryReport) Add(employee *Employee) error {
+	key := report.group(employee)
+	report.salaries[key] = append(report.salaries[key], employee.Salary.Decimal)
+	return nil
+}
+
+// AddTotals adds the group of employee, whose fields grouped by and currency are set, from totals a database
+// aggregated and the salaries at MedianRows. Groups added this way have no percentiles or bands.
+func (report *SalaryReport) AddTotals(employee *Employee, totals SalaryTotals, middle []decimal.Decimal) {
+	stats := report.groups[report.group(employee)]
+	stats.Headcount = totals.Headcount
+	stats.Total = totals.Total
+	stats.Average = Amount{Decimal: totals.Total.DivRound(decimal.NewFromInt(int64(totals.Headcount)), AmountScale)}
+	stats.Median = Amount{Decimal: decimal.Avg(middle[0], middle[1:]...).Round(AmountScale)}
+	stats.Min = totals.Min
+	stats.Max = totals.Max
+}
+
+// group returns the key of the group of employee, adding the group when it is new
+func (report *SalaryReport) group(employee *Employee) salaryGroupKey {
+	key := salaryGroupKey{currency: employee.Currency}
+	group := map[string]interface{}{}
+	for _, field := range report.query.GroupBy {
+		switch field {
+		case "position":
+			key.position = employee.Position
+			group[SalaryGroupFields[field]] = employee.Position
+		case "department":
+			if employee.DepartmentID != nil {
+				key.departmentID = *employee.DepartmentID
+			}
+			group[SalaryGroupFields[field]] = employee.DepartmentID
+		case "hire_year":
+			key.hireYear = employee.CreatedAt.UTC().Year()
+			group[SalaryGroupFields[field]] = key.hireYear
+		}
+	}
+	if _, ok := report.groups[key]; !ok {
+		report.groups[key] = &SalaryStats{Group: group, Currency: employee.Currency}
+	}
+	return key
+}
+
+// Stats returns the statistics of every group, ordered by the fields grouped by and then by currency
+func (report *SalaryReport) Stats() []*SalaryStats {
+	keys := make([]salaryGroupKey, 0, len(report.groups))
+	for key := range report.groups {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b salaryGroupKey) int {
+		if c := cmp.Compare(a.position, b.position); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.departmentID, b.departmentID); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.hireYear, b.hireYear); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.currency, b.currency)
+	})
+
+	stats := make([]*SalaryStats, 0, len(keys))
+	for _, key := range keys {
+		group, salaries := report.groups[key], report.salaries[key]
+		if len(salaries) == 0 {
+			// added with its totals
+			stats = append(stats, group)
+			continue
+		}
+		slices.SortFunc(salaries, decimal.Decimal.Cmp)
+		total := decimal.Sum(decimal.Zero, salaries...)
+		group.Headcount = len(salaries)
+		group.Total = Amount{Decimal: total}
+		group.Average = Amount{Decimal: total.DivRound(decimal.NewFromInt(int64(len(salaries))), AmountScale)}
+		group.Median = Amount{Decimal: percentile(salaries, decimal.NewFromInt(50))}
+		group.Min = Amount{Decimal: salaries[0]}
+		group.Max = Amount{Decimal: salaries[len(salaries)-1]}
+		for _, p := range report.query.Percentiles {
+			if group.Percentiles == nil {
+				group.Percentiles = map[string]Amount{}
+			}
+			group.Percentiles[strconv.FormatFloat(p, 'f', -1, 64)] = Amount{Decimal: percentile(salaries, decimal.NewFromFloat(p))}
+		}
+		if report.query.BandWidth != nil {
+			group.Bands = salaryBands(salaries, report.query.BandWidth.Decimal)
+		}
+		stats = append(stats, group)
+	}
+	return stats
+}
+
+// percentile interpolates linearly between the two salaries closest to rank p of sorted, like PERCENTILE_CONT in SQL
+func percentile(sorted []decimal.Decimal, p decimal.Decimal) decimal.Decimal {
+	rank := p.Mul(decimal.NewFromInt(int64(len(sorted) - 1))).Div(decimal.NewFromInt(100))
+	lower := rank.Floor()
+	i := int(lower.IntPart())
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i].Add(sorted[i+1].Sub(sorted[i]).Mul(rank.Sub(lower))).Round(AmountScale)
+}
+
+// salaryBands counts sorted salaries by the band of width they fall into, leaving out empty bands
+func salaryBands(sorted []decimal.Decimal, width decimal.Decimal) []*SalaryBand {
+	var bands []*SalaryBand
+	for _, salary := range sorted {
+		quotient, remainder := salary.QuoRem(width, 0)
+		if remainder.IsNegative() {
+			quotient = quotient.Sub(decimal.NewFromInt(1))
+		}
+		from := quotient.Mul(width)
+		if len(bands) == 0 || !bands[len(bands)-1].From.Equal(from) {
+			bands = append(bands, &SalaryBand{From: Amount{Decimal: from}, To: Amount{Decimal: from.Add(width)}})
+		}
+		bands[len(bands)-1].Headcount++
+	}
+	return bands
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+)
+
+type DepartmentService struct {
+	departmentRepository daos.DepartmentRepository
+}
+
+func NewDepartmentService(departmentRepository daos.DepartmentRepository) *DepartmentService {
+	return &DepartmentService{
+		departmentRepository: departmentRepository,
+	}
+}
+
+func (departmentService *DepartmentService) CreateDepartment(department *models.Department) (*models.Department, error) {
+	if err := department.Validate(); err != nil {
+		return nil, err
+	}
+	return departmentService.departmentRepository.CreateDepartment(department)
+}
+
+func (departmentService *DepartmentService) GetDepartment(id int64) (*models.Department, error) {
+	return departmentService.departmentRepository.GetDepartment(id)
+}
+
+func (departmentService *DepartmentService) GetDepartments(page int, limit int) ([]*models.Department, int64, error) {
+	return departmentService.departmentRepository.GetDepartments(page, limit)
+}
+
+func (departmentService *DepartmentService) UpdateDepartment(id int64, department *models.Department) (*models.Department, error) {
+	if err := department.Validate(); err != nil {
+		return nil, err
+	}
+	return departmentService.departmentRepository.UpdateDepartment(id, department)
+}
+
+// DeleteDepartment deletes an empty department, or moves its employees to the department reassignTo first when it is not 0.
+// The audit trail attributes the moves to audit.
+func (departmentService *DepartmentService) DeleteDepartment(id int64, reassignTo int64, audit models.AuditContext) error {
+	return departmentService.departmentRepository.DeleteDepartment(id, reassignTo, audit)
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+)
+
+// WithAudit returns a service sharing the storage of this one that attributes the changes it makes to audit
+func (employeeService *EmployeeService) WithAudit(audit models.AuditContext) *EmployeeService {
+	return &EmployeeService{
+		employeeRepository: employeeService.employeeRepository.WithAudit(audit),
+	}
+}
+
+func (employeeService *EmployeeService) GetAuditEntries(query *models.AuditQuery) (*models.AuditPage, error) {
+	return employeeService.employeeRepository.GetAuditEntries(query)
+}
+
+// GetEmployeeAudit returns a page of the audit trail of employee id, which outlives the employee.
+// It fails with sqls.ErrNotExists for an employee without any entry that does not exist either.
+func (employeeService *EmployeeService) GetEmployeeAudit(id int64, query *models.AuditQuery) (*models.AuditPage, error) {
+	query.EmployeeIDs = []uint{uint(id)}
+	page, err := employeeService.employeeRepository.GetAuditEntries(query)
+	if err != nil {
+		return nil, err
+	}
+	if page.Total == 0 {
+		if _, err := employeeService.employeeRepository.GetEmployee(id); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
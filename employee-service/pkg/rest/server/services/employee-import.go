@@ -0,0 +1,286 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// xlsxUnzipRatio bounds how much larger than the uploaded file an XLSX workbook may get once
+// unzipped, so that a small archive of highly compressible parts cannot exhaust memory or disk
+const xlsxUnzipRatio = 10
+
+// ImportEmployees reads employees out of a CSV or XLSX file and reports the invalid rows.
+// Employees are only stored when the request commits and every row is valid, all of them in one transaction.
+func (employeeService *EmployeeService) ImportEmployees(request *models.EmployeeImportRequest) (*models.EmployeeImportResult, error) {
+	table, err := readImportTable(request)
+	if err != nil {
+		return nil, err
+	}
+	if len(table) == 0 {
+		return nil, fmt.Errorf("%w: the file has no header row", models.ErrInvalidImport)
+	}
+	columns, err := mapImportColumns(table[0].cells, request.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReadOnlyColumns(table, columns, request.ReadOnlyFields); err != nil {
+		return nil, err
+	}
+
+	result := &models.EmployeeImportResult{Errors: []*models.EmployeeImportRowError{}}
+	var employees []*models.Employee
+	var lines []int
+	for _, row := range table[1:] {
+		if blankRow(row.cells) {
+			continue
+		}
+		result.Rows++
+		if result.Rows > models.MaxEmployeeImportRows {
+			return nil, fmt.Errorf("%w: more than %d rows", models.ErrInvalidImport, models.MaxEmployeeImportRows)
+		}
+		employee, rowErrors := parseImportRow(row.line, row.cells, columns)
+		if len(rowErrors) > 0 {
+			result.Invalid++
+			result.Errors = append(result.Errors, rowErrors...)
+			continue
+		}
+		result.Valid++
+		employees = append(employees, employee)
+		lines = append(lines, row.line)
+	}
+	if employees, err = employeeService.checkImportReferences(result, employees, lines); err != nil {
+		return nil, err
+	}
+
+	if !request.Commit || result.Invalid > 0 || len(employees) == 0 {
+		return result, nil
+	}
+	if err := employeeService.employeeRepository.CreateEmployees(employees); err != nil {
+		return nil, err
+	}
+	result.Committed = true
+	result.Created = len(employees)
+	return result, nil
+}
+
+// checkImportReferences reports the rows of employees, at lines, whose department or manager does not exist, and
+// returns the employees left valid. Rows cannot name each other as managers, their ids are only known once stored.
+func (employeeService *EmployeeService) checkImportReferences(result *models.EmployeeImportResult, employees []*models.Employee, lines []int) ([]*models.Employee, error) {
+	if len(employees) == 0 {
+		return employees, nil
+	}
+	fieldErrors, err := employeeService.employeeRepository.CheckEmployeeReferences(employees)
+	if err != nil {
+		return nil, err
+	}
+	valid := employees[:0]
+	for i, employee := range employees {
+		if len(fieldErrors[i]) == 0 {
+			valid = append(valid, employee)
+			continue
+		}
+		result.Valid--
+		result.Invalid++
+		for _, fieldError := range fieldErrors[i] {
+			result.Errors = append(result.Errors, &models.EmployeeImportRowError{Row: lines[i], Column: fieldError.Field, Code: fieldError.Code, Error: fieldError.Message})
+		}
+	}
+	// keep the errors in file order
+	slices.SortStableFunc(result.Errors, func(a, b *models.EmployeeImportRowError) int {
+		return a.Row - b.Row
+	})
+	return valid, nil
+}
+
+// importRow holds the cells of a row and its line in the file
+type importRow struct {
+	line  int
+	cells []string
+}
+
+// readImportTable returns the non-empty rows of the file
+func readImportTable(request *models.EmployeeImportRequest) ([]importRow, error) {
+	switch request.Format {
+	case models.EmployeeImportCSV:
+		reader := csv.NewReader(request.File)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		var table []importRow
+		for {
+			cells, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", models.ErrInvalidImport, err)
+			}
+			// blank lines are skipped by the reader, the position keeps rows numbered like the file
+			line, _ := reader.FieldPos(0)
+			table = append(table, importRow{line: line, cells: cells})
+		}
+		if len(table) > 0 && len(table[0].cells) > 0 {
+			table[0].cells[0] = strings.TrimPrefix(table[0].cells[0], "\ufeff")
+		}
+		return table, nil
+	case models.EmployeeImportXLSX:
+		file, err := excelize.OpenReader(request.File, excelize.Options{
+			RawCellValue:      true,
+			UnzipSizeLimit:    xlsxUnzipRatio * models.MaxEmployeeImportSize,
+			UnzipXMLSizeLimit: models.MaxEmployeeImportSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", models.ErrInvalidImport, err)
+		}
+		defer file.Close()
+		sheet := request.Sheet
+		if len(sheet) == 0 {
+			sheet = file.GetSheetName(0)
+		}
+		rows, err := file.GetRows(sheet)
+		if err != nil {
+			var sheetErr excelize.ErrSheetNotExist
+			if errors.As(err, &sheetErr) {
+				return nil, fmt.Errorf("%w: %v", models.ErrInvalidImport, err)
+			}
+			return nil, err
+		}
+		var table []importRow
+		for i, cells := range rows {
+			if len(table) == 0 && blankRow(cells) {
+				// rows above the header
+				continue
+			}
+			table = append(table, importRow{line: i + 1, cells: cells})
+		}
+		return table, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported format %q, expected csv or xlsx", models.ErrInvalidImport, request.Format)
+}
+
+// mapImportColumns returns the column index of every mapped employee field
+func mapImportColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	defaultMapping := len(mapping) == 0
+	if defaultMapping {
+		mapping = map[string]string{}
+		for _, field := range models.EmployeeImportFields {
+			mapping[field] = field
+		}
+	}
+
+	headers := map[string]int{}
+	for i, name := range header {
+		headers[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	columns := map[string]int{}
+	for name, field := range mapping {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !slices.Contains(models.EmployeeImportFields, field) {
+			return nil, fmt.Errorf("%w: column %q is mapped to unknown field %q, expected one of %s", models.ErrInvalidImport, name, field, strings.Join(models.EmployeeImportFields, ", "))
+		}
+		index, ok := headers[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if defaultMapping {
+				// the file just lacks that column
+				continue
+			}
+			return nil, fmt.Errorf("%w: mapped column %q is not in the header row", models.ErrInvalidImport, name)
+		}
+		if _, ok := columns[field]; ok {
+			return nil, fmt.Errorf("%w: more than one column is mapped to %s", models.ErrInvalidImport, field)
+		}
+		columns[field] = index
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, fmt.Errorf("%w: no column is mapped to name", models.ErrInvalidImport)
+	}
+	return columns, nil
+}
+
+// checkReadOnlyColumns refuses a table with a value in a column mapped to one of the read-only fields
+func checkReadOnlyColumns(table []importRow, columns map[string]int, readOnlyFields []string) error {
+	for _, field := range readOnlyFields {
+		index, ok := columns[field]
+		if !ok {
+			continue
+		}
+		for _, row := range table[1:] {
+			if index < len(row.cells) && len(strings.TrimSpace(row.cells[index])) > 0 {
+				return fmt.Errorf("%w: row %d sets the %s, which the caller may not", models.ErrReadOnlyImportField, row.line, field)
+			}
+		}
+	}
+	return nil
+}
+
+// parseImportRow turns the row at line into an employee, or explains what is wrong with it
+func parseImportRow(line int, row []string, columns map[string]int) (*models.Employee, []*models.EmployeeImportRowError) {
+	cell := func(field string) string {
+		index, ok := columns[field]
+		if !ok || index >= len(row) {
+			return ""
+		}
+		return unescapeFormula(strings.TrimSpace(row[index]))
+	}
+
+	var rowErrors []*models.EmployeeImportRowError
+	employee := &models.Employee{Name: cell("name"), Position: cell("position"), Currency: cell("currency")}
+	for _, reference := range []struct {
+		field string
+		id    **uint
+	}{{"department_id", &employee.DepartmentID}, {"manager_id", &employee.ManagerID}} {
+		field := reference.field
+		value := cell(field)
+		if len(value) == 0 {
+			continue
+		}
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || parsed == 0 {
+			rowErrors = append(rowErrors, &models.EmployeeImportRowError{Row: line, Column: field, Code: models.FieldErrorInvalidType, Error: fmt.Sprintf("%s %q is not an id", field, value)})
+			continue
+		}
+		id := uint(parsed)
+		*reference.id = &id
+	}
+	if salary := cell("salary"); len(salary) > 0 {
+		value, err := models.NewAmount(salary)
+		if err != nil {
+			rowErrors = append(rowErrors, &models.EmployeeImportRowError{Row: line, Column: "salary", Code: models.FieldErrorInvalidType, Error: fmt.Sprintf("salary %q is not a number", salary)})
+		}
+		employee.Salary = value
+	}
+	var validationError *models.ValidationError
+	if err := employee.Validate(); errors.As(err, &validationError) {
+		for _, fieldError := range validationError.Errors {
+			if fieldError.Field == "salary" && len(rowErrors) > 0 {
+				// already reported as not a number
+				continue
+			}
+			rowErrors = append(rowErrors, &models.EmployeeImportRowError{Row: line, Column: fieldError.Field, Code: fieldError.Code, Error: fieldError.Message})
+		}
+	}
+	return employee, rowErrors
+}
+
+// unescapeFormula drops the quote exports put in front of text a spreadsheet would evaluate
+func unescapeFormula(text string) string {
+	if len(text) > 1 && text[0] == '\'' && strings.ContainsRune(models.FormulaPrefixes, rune(text[1])) {
+		return text[1:]
+	}
+	return text
+}
+
+func blankRow(row []string) bool {
+	for _, cell := range row {
+		if len(strings.TrimSpace(cell)) > 0 {
+			return false
+		}
+	}
+	return true
+}
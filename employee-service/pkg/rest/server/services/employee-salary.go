@@ -0,0 +1,49 @@
+package services
+
+import (
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+)
+
+// GetSalaryChanges returns the salary history of an employee by effective date, the scheduled changes last
+func (employeeService *EmployeeService) GetSalaryChanges(id int64) ([]*models.SalaryChange, error) {
+	changes, err := employeeService.employeeRepository.GetSalaryChanges(id)
+	if err != nil {
+		return nil, err
+	}
+	markScheduled(changes...)
+	return changes, nil
+}
+
+// CreateSalaryChange records a salary change effective today unless it has an effective date of its own.
+// A change effective later is scheduled, the salary of the employee changes on its effective date.
+func (employeeService *EmployeeService) CreateSalaryChange(change *models.SalaryChange) (*models.SalaryChange, error) {
+	if len(change.EffectiveDate) == 0 {
+		change.EffectiveDate = models.Today()
+	}
+	if err := change.Validate(); err != nil {
+		return nil, err
+	}
+	change, err := employeeService.employeeRepository.CreateSalaryChange(change)
+	if err != nil {
+		return nil, err
+	}
+	markScheduled(change)
+	return change, nil
+}
+
+// DeleteSalaryChange cancels a scheduled salary change, the history of effective ones stays as it is
+func (employeeService *EmployeeService) DeleteSalaryChange(employeeID int64, id int64) error {
+	return employeeService.employeeRepository.DeleteSalaryChange(employeeID, id)
+}
+
+func markScheduled(changes ...*models.SalaryChange) {
+	today := models.Today()
+	for _, change := range changes {
+		change.Scheduled = change.EffectiveDate > today
+	}
+}
+
+// GetSalaryReport sums up the salaries of the employees matching the filter of query, group by group
+func (employeeService *EmployeeService) GetSalaryReport(query *models.SalaryReportQuery) ([]*models.SalaryStats, error) {
+	return employeeService.employeeRepository.GetSalaryReport(query)
+}
@@ -1,44 +1,148 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
 	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"time"
 )
 
+// maxPatchAttempts bounds how often an unconditional patch is reapplied after losing a race
+const maxPatchAttempts = 3
+
 type EmployeeService struct {
-	employeeDao *daos.EmployeeDao
+	employeeRepository daos.EmployeeRepository
 }
 
-func NewEmployeeService() (*EmployeeService, error) {
-	employeeDao, err := daos.NewEmployeeDao()
-	if err != nil {
-		return nil, err
-	}
+func NewEmployeeService(employeeRepository daos.EmployeeRepository) *EmployeeService {
 	return &EmployeeService{
-		employeeDao: employeeDao,
-	}, nil
+		employeeRepository: employeeRepository,
+	}
 }
 
 func (employeeService *EmployeeService) CreateEmployee(employee *models.Employee) (*models.Employee, error) {
-	return employeeService.employeeDao.CreateEmployee(employee)
+	if err := employee.Validate(); err != nil {
+		return nil, err
+	}
+	return employeeService.employeeRepository.CreateEmployee(employee)
 }
 
 func (employeeService *EmployeeService) GetEmployee(id int64) (*models.Employee, error) {
-	return employeeService.employeeDao.GetEmployee(id)
+	return employeeService.employeeRepository.GetEmployee(id)
+}
+
+// GetEmployeeAsOf returns an employee the way it was at asOf, one deleted since included
+func (employeeService *EmployeeService) GetEmployeeAsOf(id int64, asOf time.Time) (*models.Employee, error) {
+	return employeeService.employeeRepository.GetEmployeeAsOf(id, asOf)
+}
+
+func (employeeService *EmployeeService) GetEmployees(query *models.EmployeeQuery) (*models.EmployeePage, error) {
+	return employeeService.employeeRepository.GetEmployees(query)
+}
+
+func (employeeService *EmployeeService) SearchEmployees(query *models.EmployeeSearchQuery) (*models.EmployeeSearchResult, error) {
+	return employeeService.employeeRepository.SearchEmployees(query)
+}
+
+func (employeeService *EmployeeService) ExportEmployees(filter *models.EmployeeFilter, sort []models.EmployeeSort, visit func(*models.Employee) error) error {
+	return employeeService.employeeRepository.ExportEmployees(filter, sort, visit)
 }
 
-func (employeeService *EmployeeService) GetEmployees(page int, limit int) ([]*models.Employee, error) {
-	return employeeService.employeeDao.GetEmployees(page, limit)
+func (employeeService *EmployeeService) GetManagementChain(id int64) ([]*models.Employee, error) {
+	return employeeService.employeeRepository.GetManagementChain(id)
+}
+
+func (employeeService *EmployeeService) GetReportingSubtree(id int64) ([]*models.Employee, error) {
+	return employeeService.employeeRepository.GetReportingSubtree(id)
+}
+
+// GetOrgChart arranges employee root and everyone reporting to them as a tree, or the whole organization when root is 0
+func (employeeService *EmployeeService) GetOrgChart(root int64) ([]*models.OrgChartNode, error) {
+	if root == 0 {
+		var employees []*models.Employee
+		if err := employeeService.employeeRepository.ExportEmployees(&models.EmployeeFilter{}, nil, func(employee *models.Employee) error {
+			employees = append(employees, employee)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		return models.NewOrgChart(employees), nil
+	}
+	manager, err := employeeService.employeeRepository.GetEmployee(root)
+	if err != nil {
+		return nil, err
+	}
+	subtree, err := employeeService.employeeRepository.GetReportingSubtree(root)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewOrgChart(append([]*models.Employee{manager}, subtree...)), nil
 }
 
 func (employeeService *EmployeeService) UpdateEmployee(id int64, employee *models.Employee) (*models.Employee, error) {
-	return employeeService.employeeDao.UpdateEmployee(id, employee)
+	if err := employee.Validate(); err != nil {
+		return nil, err
+	}
+	return employeeService.employeeRepository.UpdateEmployee(id, employee)
 }
 
-func (employeeService *EmployeeService) DeleteEmployee(id int64) error {
-	return employeeService.employeeDao.DeleteEmployee(id)
+// PatchEmployee applies patch to the stored employee and writes back only the fields it changed.
+// A non-zero version must match the stored one, otherwise the patch is reapplied when a concurrent write got in between.
+func (employeeService *EmployeeService) PatchEmployee(id int64, version uint, patch *models.EmployeePatch) (*models.Employee, error) {
+	for attempt := 1; ; attempt++ {
+		employee, err := employeeService.employeeRepository.GetEmployee(id)
+		if err != nil {
+			return nil, err
+		}
+		if version != 0 && employee.Version != version {
+			return nil, sqls.ErrVersionMismatch
+		}
+		changes, err := patch.Apply(employee)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) == 0 {
+			return employee, nil
+		}
+		// only the patched fields are held to the rules, employees stored before a rule existed stay patchable
+		fields := make([]string, 0, len(changes))
+		for field := range changes {
+			fields = append(fields, field)
+		}
+		if err := employee.ValidateFields(fields...); err != nil {
+			return nil, err
+		}
+		patched, err := employeeService.employeeRepository.PatchEmployee(id, employee.Version, changes)
+		if errors.Is(err, sqls.ErrVersionMismatch) && version == 0 && attempt < maxPatchAttempts {
+			continue
+		}
+		return patched, err
+	}
+}
+
+func (employeeService *EmployeeService) DeleteEmployee(id int64, version uint) error {
+	return employeeService.employeeRepository.DeleteEmployee(id, version)
+}
+
+func (employeeService *EmployeeService) RestoreEmployee(id int64, version uint) (*models.Employee, error) {
+	return employeeService.employeeRepository.RestoreEmployee(id, version)
+}
+
+func (employeeService *EmployeeService) PurgeEmployee(id int64) error {
+	return employeeService.employeeRepository.PurgeEmployee(id)
 }
 
 func (employeeService *EmployeeService) CreateEmployees(employees []*models.Employee) (error) {
-	return employeeService.employeeDao.CreateEmployees(employees)
-}
\ No newline at end of file
+	for i, employee := range employees {
+		if err := employee.Validate(); err != nil {
+			return fmt.Errorf("employee %d: %w", i, err)
+		}
+	}
+	return employeeService.employeeRepository.CreateEmployees(employees)
+}
+
+func (employeeService *EmployeeService) BatchEmployees(operations []*models.EmployeeBatchOperation, atomic bool) ([]*models.EmployeeBatchResult, error) {
+	return employeeService.employeeRepository.BatchEmployees(operations, atomic)
+}
@@ -0,0 +1,279 @@
+package test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/controllers"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// signToken makes a JWT with the claims, signed by key with algorithm, naming the key keyID when it is not empty
+func signToken(t *testing.T, algorithm string, key interface{}, keyID string, claims map[string]interface{}) string {
+	header := map[string]interface{}{"alg": algorithm, "typ": "JWT"}
+	if len(keyID) > 0 {
+		header["kid"] = keyID
+	}
+	encode := func(value interface{}) string {
+		data, err := json.Marshal(value)
+		assert.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signingInput := encode(header) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	var signature []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		assert.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		assert.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// tokenClaims are valid claims of the subject for an hour
+func tokenClaims(subject string) map[string]interface{} {
+	return map[string]interface{}{"sub": subject, "exp": time.Now().Add(time.Hour).Unix(), "iss": "https://id.example.com", "aud": []string{"employee-service"}}
+}
+
+// keySetJSON is a JWK set with the public keys of rsaKey and ecKey
+func keySetJSON(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) []byte {
+	encode := func(value *big.Int, size int) string {
+		return base64.RawURLEncoding.EncodeToString(value.FillBytes(make([]byte, size)))
+	}
+	data, err := json.Marshal(map[string]interface{}{"keys": []map[string]interface{}{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "alg": "RS256", "n": encode(rsaKey.N, rsaKey.Size()), "e": encode(big.NewInt(int64(rsaKey.E)), 3)},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": encode(ecKey.X, 32), "y": encode(ecKey.Y, 32)},
+		{"kty": "oct", "kid": "ignored", "k": "c2VjcmV0"},
+	}})
+	assert.NoError(t, err)
+	return data
+}
+
+// newAuthenticatedRouter answers GET /whoami with the token subject and a claim, behind Authenticate
+func newAuthenticatedRouter(verifier *controllers.TokenVerifier) *gin.Engine {
+	router := gin.New()
+	router.Use(controllers.RequestID(), controllers.Authenticate(verifier))
+	router.GET("/whoami", func(context *gin.Context) {
+		context.JSON(http.StatusOK, gin.H{"subject": controllers.TokenSubject(context), "name": controllers.TokenClaims(context)["name"]})
+	})
+	return router
+}
+
+func authenticatedGet(router *gin.Engine, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/whoami", nil)
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthenticate_HS256(t *testing.T) {
+	secret := []byte("a-secret-of-thirty-two-bytes-len")
+	verifier, err := controllers.NewTokenVerifier(controllers.AuthConfig{HMACSecret: string(secret), Issuer: "https://id.example.com", Audience: "employee-service"})
+	assert.NoError(t, err)
+	router := newAuthenticatedRouter(verifier)
+
+	claims := tokenClaims("jane.hr")
+	claims["name"] = "Jane"
+	rec := authenticatedGet(router, signToken(t, "HS256", secret, "", claims))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"subject": "jane.hr", "name": "Jane"}`, rec.Body.String())
+
+	rec = authenticatedGet(router, "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, controllers.ProblemContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, `Bearer realm="employee-service"`, rec.Header().Get("WWW-Authenticate"))
+
+	expired := tokenClaims("jane.hr")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	wrongAudience := tokenClaims("jane.hr")
+	wrongAudience["aud"] = "payroll-service"
+	noSubject := tokenClaims("")
+	noExpiry := tokenClaims("jane.hr")
+	delete(noExpiry, "exp")
+	for name, token := range map[string]string{
+		"wrong secret":   signToken(t, "HS256", []byte("another-secret"), "", tokenClaims("jane.hr")),
+		"expired":        signToken(t, "HS256", secret, "", expired),
+		"wrong audience": signToken(t, "HS256", secret, "", wrongAudience),
+		"no subject":     signToken(t, "HS256", secret, "", noSubject),
+		"no expiry":      signToken(t, "HS256", secret, "", noExpiry),
+		"unsigned":       signToken(t, "none", nil, "", tokenClaims("jane.hr")),
+		"malformed":      "not-a-token",
+	} {
+		rec := authenticatedGet(router, token)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, name)
+		assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `error="invalid_token"`, name)
+	}
+
+	// without a secret or a key set nothing gets through
+	verifier, err = controllers.NewTokenVerifier(controllers.AuthConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, authenticatedGet(newAuthenticatedRouter(verifier), signToken(t, "HS256", secret, "", tokenClaims("jane.hr"))).Code)
+}
+
+func TestAuthenticate_JWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	keySet := keySetJSON(t, rsaKey, ecKey)
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(file, keySet, 0o600))
+	fileVerifier, err := controllers.NewTokenVerifier(controllers.AuthConfig{JWKSFile: file})
+	assert.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(keySet)
+	}))
+	defer server.Close()
+	urlVerifier, err := controllers.NewTokenVerifier(controllers.AuthConfig{JWKSURL: server.URL})
+	assert.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	for _, verifier := range []*controllers.TokenVerifier{fileVerifier, urlVerifier} {
+		router := newAuthenticatedRouter(verifier)
+		assert.Equal(t, http.StatusOK, authenticatedGet(router, signToken(t, "RS256", rsaKey, "rsa-1", tokenClaims("jane.hr"))).Code)
+		assert.Equal(t, http.StatusOK, authenticatedGet(router, signToken(t, "ES256", ecKey, "ec-1", tokenClaims("jane.hr"))).Code)
+		assert.Equal(t, http.StatusOK, authenticatedGet(router, signToken(t, "ES256", ecKey, "", tokenClaims("jane.hr"))).Code)
+		assert.Equal(t, http.StatusUnauthorized, authenticatedGet(router, signToken(t, "RS256", otherKey, "rsa-1", tokenClaims("jane.hr"))).Code)
+		// the key of a token has to be meant for its algorithm
+		assert.Equal(t, http.StatusUnauthorized, authenticatedGet(router, signToken(t, "ES256", ecKey, "rsa-1", tokenClaims("jane.hr"))).Code)
+		// HS256 is only accepted with a configured secret
+		assert.Equal(t, http.StatusUnauthorized, authenticatedGet(router, signToken(t, "HS256", keySet, "rsa-1", tokenClaims("jane.hr"))).Code)
+	}
+
+	_, err = controllers.NewTokenVerifier(controllers.AuthConfig{JWKSFile: filepath.Join(t.TempDir(), "missing.json")})
+	assert.Error(t, err)
+	assert.NoError(t, os.WriteFile(file, []byte(`{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`), 0o600))
+	_, err = controllers.NewTokenVerifier(controllers.AuthConfig{JWKSFile: file})
+	assert.Error(t, err)
+}
+
+func TestAuthenticate_JWKSRefresh(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	keySet := keySetJSON(t, rsaKey, ecKey)
+	token := signToken(t, "RS256", rsaKey, "rsa-1", tokenClaims("jane.hr"))
+
+	// failed fetches are not retried right away
+	var failedFetches atomic.Int32
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failedFetches.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	verifier, err := controllers.NewTokenVerifier(controllers.AuthConfig{JWKSURL: failing.URL})
+	assert.NoError(t, err)
+	router := newAuthenticatedRouter(verifier)
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusUnauthorized, authenticatedGet(router, token).Code)
+	}
+	assert.Equal(t, int32(1), failedFetches.Load())
+
+	// concurrent requests share one fetch of a slow issuer, and unknown keys do not fetch again right away
+	var fetches atomic.Int32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write(keySet)
+	}))
+	defer slow.Close()
+	verifier, err = controllers.NewTokenVerifier(controllers.AuthConfig{JWKSURL: slow.URL})
+	assert.NoError(t, err)
+	router = newAuthenticatedRouter(verifier)
+	var wg sync.WaitGroup
+	codes := make([]int, 10)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = authenticatedGet(router, token).Code
+		}(i)
+	}
+	wg.Wait()
+	for _, code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+	unknownKey := signToken(t, "RS256", rsaKey, "rsa-2", tokenClaims("jane.hr"))
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusUnauthorized, authenticatedGet(router, unknownKey).Code)
+	}
+	assert.Equal(t, int32(1), fetches.Load())
+}
+
+func TestAuthenticate_AuditActor(t *testing.T) {
+	secret := []byte("a-secret-of-thirty-two-bytes-len")
+	verifier, err := controllers.NewTokenVerifier(controllers.AuthConfig{HMACSecret: string(secret)})
+	assert.NoError(t, err)
+	employeeDao := daos.NewEmployeeMemoryDao()
+	employeeController := controllers.NewEmployeeController(services.NewEmployeeService(employeeDao))
+	router := gin.New()
+	router.Use(controllers.RequestID(), controllers.Authenticate(verifier))
+	router.POST("/employees", employeeController.CreateEmployee)
+	router.POST("/employees/:id/salary-changes", employeeController.CreateSalaryChange)
+
+	// the actor is the token subject, whatever the X-Actor header claims
+	req, err := http.NewRequest("POST", "/employees", strings.NewReader(`{"name": "Rahul Gupta"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, "HS256", secret, "", tokenClaims("jane.hr")))
+	req.Header.Set(controllers.ActorHeader, "mallory")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	// without Authorize the caller is granted no permission, salaries:read neither
+	assert.Contains(t, rec.Body.String(), `"salary":null`)
+
+	page, err := employeeDao.GetAuditEntries(&models.AuditQuery{Page: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 1)
+	assert.Equal(t, "jane.hr", page.Entries[0].Actor)
+
+	// so is the author of a salary change, which cannot be backdated either
+	req, err = http.NewRequest("POST", "/employees/1/salary-changes", strings.NewReader(`{"salary": "1000", "author": "mallory", "CreatedAt": "2001-01-01T00:00:00Z"}`))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, "HS256", secret, "", tokenClaims("jane.hr")))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	changes, err := employeeDao.GetSalaryChanges(1)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, changes) {
+		assert.Equal(t, "jane.hr", changes[len(changes)-1].Author)
+		assert.WithinDuration(t, time.Now(), changes[len(changes)-1].CreatedAt, time.Minute)
+	}
+}
@@ -0,0 +1,293 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/controllers"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// authorizationSecret signs the tokens of the authorization tests
+var authorizationSecret = []byte("a-secret-of-thirty-two-bytes-len")
+
+// newAuthorizedRouter serves some employee routes the way ServeRoutes guards them, with the shipped policy
+func newAuthorizedRouter(t *testing.T) *gin.Engine {
+	verifier, err := controllers.NewTokenVerifier(controllers.AuthConfig{HMACSecret: string(authorizationSecret)})
+	assert.NoError(t, err)
+	policy, err := controllers.LoadPolicy("../rbac-policy.json")
+	assert.NoError(t, err)
+	employeeController := controllers.NewEmployeeController(services.NewEmployeeService(daos.NewEmployeeMemoryDao()))
+
+	router := gin.New()
+	router.Use(controllers.RequestID(), controllers.Authenticate(verifier), controllers.Authorize(policy))
+	router.GET("/employees", controllers.RequirePermission(controllers.PermissionReadEmployees, employeeController.FetchEmployees))
+	router.GET("/employees/:id", controllers.RequirePermission(controllers.PermissionReadEmployees, employeeController.FetchEmployee))
+	router.GET("/employees:method", controllers.CustomMethods(map[string]gin.HandlerFunc{
+		"export": controllers.RequirePermission(controllers.PermissionReadEmployees, employeeController.ExportEmployees),
+	}))
+	router.GET("/employees/search", controllers.RequirePermission(controllers.PermissionReadEmployees, employeeController.SearchEmployees))
+	router.GET("/audit", controllers.RequirePermission(controllers.PermissionReadAudit, employeeController.FetchAuditEntries))
+	router.POST("/employees", controllers.RequirePermission(controllers.PermissionWriteEmployees, employeeController.CreateEmployee))
+	router.PUT("/employees/:id", controllers.RequirePermission(controllers.PermissionWriteEmployees, employeeController.UpdateEmployee))
+	router.PATCH("/employees/:id", controllers.RequirePermission(controllers.PermissionWriteEmployees, employeeController.PatchEmployee))
+	router.DELETE("/employees/:id", controllers.RequirePermission(controllers.PermissionDeleteEmployees, employeeController.DeleteEmployee))
+	router.POST("/employees:method", controllers.CustomMethods(map[string]gin.HandlerFunc{
+		"batch":  controllers.RequirePermission(controllers.PermissionWriteEmployees, employeeController.BatchEmployees),
+		"import": controllers.RequirePermission(controllers.PermissionWriteEmployees, employeeController.ImportEmployees),
+	}))
+	return router
+}
+
+// adminAuthorization is the Authorization header of subject with the admin role of the shipped policy
+func adminAuthorization(t *testing.T, subject string) string {
+	claims := tokenClaims(subject)
+	claims["roles"] = []string{"admin"}
+	return "Bearer " + signToken(t, "HS256", authorizationSecret, "", claims)
+}
+
+// authorizeAsAdmin guards routes the way ServeRoutes does and lets requests without an Authorization header in as
+// an admin, for the tests of what the routes do rather than of who may call them
+func authorizeAsAdmin(t *testing.T) []gin.HandlerFunc {
+	verifier, err := controllers.NewTokenVerifier(controllers.AuthConfig{HMACSecret: string(authorizationSecret)})
+	assert.NoError(t, err)
+	policy, err := controllers.LoadPolicy("../rbac-policy.json")
+	assert.NoError(t, err)
+	authorization := adminAuthorization(t, "admin")
+	return []gin.HandlerFunc{
+		func(context *gin.Context) {
+			if len(context.GetHeader("Authorization")) == 0 {
+				context.Request.Header.Set("Authorization", authorization)
+			}
+		},
+		controllers.Authenticate(verifier),
+		controllers.Authorize(policy),
+	}
+}
+
+// serveAs serves a request with a token for the roles
+func serveAs(t *testing.T, router *gin.Engine, roles interface{}, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var buff bytes.Buffer
+	assert.NoError(t, json.NewEncoder(&buff).Encode(body))
+	return serveBodyAs(t, router, roles, method, path, "application/json", &buff)
+}
+
+// serveBodyAs serves a request with a body of contentType and a token for the roles
+func serveBodyAs(t *testing.T, router *gin.Engine, roles interface{}, method, path, contentType string, body io.Reader) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, path, body)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", contentType)
+	claims := tokenClaims("jane.hr")
+	claims["roles"] = roles
+	req.Header.Set("Authorization", "Bearer "+signToken(t, "HS256", authorizationSecret, "", claims))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthorize(t *testing.T) {
+	router := newAuthorizedRouter(t)
+	employee := map[string]interface{}{"name": "Rahul Gupta"}
+
+	assert.Equal(t, http.StatusOK, serveAs(t, router, []string{"viewer"}, "GET", "/employees", nil).Code)
+	rec := serveAs(t, router, []string{"viewer"}, "POST", "/employees", employee)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	var problem controllers.Problem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "urn:employee-service:problem:forbidden", problem.Type)
+	assert.Equal(t, string(controllers.PermissionWriteEmployees), problem.MissingPermission)
+	assert.Equal(t, "the roles viewer do not grant the permission employees:write", problem.Detail)
+
+	// roles can be a single space separated string, unknown roles grant nothing
+	assert.Equal(t, http.StatusCreated, serveAs(t, router, "hr-editor", "POST", "/employees", employee).Code)
+	assert.Equal(t, http.StatusForbidden, serveAs(t, router, []string{"hr-editor", "ceo"}, "DELETE", "/employees/1", nil).Code)
+	assert.Equal(t, http.StatusForbidden, serveAs(t, router, nil, "GET", "/employees", nil).Code)
+
+	// a batch only deletes with the delete permission
+	batch := map[string]interface{}{"operations": []map[string]interface{}{{"op": "delete", "id": 1}}}
+	rec = serveAs(t, router, []string{"hr-editor"}, "POST", "/employees:batch", batch)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"missing_permission":"employees:delete"`)
+	assert.Equal(t, http.StatusOK, serveAs(t, router, []string{"admin"}, "POST", "/employees:batch", batch).Code)
+	assert.Equal(t, http.StatusGone, serveAs(t, router, []string{"admin"}, "DELETE", "/employees/1", nil).Code)
+}
+
+func TestLoadPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"roles": {"viewer": ["employees:read"]}}`), 0o600))
+	policy, err := controllers.LoadPolicy(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "roles", policy.RolesClaim)
+
+	assert.NoError(t, os.WriteFile(file, []byte(`{"roles": {"viewer": ["employees:read", "employees:fire"]}}`), 0o600))
+	_, err = controllers.LoadPolicy(file)
+	assert.ErrorContains(t, err, `unknown permission "employees:fire"`)
+	_, err = controllers.LoadPolicy(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestAuthorize_SalaryRedaction(t *testing.T) {
+	router := newAuthorizedRouter(t)
+	assert.Equal(t, http.StatusCreated, serveAs(t, router, []string{"admin"}, "POST", "/employees", map[string]interface{}{"name": "Rahul Gupta", "salary": "1000"}).Code)
+
+	// viewers see every employee, their salary masked
+	rec := serveAs(t, router, []string{"viewer"}, "GET", "/employees/1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var employee map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &employee))
+	assert.Contains(t, employee, "salary")
+	assert.Nil(t, employee["salary"])
+	assert.Equal(t, "Rahul Gupta", employee["name"])
+	rec = serveAs(t, router, []string{"viewer"}, "GET", "/employees", nil)
+	assert.Contains(t, rec.Body.String(), `"salary":null`)
+	rec = serveAs(t, router, []string{"viewer"}, "GET", "/employees/search?q=rahul", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"salary":null`)
+	rec = serveAs(t, router, []string{"viewer"}, "GET", "/employees:export", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1,Rahul Gupta,,,USD,,,1", strings.Join(strings.Split(strings.Split(rec.Body.String(), "\n")[1], ",")[:8], ","))
+
+	// filtering and sorting by salary would tell it all the same
+	rec = serveAs(t, router, []string{"viewer"}, "GET", "/employees?salary_min=900", nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"missing_permission":"salaries:read"`)
+	assert.Equal(t, http.StatusForbidden, serveAs(t, router, []string{"viewer"}, "GET", "/employees:export?sort=-salary", nil).Code)
+
+	// payroll sees the salaries, admins the salaries in the audit trail
+	rec = serveAs(t, router, []string{"viewer", "payroll"}, "GET", "/employees/1", nil)
+	assert.Contains(t, rec.Body.String(), `"salary":"1000"`)
+	assert.Equal(t, http.StatusOK, serveAs(t, router, []string{"payroll"}, "GET", "/employees?sort=salary&salary_min=900", nil).Code)
+	rec = serveAs(t, router, []string{"admin"}, "GET", "/audit", nil)
+	assert.Contains(t, rec.Body.String(), `"field":"salary","before":null,"after":"1000"`)
+}
+
+func TestAuthorize_MaskedSalaryRoundTrip(t *testing.T) {
+	router := newAuthorizedRouter(t)
+	employee := map[string]interface{}{"name": "Rahul Gupta", "position": "Accountant", "salary": "1000", "currency": "EUR"}
+	assert.Equal(t, http.StatusCreated, serveAs(t, router, []string{"admin"}, "POST", "/employees", employee).Code)
+
+	// an editor puts back what they fetched, the masked salary stays as it is
+	rec := serveAs(t, router, []string{"hr-editor"}, "GET", "/employees/1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var fetched map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &fetched))
+	assert.Nil(t, fetched["salary"])
+	fetched["name"] = "Rahul K. Gupta"
+	assert.Equal(t, http.StatusNoContent, serveAs(t, router, []string{"hr-editor"}, "PUT", "/employees/1", fetched).Code)
+
+	rec = serveAs(t, router, []string{"admin"}, "GET", "/employees/1", nil)
+	var updated map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+	assert.Equal(t, "Rahul K. Gupta", updated["name"])
+	assert.Equal(t, "1000", updated["salary"])
+	assert.Equal(t, "EUR", updated["currency"])
+	assert.Equal(t, float64(2), updated["version"])
+	rec = serveAs(t, router, []string{"admin"}, "GET", "/audit", nil)
+	assert.NotContains(t, rec.Body.String(), `"field":"salary","before":"1000"`)
+}
+
+func TestAuthorize_ETags(t *testing.T) {
+	router := newAuthorizedRouter(t)
+	assert.Equal(t, http.StatusCreated, serveAs(t, router, []string{"admin"}, "POST", "/employees", map[string]interface{}{"name": "Rahul Gupta", "salary": "1000"}).Code)
+	serve := func(roles []string, method, header, etag string, body interface{}) *httptest.ResponseRecorder {
+		var buff bytes.Buffer
+		assert.NoError(t, json.NewEncoder(&buff).Encode(body))
+		req, err := http.NewRequest(method, "/employees/1", &buff)
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(header, etag)
+		claims := tokenClaims("jane.hr")
+		claims["roles"] = roles
+		req.Header.Set("Authorization", "Bearer "+signToken(t, "HS256", authorizationSecret, "", claims))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// masked and full representations have their own tags, and responses vary by caller
+	rec := serveAs(t, router, []string{"viewer"}, "GET", "/employees/1", nil)
+	assert.Equal(t, `"1-m"`, rec.Header().Get("ETag"))
+	assert.Equal(t, "Authorization", rec.Header().Get("Vary"))
+	assert.Equal(t, `"1"`, serveAs(t, router, []string{"admin"}, "GET", "/employees/1", nil).Header().Get("ETag"))
+
+	// a cached masked representation is not current for a caller who may read salaries, nor the other way round
+	assert.Equal(t, http.StatusNotModified, serve([]string{"viewer"}, "GET", "If-None-Match", `"1-m"`, nil).Code)
+	assert.Equal(t, http.StatusOK, serve([]string{"viewer"}, "GET", "If-None-Match", `"1"`, nil).Code)
+	assert.Equal(t, http.StatusOK, serve([]string{"admin"}, "GET", "If-None-Match", `W/"1-m"`, nil).Code)
+	assert.Equal(t, http.StatusNotModified, serve([]string{"admin"}, "GET", "If-None-Match", `W/"1"`, nil).Code)
+
+	// writes are conditional on the version, whichever tag of it the caller got
+	rec = serve([]string{"hr-editor"}, "PUT", "If-Match", `"1-m"`, map[string]interface{}{"id": 1, "name": "Rahul K. Gupta", "salary": nil})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, `"2-m"`, rec.Header().Get("ETag"))
+	assert.Equal(t, http.StatusPreconditionFailed, serve([]string{"hr-editor"}, "PUT", "If-Match", `"1-m"`, map[string]interface{}{"id": 1, "name": "Rahul Gupta", "salary": nil}).Code)
+}
+
+func TestAuthorize_SalaryWrites(t *testing.T) {
+	router := newAuthorizedRouter(t)
+	assert.Equal(t, http.StatusCreated, serveAs(t, router, []string{"admin"}, "POST", "/employees", map[string]interface{}{"name": "Rahul Gupta", "salary": "1000"}).Code)
+	editor := []string{"hr-editor"}
+	forbidden := func(rec *httptest.ResponseRecorder, permission controllers.Permission) {
+		t.Helper()
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"missing_permission":"`+string(permission)+`"`)
+	}
+
+	// editors create and replace employees, but set no salary or currency
+	forbidden(serveAs(t, router, editor, "POST", "/employees", map[string]interface{}{"name": "Amit Kumar", "salary": "999999"}), controllers.PermissionWriteSalaries)
+	forbidden(serveAs(t, router, editor, "POST", "/employees", map[string]interface{}{"name": "Amit Kumar", "currency": "EUR"}), controllers.PermissionWriteSalaries)
+	assert.Equal(t, http.StatusCreated, serveAs(t, router, editor, "POST", "/employees", map[string]interface{}{"name": "Amit Kumar", "salary": nil, "currency": "USD"}).Code)
+	forbidden(serveAs(t, router, editor, "PUT", "/employees/1", map[string]interface{}{"name": "Rahul Gupta", "salary": "999999"}), controllers.PermissionWriteSalaries)
+	// leaving the salary out of a replacement would reset it
+	forbidden(serveAs(t, router, editor, "PUT", "/employees/1", map[string]interface{}{"name": "Rahul Gupta"}), controllers.PermissionWriteSalaries)
+
+	patch := func(contentType, document string) *httptest.ResponseRecorder {
+		return serveBodyAs(t, router, editor, "PATCH", "/employees/1", contentType, strings.NewReader(document))
+	}
+	forbidden(patch("application/merge-patch+json", `{"salary": "999999"}`), controllers.PermissionWriteSalaries)
+	forbidden(patch("application/merge-patch+json", `{"currency": "EUR"}`), controllers.PermissionWriteSalaries)
+	forbidden(patch("application/json-patch+json", `[{"op": "remove", "path": "/salary"}]`), controllers.PermissionWriteSalaries)
+	// tests and copies of a masked salary would tell it
+	forbidden(patch("application/json-patch+json", `[{"op": "test", "path": "/salary", "value": "1000"}]`), controllers.PermissionReadSalaries)
+	forbidden(patch("application/json-patch+json", `[{"op": "copy", "from": "/salary", "path": "/name"}]`), controllers.PermissionReadSalaries)
+	assert.Equal(t, http.StatusOK, patch("application/merge-patch+json", `{"name": "Rahul K. Gupta"}`).Code)
+
+	forbidden(serveAs(t, router, editor, "POST", "/employees:batch", map[string]interface{}{"operations": []map[string]interface{}{
+		{"op": "create", "employee": map[string]interface{}{"name": "Priya Sharma"}},
+		{"op": "update", "id": 1, "employee": map[string]interface{}{"name": "Rahul Gupta", "salary": "999999"}},
+	}}), controllers.PermissionWriteSalaries)
+	rec := serveAs(t, router, editor, "POST", "/employees:batch", map[string]interface{}{"operations": []map[string]interface{}{
+		{"op": "update", "id": 1, "employee": map[string]interface{}{"name": "Rahul Gupta", "salary": nil}},
+	}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	upload := func(content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("file", "employees.csv")
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+		return serveBodyAs(t, router, editor, "POST", "/employees:import", writer.FormDataContentType(), &body)
+	}
+	forbidden(upload("name,salary\nPriya Sharma,999999\n"), controllers.PermissionWriteSalaries)
+	assert.Equal(t, http.StatusOK, upload("name,salary\nPriya Sharma,\n").Code)
+
+	// the editors left the salary alone, admins still set it
+	rec = serveAs(t, router, []string{"admin"}, "GET", "/employees/1", nil)
+	assert.Contains(t, rec.Body.String(), `"salary":"1000"`)
+	assert.Contains(t, rec.Body.String(), `"currency":"USD"`)
+	assert.Equal(t, http.StatusOK, serveBodyAs(t, router, []string{"admin"}, "PATCH", "/employees/1", "application/merge-patch+json", strings.NewReader(`{"salary": "1100"}`)).Code)
+}
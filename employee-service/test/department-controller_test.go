@@ -0,0 +1,130 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/controllers"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveJSON sends body encoded as JSON, or no body when it is nil
+func serveJSON(t *testing.T, router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var buff bytes.Buffer
+	if body != nil {
+		assert.NoError(t, json.NewEncoder(&buff).Encode(body))
+	}
+	req, err := http.NewRequest(method, path, &buff)
+	assert.NoError(t, err)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDepartmentController_Departments(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, _ := newEmployeeRouter(t, repository)
+
+		rec := serveJSON(t, router, "POST", "/departments", map[string]interface{}{"name": "Engineering", "description": "builds things"})
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var engineering models.Department
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &engineering))
+		assert.Equal(t, uint(1), engineering.ID)
+		assert.Equal(t, http.StatusCreated, serveJSON(t, router, "POST", "/departments", map[string]interface{}{"name": "Accounting"}).Code)
+
+		// names are required and unique
+		rec = serveJSON(t, router, "POST", "/departments", map[string]interface{}{"name": " "})
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"field":"name","code":"required"`)
+		rec = serveJSON(t, router, "POST", "/departments", map[string]interface{}{"name": "Engineering"})
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), "urn:employee-service:problem:duplicate")
+
+		rec = serveJSON(t, router, "PUT", "/departments/1", map[string]interface{}{"name": "Software Engineering"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, http.StatusConflict, serveJSON(t, router, "PUT", "/departments/2", map[string]interface{}{"name": "Software Engineering"}).Code)
+		assert.Equal(t, http.StatusNotFound, serveJSON(t, router, "PUT", "/departments/9", map[string]interface{}{"name": "Legal"}).Code)
+
+		rec = serveJSON(t, router, "GET", "/departments?page_size=1", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var departmentList controllers.DepartmentList
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &departmentList))
+		assert.Len(t, departmentList.Data, 1)
+		assert.Equal(t, "Accounting", departmentList.Data[0].Name)
+		assert.Equal(t, int64(2), departmentList.Page.Total)
+		assert.Equal(t, "/departments?page=2&page_size=1", departmentList.Links.Next)
+
+		rec = serveJSON(t, router, "GET", "/departments/1", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"Software Engineering"`)
+		assert.Equal(t, http.StatusNotFound, serveJSON(t, router, "GET", "/departments/9", nil).Code)
+	})
+}
+
+func TestDepartmentController_DepartmentEmployees(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, employeeDao := newEmployeeRouter(t, repository)
+		assert.Equal(t, http.StatusCreated, serveJSON(t, router, "POST", "/departments", map[string]interface{}{"name": "Engineering"}).Code)
+		assert.Equal(t, http.StatusCreated, serveJSON(t, router, "POST", "/departments", map[string]interface{}{"name": "Accounting"}).Code)
+
+		rec := serveJSON(t, router, "POST", "/employees", map[string]interface{}{"name": "Rahul Gupta", "position": "Software Developer", "department_id": 1})
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"department_id":1`)
+		assert.Equal(t, http.StatusCreated, serveJSON(t, router, "POST", "/employees", map[string]interface{}{"name": "Neha Reddy", "department_id": 1}).Code)
+		assert.Equal(t, http.StatusCreated, serveJSON(t, router, "POST", "/employees", map[string]interface{}{"name": "Amit Kumar", "department_id": 2}).Code)
+		assert.Equal(t, http.StatusCreated, serveJSON(t, router, "POST", "/employees", map[string]interface{}{"name": "Pooja Shah"}).Code)
+
+		// employees only join departments that exist
+		rec = serveJSON(t, router, "POST", "/employees", map[string]interface{}{"name": "Raj Gupta", "department_id": 9})
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"field":"department_id","code":"unknown_department"`)
+
+		var employeeList controllers.EmployeeList
+		rec = serveJSON(t, router, "GET", "/departments/1/employees?sort=name", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &employeeList))
+		assert.Equal(t, []string{"Neha Reddy", "Rahul Gupta"}, employeeNames(employeeList.Data))
+		assert.Equal(t, http.StatusNotFound, serveJSON(t, router, "GET", "/departments/9/employees", nil).Code)
+		rec = serveJSON(t, router, "GET", "/employees?department_id=2&department_id=1&sort=name", nil)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &employeeList))
+		assert.Equal(t, []string{"Amit Kumar", "Neha Reddy", "Rahul Gupta"}, employeeNames(employeeList.Data))
+
+		// a merge patch moves an employee, null takes it out of its department
+		req, err := http.NewRequest("PATCH", "/employees/2", bytes.NewBufferString(`{"department_id": null}`))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", models.MergePatchContentType)
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "department_id")
+
+		// a department with employees is only deleted when they are reassigned
+		rec = serveJSON(t, router, "DELETE", "/departments/1", nil)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), "urn:employee-service:problem:department-not-empty")
+		rec = serveJSON(t, router, "DELETE", "/departments/1?reassign_to=9", nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"field":"reassign_to","code":"unknown_department"`)
+		assert.Equal(t, http.StatusBadRequest, serveJSON(t, router, "DELETE", "/departments/1?reassign_to=x", nil).Code)
+		assert.Equal(t, http.StatusNoContent, serveJSON(t, router, "DELETE", "/departments/1?reassign_to=2", nil).Code)
+
+		moved, err := employeeDao.GetEmployee(1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), *moved.DepartmentID)
+		assert.Equal(t, uint(2), moved.Version)
+		assert.Equal(t, http.StatusNotFound, serveJSON(t, router, "GET", "/departments/1", nil).Code)
+
+		// deleted employees do not keep a department from being deleted
+		assert.Equal(t, http.StatusNoContent, serveJSON(t, router, "DELETE", "/employees/1", nil).Code)
+		assert.Equal(t, http.StatusNoContent, serveJSON(t, router, "DELETE", "/employees/3", nil).Code)
+		assert.Equal(t, http.StatusNoContent, serveJSON(t, router, "DELETE", "/departments/2", nil).Code)
+		rec = serveJSON(t, router, "POST", "/employees/1/restore", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "department_id")
+	})
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepartmentDao_DeleteDepartment(t *testing.T) {
+	sqlClient := newMigratedDB(t)
+	employeeDao, err := daos.NewEmployeeDao(sqlClient)
+	assert.NoError(t, err)
+	departmentDao := daos.NewDepartmentDao(sqlClient)
+
+	engineering, err := departmentDao.CreateDepartment(&models.Department{Name: "Engineering"})
+	assert.NoError(t, err)
+	accounting, err := departmentDao.CreateDepartment(&models.Department{Name: "Accounting"})
+	assert.NoError(t, err)
+	_, err = departmentDao.CreateDepartment(&models.Department{Name: "Accounting"})
+	assert.ErrorIs(t, err, sqls.ErrDuplicate)
+
+	assert.NoError(t, employeeDao.CreateEmployees([]*models.Employee{
+		{Name: "Rahul Gupta", DepartmentID: &engineering.ID},
+		{Name: "Neha Reddy", DepartmentID: &engineering.ID},
+		{Name: "Amit Kumar", DepartmentID: &accounting.ID},
+	}))
+	unknown := uint(42)
+	_, err = employeeDao.CreateEmployee(&models.Employee{Name: "Raj Gupta", DepartmentID: &unknown})
+	assert.ErrorIs(t, err, models.ErrInvalidEmployee)
+	_, err = employeeDao.PatchEmployee(3, 1, map[string]interface{}{"department_id": &unknown})
+	assert.ErrorIs(t, err, models.ErrInvalidEmployee)
+
+	page, err := employeeDao.GetEmployees(&models.EmployeeQuery{Filter: models.EmployeeFilter{DepartmentIDs: []uint{engineering.ID}}, Page: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Rahul Gupta", "Neha Reddy"}, employeeNames(page.Employees))
+
+	assert.ErrorIs(t, departmentDao.DeleteDepartment(int64(engineering.ID), 0, models.AuditContext{}), models.ErrDepartmentNotEmpty)
+	assert.ErrorIs(t, departmentDao.DeleteDepartment(int64(engineering.ID), int64(engineering.ID), models.AuditContext{}), models.ErrInvalidDepartment)
+	assert.NoError(t, employeeDao.DeleteEmployee(2, 0))
+	assert.NoError(t, departmentDao.DeleteDepartment(int64(engineering.ID), int64(accounting.ID), models.AuditContext{}))
+	_, err = departmentDao.GetDepartment(int64(engineering.ID))
+	assert.ErrorIs(t, err, sqls.ErrNotExists)
+
+	// deleted employees move along and are detached when their department goes without reassignment
+	assert.NoError(t, employeeDao.DeleteEmployee(1, 0))
+	assert.NoError(t, employeeDao.DeleteEmployee(3, 0))
+	assert.NoError(t, departmentDao.DeleteDepartment(int64(accounting.ID), 0, models.AuditContext{}))
+	restored, err := employeeDao.RestoreEmployee(2, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, restored.DepartmentID)
+	assert.Equal(t, uint(4), restored.Version)
+}
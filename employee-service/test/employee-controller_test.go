@@ -1,74 +1,1208 @@
 package test
 
 import (
+	"archive/zip"
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/controllers"
 	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/services"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
 )
 
-var employeeController, _ = controllers.NewEmployeeController()
-var employeeDao, _ = daos.NewEmployeeDao()
-var router = gin.Default()
+// employeeRepositories build fresh repositories of employees and departments, the controller tests run against
+// the SQL daos production uses as well as against the memory dao so that the two do not drift apart
+var employeeRepositories = map[string]func(t *testing.T) (daos.EmployeeRepository, daos.DepartmentRepository){
+	"sql": func(t *testing.T) (daos.EmployeeRepository, daos.DepartmentRepository) {
+		sqlClient := newMigratedDB(t)
+		employeeDao, err := daos.NewEmployeeDao(sqlClient)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return employeeDao, daos.NewDepartmentDao(sqlClient)
+	},
+	"memory": func(t *testing.T) (daos.EmployeeRepository, daos.DepartmentRepository) {
+		employeeDao := daos.NewEmployeeMemoryDao()
+		return employeeDao, employeeDao
+	},
+}
 
-func TestEmployeeController_CreateEmployee(t *testing.T) {
+// forEachRepository runs test once for every one of employeeRepositories
+func forEachRepository(t *testing.T, test func(t *testing.T, repository string)) {
+	for _, repository := range []string{"sql", "memory"} {
+		t.Run(repository, func(t *testing.T) {
+			test(t, repository)
+		})
+	}
+}
+
+// newEmployeeRouter serves the employee and department routes on top of fresh repositories, to admins
+func newEmployeeRouter(t *testing.T, repository string) (*gin.Engine, daos.EmployeeRepository) {
+	employeeDao, departmentDao := employeeRepositories[repository](t)
+	employeeService := services.NewEmployeeService(employeeDao)
+	employeeController := controllers.NewEmployeeController(employeeService)
+	departmentController := controllers.NewDepartmentController(services.NewDepartmentService(departmentDao), employeeService)
+
+	router := gin.New()
+	router.Use(gin.CustomRecovery(controllers.Recovered), controllers.RequestID())
+	router.Use(authorizeAsAdmin(t)...)
+	router.NoRoute(controllers.NoRoute)
 	router.POST("/employees", employeeController.CreateEmployee)
+	router.GET("/employees/:id", employeeController.FetchEmployee)
+	router.GET("/employees", employeeController.FetchEmployees)
+	router.GET("/employees:method", controllers.CustomMethods(map[string]gin.HandlerFunc{
+		"export":        employeeController.ExportEmployees,
+		"orgchart":      employeeController.ExportOrgChart,
+		"salary-report": employeeController.FetchSalaryReport,
+	}))
+	router.GET("/employees/:id/reports", employeeController.FetchDirectReports)
+	router.GET("/employees/:id/chain", employeeController.FetchManagementChain)
+	router.GET("/employees/:id/subtree", employeeController.FetchReportingSubtree)
+	router.GET("/employees/:id/salary-changes", employeeController.FetchSalaryChanges)
+	router.POST("/employees/:id/salary-changes", employeeController.CreateSalaryChange)
+	router.DELETE("/employees/:id/salary-changes/:change_id", employeeController.DeleteSalaryChange)
+	router.GET("/employees/:id/audit", employeeController.FetchEmployeeAudit)
+	router.GET("/audit", employeeController.FetchAuditEntries)
+	router.PUT("/employees/:id", employeeController.UpdateEmployee)
+	router.PATCH("/employees/:id", employeeController.PatchEmployee)
+	router.DELETE("/employees/:id", employeeController.DeleteEmployee)
+	router.GET("/employees/search", employeeController.SearchEmployees)
+	router.POST("/employees/:id/restore", employeeController.RestoreEmployee)
+	router.POST("/employees/random", employeeController.PushEmployee)
+	router.POST("/employees:method", controllers.CustomMethods(map[string]gin.HandlerFunc{
+		"batch":  employeeController.BatchEmployees,
+		"import": employeeController.ImportEmployees,
+	}))
+	router.POST("/departments", departmentController.CreateDepartment)
+	router.GET("/departments/:id", departmentController.FetchDepartment)
+	router.GET("/departments", departmentController.FetchDepartments)
+	router.PUT("/departments/:id", departmentController.UpdateDepartment)
+	router.DELETE("/departments/:id", departmentController.DeleteDepartment)
+	router.GET("/departments/:id/employees", departmentController.FetchDepartmentEmployees)
+	router.DELETE("/admin/employees/:id", controllers.RequireAdminKey("secret"), employeeController.PurgeEmployee)
+	return router, employeeDao
+}
 
-	// create buffer body
-	var buff bytes.Buffer
-	err := json.NewEncoder(&buff).Encode(map[string]interface{}{
-		"id": 209,
-		"name":     "John Doe",
-		"position": "Software Developer",
-		"salary":   268999.90,
-	})
-	assert.NoError(t, err)
+func TestEmployeeController_CreateEmployee(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, employeeDao := newEmployeeRouter(t, repository)
+
+		// create buffer body
+		var buff bytes.Buffer
+		err := json.NewEncoder(&buff).Encode(map[string]interface{}{
+			"id":       209,
+			"name":     "John Doe",
+			"position": "Software Developer",
+			"salary":   268999.90,
+		})
+		assert.NoError(t, err)
 
-	// create a create request
-	req, err1 := http.NewRequest("POST", "/employees", &buff)
-	assert.NoError(t, err1)
+		// create a create request
+		req, err1 := http.NewRequest("POST", "/employees", &buff)
+		assert.NoError(t, err1)
 
-	rec := httptest.NewRecorder()
-	router.ServeHTTP(rec, req)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
 
-	// check the status code
-	assert.Equal(t, http.StatusCreated, rec.Code)
+		// check the status code
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		// check the employee was stored
+		page, err := employeeDao.GetEmployees(&models.EmployeeQuery{Page: 1, Limit: 10})
+		assert.NoError(t, err)
+		assert.Len(t, page.Employees, 1)
+		assert.Equal(t, "John Doe", page.Employees[0].Name)
+	})
 }
 
 func TestEmployeeController_FetchEmployee(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, _ := newEmployeeRouter(t, repository)
+
+		req, err := http.NewRequest("POST", "/employees/random", nil)
+		assert.NoError(t, err)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		// check the status code
+
+		req1, err1 := http.NewRequest("GET", "/employees/1", nil)
+		assert.NoError(t, err1)
+		rec1 := httptest.NewRecorder()
+		router.ServeHTTP(rec1, req1)
+		assert.Equal(t, http.StatusOK, rec1.Code)
+
+		req2, err2 := http.NewRequest("GET", "/employees/1000", nil)
+		assert.NoError(t, err2)
+		rec2 := httptest.NewRecorder()
+		router.ServeHTTP(rec2, req2)
+		assert.Equal(t, http.StatusNotFound, rec2.Code)
+	})
+}
+
+func TestEmployeeController_FetchEmployees(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, _ := newEmployeeRouter(t, repository)
+
+		req, err := http.NewRequest("POST", "/employees/random", nil)
+		assert.NoError(t, err)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		req1, err1 := http.NewRequest("GET", "/employees?page=2&page_size=15", nil)
+		assert.NoError(t, err1)
+		rec1 := httptest.NewRecorder()
+		router.ServeHTTP(rec1, req1)
+		assert.Equal(t, http.StatusOK, rec1.Code)
+
+		var employeeList controllers.EmployeeList
+		assert.NoError(t, json.Unmarshal(rec1.Body.Bytes(), &employeeList))
+		assert.Len(t, employeeList.Data, 15)
+		assert.Equal(t, uint(16), employeeList.Data[0].ID)
+		assert.Equal(t, int64(40), employeeList.Page.Total)
+		assert.Equal(t, int64(3), employeeList.Page.TotalPages)
+		assert.True(t, employeeList.Page.HasNext)
+		assert.True(t, employeeList.Page.HasPrev)
+		assert.Equal(t, "/employees?page=3&page_size=15", employeeList.Links.Next)
+		assert.Equal(t, "/employees?page=1&page_size=15", employeeList.Links.Prev)
+		assert.Equal(t, "/employees?page=3&page_size=15", employeeList.Links.Last)
+	})
+}
+
+func TestEmployeeController_UpdateEmployee(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, employeeDao := newEmployeeRouter(t, repository)
+		employee, err := employeeDao.CreateEmployee(&models.Employee{Name: "John Doe", Position: "Accountant", Salary: models.RequireAmount("1000")})
+		assert.NoError(t, err)
+
+		var buff bytes.Buffer
+		assert.NoError(t, json.NewEncoder(&buff).Encode(map[string]interface{}{
+			"id":       employee.ID,
+			"name":     "John Doe",
+			"position": "Senior Accountant",
+			"salary":   2000,
+		}))
+		req, err := http.NewRequest("PUT", "/employees/1", &buff)
+		assert.NoError(t, err)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		updated, err := employeeDao.GetEmployee(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Senior Accountant", updated.Position)
+	})
+}
+
+func TestEmployeeController_Problems(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, _ := newEmployeeRouter(t, repository)
+		request := func(method, path string, header map[string]string) (*httptest.ResponseRecorder, controllers.Problem) {
+			req, err := http.NewRequest(method, path, nil)
+			assert.NoError(t, err)
+			for key, value := range header {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, controllers.ProblemContentType, rec.Header().Get("Content-Type"), path)
+			var problem controllers.Problem
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			return rec, problem
+		}
+
+		rec, problem := request("GET", "/employees/42", map[string]string{"X-Request-ID": "trace-42"})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, controllers.Problem{
+			Type:      "urn:employee-service:problem:not-found",
+			Title:     "Not found",
+			Status:    http.StatusNotFound,
+			Detail:    "row not exists",
+			Instance:  "/employees/42",
+			RequestID: "trace-42",
+		}, problem)
+		assert.Equal(t, "trace-42", rec.Header().Get("X-Request-ID"))
+
+		// request ids that are unsafe to log are replaced
+		rec, problem = request("GET", "/employees/abc", map[string]string{"X-Request-ID": "bad id\n"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "urn:employee-service:problem:bad-request", problem.Type)
+		assert.Equal(t, `invalid employee id "abc", expected a positive integer`, problem.Detail)
+		assert.Regexp(t, `^[0-9a-f]{32}$`, problem.RequestID)
+		assert.Equal(t, problem.RequestID, rec.Header().Get("X-Request-ID"))
+
+		rec, problem = request("GET", "/employees/1/nowhere", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "urn:employee-service:problem:not-found", problem.Type)
+		rec, problem = request("DELETE", "/admin/employees/1", nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "urn:employee-service:problem:unauthorized", problem.Type)
+
+		// database errors are not passed on
+		sqlClient := newMigratedDB(t)
+		employeeDao, err := daos.NewEmployeeDao(sqlClient)
+		assert.NoError(t, err)
+		employeeController := controllers.NewEmployeeController(services.NewEmployeeService(employeeDao))
+		router = gin.New()
+		router.Use(controllers.RequestID())
+		router.GET("/employees/:id", employeeController.FetchEmployee)
+		db, err := sqlClient.DB.DB()
+		assert.NoError(t, err)
+		assert.NoError(t, db.Close())
+		rec, problem = request("GET", "/employees/1", nil)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "urn:employee-service:problem:internal", problem.Type)
+		assert.NotContains(t, problem.Detail, "sql")
+	})
+}
+
+func TestEmployeeController_ValidateEmployee(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, employeeDao := newEmployeeRouter(t, repository)
+		// stored before the position catalog existed
+		_, err := employeeDao.CreateEmployee(&models.Employee{Name: "John Doe", Position: "Janitor", Salary: models.RequireAmount("1000")})
+		assert.NoError(t, err)
+
+		request := func(method, path, contentType, body string) (*httptest.ResponseRecorder, controllers.Problem) {
+			req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", contentType)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			var response controllers.Problem
+			_ = json.Unmarshal(rec.Body.Bytes(), &response)
+			return rec, response
+		}
+
+		rec, response := request("POST", "/employees", "application/json",
+			`{"name": " ", "position": "`+strings.Repeat("x", 101)+`", "salary": -1}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, []*models.FieldError{
+			{Field: "name", Code: "required", Message: "name is required"},
+			{Field: "position", Code: "too_long", Message: "position must be at most 100 characters long"},
+			{Field: "salary", Code: "too_small", Message: "salary must not be negative"},
+		}, response.Errors)
+
+		for body, want := range map[string]*models.FieldError{
+			`{"name": "Jane Doe", "position": "Astronaut"}`: {Field: "position", Code: "unknown_position", Message: `position "Astronaut" is not in the position catalog`},
+			`{"name": "Jane Doe", "salary": "lots"}`:        {Field: "salary", Code: "invalid_type", Message: "salary must be a decimal number"},
+			`{"name": 7}`:                                   {Field: "name", Code: "invalid_type", Message: "name must be a string"},
+			`{"name": "Jane Doe", "salary": "0.00001"}`:     {Field: "salary", Code: "invalid", Message: "salary must have at most 4 decimal places and be less than 100000000000000"},
+			`{"name": "Jane Doe", "currency": "DOL"}`:       {Field: "currency", Code: "unknown_currency", Message: `currency "DOL" is not an ISO 4217 currency code`},
+			`{"position": "Accountant"}`:                    {Field: "name", Code: "required", Message: "name is required"},
+		} {
+			rec, response := request("POST", "/employees", "application/json", body)
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code, body)
+			assert.Equal(t, []*models.FieldError{want}, response.Errors, body)
+		}
+		rec, _ = request("POST", "/employees", "application/json", `{"name": "Jane Doe", "position": "Accountant", "salary": 0}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		rec, response = request("PUT", "/employees/1", "application/json", `{"ID": 1, "name": "John Doe", "position": "Janitor"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, "unknown_position", response.Errors[0].Code)
+
+		// a patch is only held to the rules for the fields it changes
+		rec, _ = request("PATCH", "/employees/1", models.MergePatchContentType, `{"salary": 1200}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec, response = request("PATCH", "/employees/1", models.MergePatchContentType, `{"name": null, "salary": -5}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Len(t, response.Errors, 2)
+		rec, response = request("PATCH", "/employees/1", models.MergePatchContentType, `{"salary": "high"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, "invalid_type", response.Errors[0].Code)
+		stored, err := employeeDao.GetEmployee(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "John Doe", stored.Name)
+		assert.Equal(t, "1200", stored.Salary.String())
+
+		rec, _ = request("POST", "/employees:batch", "application/json", `{"mode": "best_effort", "operations": [
+		{"op": "create", "employee": {"name": "Amit Kumar", "position": "Accountant"}},
+		{"op": "create", "employee": {"name": "", "position": "Accountant"}}]}`)
+		assert.Equal(t, http.StatusMultiStatus, rec.Code)
+		var batch controllers.EmployeeBatchResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &batch))
+		assert.Equal(t, http.StatusUnprocessableEntity, batch.Results[1].Code)
+		assert.Equal(t, []*models.FieldError{{Field: "name", Code: "required", Message: "name is required"}}, batch.Results[1].Errors)
+	})
+}
+
+func TestEmployeeController_PatchEmployee(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, employeeDao := newEmployeeRouter(t, repository)
+		_, err := employeeDao.CreateEmployee(&models.Employee{Name: "John Doe", Position: "Accountant", Salary: models.RequireAmount("1000")})
+		assert.NoError(t, err)
+
+		patch := func(contentType, document string) *httptest.ResponseRecorder {
+			req, err := http.NewRequest("PATCH", "/employees/1", bytes.NewBufferString(document))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", contentType)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			return rec
+		}
+
+		// a merge patch leaves the omitted fields alone
+		rec := patch("application/merge-patch+json", `{"position": "Senior Accountant"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var employee models.Employee
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &employee))
+		assert.Equal(t, "John Doe", employee.Name)
+		assert.Equal(t, "Senior Accountant", employee.Position)
+		assert.Equal(t, "1000", employee.Salary.String())
+
+		rec = patch("application/json-patch+json", `[{"op": "test", "path": "/salary", "value": "1000"}, {"op": "replace", "path": "/salary", "value": 2500.5}]`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		stored, err := employeeDao.GetEmployee(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "2500.5", stored.Salary.String())
+		assert.Equal(t, "Senior Accountant", stored.Position)
+
+		for _, test := range []struct {
+			contentType, document string
+			want                  int
+		}{
+			{"application/json", `{"salary": 1}`, http.StatusUnsupportedMediaType},
+			{"application/merge-patch+json", `{"salary": `, http.StatusBadRequest},
+			{"application/json-patch+json", `{"op": "replace"}`, http.StatusBadRequest},
+			{"application/json-patch+json", `[{"op": "test", "path": "/salary", "value": "1000"}]`, http.StatusConflict},
+			{"application/json-patch+json", `[{"op": "replace", "path": "/ID", "value": 7}]`, http.StatusUnprocessableEntity},
+			{"application/merge-patch+json", `{"CreatedAt": "2020-01-01T00:00:00Z"}`, http.StatusUnprocessableEntity},
+			{"application/merge-patch+json", `{"salary": "high"}`, http.StatusUnprocessableEntity},
+		} {
+			assert.Equal(t, test.want, patch(test.contentType, test.document).Code, test.document)
+		}
+		unchanged, err := employeeDao.GetEmployee(1)
+		assert.NoError(t, err)
+		assert.Equal(t, stored, unchanged)
+
+		req, err := http.NewRequest("PATCH", "/employees/1000", bytes.NewBufferString(`{"salary": 1}`))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestEmployeeController_ConditionalRequests(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, employeeDao := newEmployeeRouter(t, repository)
+		_, err := employeeDao.CreateEmployee(&models.Employee{Name: "John Doe", Position: "Accountant", Salary: models.RequireAmount("1000")})
+		assert.NoError(t, err)
+
+		request := func(method, body string, headers map[string]string) *httptest.ResponseRecorder {
+			req, err := http.NewRequest(method, "/employees/1", bytes.NewBufferString(body))
+			assert.NoError(t, err)
+			for key, value := range headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			return rec
+		}
+
+		rec := request("GET", "", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		etag := rec.Header().Get("ETag")
+		assert.Equal(t, `"1"`, etag)
+
+		rec = request("GET", "", map[string]string{"If-None-Match": `"7", ` + etag})
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, http.StatusNotModified, request("GET", "", map[string]string{"If-None-Match": "W/" + etag}).Code)
+		assert.Equal(t, http.StatusOK, request("GET", "", map[string]string{"If-None-Match": `"7"`}).Code)
+		assert.Equal(t, http.StatusPreconditionFailed, request("GET", "", map[string]string{"If-Match": `"7"`}).Code)
+
+		// the first writer wins, the second one based on the same tag gets 412
+		rec = request("PATCH", `{"salary": 2000}`, map[string]string{"Content-Type": "application/merge-patch+json", "If-Match": etag})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+		rec = request("PUT", `{"ID": 1, "name": "John Doe", "position": "Accountant", "salary": 1500}`, map[string]string{"If-Match": etag})
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+		rec = request("PATCH", `{"salary": 1500}`, map[string]string{"Content-Type": "application/merge-patch+json", "If-Match": "W/" + etag})
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+		assert.Equal(t, http.StatusPreconditionFailed, request("DELETE", "", map[string]string{"If-Match": etag}).Code)
+		stored, err := employeeDao.GetEmployee(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "2000", stored.Salary.String())
+
+		// a version in the body works like If-Match
+		rec = request("PUT", `{"ID": 1, "name": "John Doe", "salary": 1500, "version": 1}`, nil)
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+		rec = request("PUT", `{"ID": 1, "name": "John Doe", "salary": 1500}`, map[string]string{"If-Match": `"1", "2"`})
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+
+		assert.Equal(t, http.StatusNoContent, request("DELETE", "", map[string]string{"If-Match": `"3"`}).Code)
+		assert.Equal(t, http.StatusNotFound, request("GET", "", nil).Code)
+	})
+}
+
+func TestEmployeeController_DeleteEmployee(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, employeeDao := newEmployeeRouter(t, repository)
+		_, err := employeeDao.CreateEmployee(&models.Employee{Name: "John Doe"})
+		assert.NoError(t, err)
+
+		request := func(method, path string, headers map[string]string) int {
+			req, err := http.NewRequest(method, path, nil)
+			assert.NoError(t, err)
+			for key, value := range headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		// deleted, then already deleted, and never existed
+		assert.Equal(t, http.StatusNoContent, request("DELETE", "/employees/1", nil))
+		assert.Equal(t, http.StatusNotFound, request("GET", "/employees/1", nil))
+		assert.Equal(t, http.StatusGone, request("DELETE", "/employees/1", nil))
+		assert.Equal(t, http.StatusNotFound, request("DELETE", "/employees/1000", nil))
+
+		// restoring brings the employee back once
+		assert.Equal(t, http.StatusPreconditionFailed, request("POST", "/employees/1/restore", map[string]string{"If-Match": `"5"`}))
+		assert.Equal(t, http.StatusOK, request("POST", "/employees/1/restore", map[string]string{"If-Match": `"1"`}))
+		assert.Equal(t, http.StatusConflict, request("POST", "/employees/1/restore", nil))
+		assert.Equal(t, http.StatusNotFound, request("POST", "/employees/1000/restore", nil))
+		restored, err := employeeDao.GetEmployee(1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), restored.Version)
+
+		// purging needs the admin key and is final
+		assert.Equal(t, http.StatusUnauthorized, request("DELETE", "/admin/employees/1", nil))
+		assert.Equal(t, http.StatusUnauthorized, request("DELETE", "/admin/employees/1", map[string]string{"X-Admin-Key": "guess"}))
+		assert.Equal(t, http.StatusNoContent, request("DELETE", "/admin/employees/1", map[string]string{"X-Admin-Key": "secret"}))
+		assert.Equal(t, http.StatusNotFound, request("DELETE", "/admin/employees/1", map[string]string{"X-Admin-Key": "secret"}))
+		assert.Equal(t, http.StatusNotFound, request("DELETE", "/employees/1", nil))
+		assert.Equal(t, http.StatusNotFound, request("POST", "/employees/1/restore", nil))
+	})
+}
+
+func TestEmployeeController_BatchEmployees(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, employeeDao := newEmployeeRouter(t, repository)
+		_, err := employeeDao.CreateEmployee(&models.Employee{Name: "John Doe", Position: "Accountant", Salary: models.RequireAmount("1000")})
+		assert.NoError(t, err)
+
+		batch := func(body string) (int, controllers.EmployeeBatchResponse) {
+			req, err := http.NewRequest("POST", "/employees:batch", bytes.NewBufferString(body))
+			assert.NoError(t, err)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			var response controllers.EmployeeBatchResponse
+			_ = json.Unmarshal(rec.Body.Bytes(), &response)
+			return rec.Code, response
+		}
+		operations := `[
+		{"op": "create", "employee": {"name": "Jane Doe", "position": "Accountant", "salary": 2000}},
+		{"op": "update", "id": 1, "employee": {"name": "John Doe", "position": "Senior Accountant", "salary": 1500}},
+		{"op": "delete", "id": 1000},
+		{"op": "delete", "id": 1, "version": 2}
+	]`
+
+		// atomic batches undo everything when one operation fails
+		code, response := batch(`{"operations": ` + operations + `}`)
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Equal(t, 0, response.Applied)
+		assert.Equal(t, 1, response.Failed)
+		var statuses []models.EmployeeBatchStatus
+		for _, item := range response.Results {
+			statuses = append(statuses, item.Status)
+		}
+		assert.Equal(t, []models.EmployeeBatchStatus{"rolled_back", "rolled_back", "failed", "skipped"}, statuses)
+		assert.Equal(t, http.StatusFailedDependency, response.Results[0].Code)
+		assert.Nil(t, response.Results[0].Employee)
+		page, err := employeeDao.GetEmployees(&models.EmployeeQuery{Page: 1, Limit: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"John Doe"}, employeeNames(page.Employees))
+		assert.Equal(t, "Accountant", page.Employees[0].Position)
+
+		// best effort batches keep whatever succeeded
+		code, response = batch(`{"mode": "best_effort", "operations": ` + operations + `}`)
+		assert.Equal(t, http.StatusMultiStatus, code)
+		assert.Equal(t, 3, response.Applied)
+		assert.Equal(t, 1, response.Failed)
+		codes := []int{}
+		for _, item := range response.Results {
+			codes = append(codes, item.Code)
+		}
+		assert.Equal(t, []int{http.StatusCreated, http.StatusOK, http.StatusNotFound, http.StatusNoContent}, codes)
+		assert.Equal(t, "Jane Doe", response.Results[0].Employee.Name)
+		assert.NotZero(t, response.Results[0].Employee.ID)
+		_, err = employeeDao.GetEmployee(1)
+		assert.ErrorIs(t, err, sqls.ErrNotExists)
+
+		code, response = batch(`{"operations": [{"op": "update", "id": 2, "employee": {"name": "Jane Roe"}}]}`)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, uint(2), response.Results[0].Employee.Version)
+
+		for _, invalid := range []string{`{"operations": []}`, `{"mode": "eventually", "operations": [{"op": "delete", "id": 2}]}`, `{"operations": [null]}`} {
+			code, _ := batch(invalid)
+			assert.Equal(t, http.StatusBadRequest, code, invalid)
+		}
+		code, response = batch(`{"operations": [{"op": "upsert", "id": 2}]}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, response.Results[0].Error, "unknown op")
+	})
+}
+
+func TestEmployeeController_ImportEmployees(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, employeeDao := newEmployeeRouter(t, repository)
+
+		upload := func(fileName string, content []byte, fields map[string]string) (int, models.EmployeeImportResult) {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			part, err := writer.CreateFormFile("file", fileName)
+			assert.NoError(t, err)
+			_, err = part.Write(content)
+			assert.NoError(t, err)
+			for key, value := range fields {
+				assert.NoError(t, writer.WriteField(key, value))
+			}
+			assert.NoError(t, writer.Close())
+
+			req, err := http.NewRequest("POST", "/employees:import", &body)
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			var result models.EmployeeImportResult
+			_ = json.Unmarshal(rec.Body.Bytes(), &result)
+			return rec.Code, result
+		}
+		countEmployees := func() int64 {
+			page, err := employeeDao.GetEmployees(&models.EmployeeQuery{Page: 1, Limit: 10})
+			assert.NoError(t, err)
+			return page.Total
+		}
+
+		csvFile := []byte("Full Name,Role,Annual Salary,Notes\nRahul Gupta,Software Developer,76000.25,\n,Accountant,53000,no name\n\nNeha Reddy,Software Developer,-1,\n")
+		mapping := `{"Full Name": "name", "role": "position", "Annual Salary": "salary"}`
+
+		// dry runs report the invalid rows by line
+		code, result := upload("staff.csv", csvFile, map[string]string{"mapping": mapping})
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 3, result.Rows)
+		assert.Equal(t, 1, result.Valid)
+		assert.Equal(t, 2, result.Invalid)
+		assert.Equal(t, []*models.EmployeeImportRowError{
+			{Row: 3, Column: "name", Code: "required", Error: "name is required"},
+			{Row: 5, Column: "salary", Code: "too_small", Error: "salary must not be negative"},
+		}, result.Errors)
+		assert.False(t, result.Committed)
+
+		// commits are all or nothing
+		code, result = upload("staff.csv", csvFile, map[string]string{"mapping": mapping, "mode": "commit"})
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.False(t, result.Committed)
+		assert.Equal(t, int64(0), countEmployees())
+
+		workbook := excelize.NewFile()
+		assert.NoError(t, workbook.SetSheetRow("Sheet1", "A1", &[]interface{}{"name", "position", "salary"}))
+		assert.NoError(t, workbook.SetSheetRow("Sheet1", "A2", &[]interface{}{"Rahul Gupta", "Software Developer", 76000.25}))
+		assert.NoError(t, workbook.SetSheetRow("Sheet1", "A3", &[]interface{}{"Amit Kumar", "Accountant", 53000}))
+		var xlsxFile bytes.Buffer
+		assert.NoError(t, workbook.Write(&xlsxFile))
 
-	router.GET("/employees", employeeController.FetchEmployee)
-
-	req, err := http.NewRequest("POST", "/employees/random", nil)
-	assert.NoError(t, err)
-	rec := httptest.NewRecorder()
-	router.ServeHTTP(rec, req)
-	assert.Equal(t, http.StatusCreated, rec.Code)
-	// check the status code
-
-	req1, err1 := http.NewRequest("GET", "/employees/1", nil)
-	assert.NoError(t, err1)
-	rec1 := httptest.NewRecorder()
-	router.ServeHTTP(rec1, req1)
-	assert.Equal(t, http.StatusOK, rec1.Code)
-
-}
-// func TestEmployeeController_DeleteEmployee(t *testing.T) {
-// 	// create a delete request
-// 	router.DELETE("/employees/:id", employeeController.DeleteEmployee)
-// 	// create a delete request
-// 	req, err := http.NewRequest("DELETE", "/employees/1", nil)
-// 	assert.NoError(t, err)
-// 	rec1 := httptest.NewRecorder()
-// 	router.ServeHTTP(rec1, req)
-
-// 	// check the status code
-// 	assert.Equal(t, http.StatusNoContent, rec1.Code)
-// 	assert.Equal(t, "", rec1.Body.String())
-// }
+		code, result = upload("staff.xlsx", xlsxFile.Bytes(), map[string]string{"mode": "commit"})
+		assert.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, 2, result.Created)
+		employee, err := employeeDao.GetEmployee(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Rahul Gupta", employee.Name)
+		assert.Equal(t, "76000.25", employee.Salary.String())
+
+		// departments and managers have to exist, dry runs tell which rows name others
+		references := []byte("name,department_id,manager_id\nDeepika Patel,,1\nNeha Reddy,7,42\nAmit Singh,,3\n")
+		code, result = upload("staff.csv", references, nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 1, result.Valid)
+		assert.Equal(t, 2, result.Invalid)
+		assert.Equal(t, []*models.EmployeeImportRowError{
+			{Row: 3, Column: "department_id", Code: "unknown_department", Error: "department 7 does not exist"},
+			{Row: 3, Column: "manager_id", Code: "unknown_manager", Error: "employee 42 does not exist"},
+			{Row: 4, Column: "manager_id", Code: "unknown_manager", Error: "employee 3 does not exist"},
+		}, result.Errors)
+		code, result = upload("staff.csv", references, map[string]string{"mode": "commit"})
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		assert.Len(t, result.Errors, 3)
+		assert.Equal(t, int64(2), countEmployees())
+
+		for name, fields := range map[string]map[string]string{
+			"unknown field":   {"mapping": `{"Full Name": "salary_band"}`},
+			"missing column":  {"mapping": `{"Surname": "name"}`},
+			"no name column":  {"mapping": `{"Role": "position"}`},
+			"invalid mapping": {"mapping": `{"Full Name"}`},
+			"invalid mode":    {"mode": "maybe"},
+			"unknown format":  {"format": "ods"},
+		} {
+			code, _ := upload("staff.csv", csvFile, fields)
+			assert.Equal(t, http.StatusBadRequest, code, name)
+		}
+		code, _ = upload("staff.xlsx", csvFile, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		// a workbook unzipping to more than ten times the upload limit is refused before it is read
+		var bomb bytes.Buffer
+		archive := zip.NewWriter(&bomb)
+		workbookArchive, err := zip.NewReader(bytes.NewReader(xlsxFile.Bytes()), int64(xlsxFile.Len()))
+		assert.NoError(t, err)
+		for _, file := range workbookArchive.File {
+			assert.NoError(t, archive.Copy(file))
+		}
+		part, err := archive.Create("xl/media/padding.bin")
+		assert.NoError(t, err)
+		zeros := make([]byte, 1<<20)
+		for i := 0; i <= 10*models.MaxEmployeeImportSize/len(zeros); i++ {
+			_, err = part.Write(zeros)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, archive.Close())
+		code, _ = upload("staff.xlsx", bomb.Bytes(), nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, int64(2), countEmployees())
+	})
+}
+
+func TestEmployeeController_ExportEmployees(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, employeeDao := newEmployeeRouter(t, repository)
+		assert.NoError(t, employeeDao.CreateEmployees([]*models.Employee{
+			{Name: "Rahul Gupta", Position: "Software Developer", Salary: models.RequireAmount("76000.25")},
+			{Name: "Deepika Patel, MBA", Position: "Marketing Specialist", Salary: models.RequireAmount("59000.50")},
+			{Name: "Amit Kumar", Position: "Accountant", Salary: models.RequireAmount("53000")},
+			{Name: "Neha Reddy", Position: "Software Developer", Salary: models.RequireAmount("74000.25")},
+		}))
+		assert.NoError(t, employeeDao.DeleteEmployee(4, 0))
+
+		export := func(query string) *httptest.ResponseRecorder {
+			req, err := http.NewRequest("GET", "/employees:export"+query, nil)
+			assert.NoError(t, err)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			return rec
+		}
+
+		rec := export("")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Regexp(t, `^attachment; filename="employees-\d{8}\.csv"$`, rec.Header().Get("Content-Disposition"))
+		records, err := csv.NewReader(rec.Body).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, records, 4)
+		assert.Equal(t, []string{"id", "name", "position", "salary", "currency", "department_id", "manager_id", "version", "created_at", "updated_at"}, records[0])
+		assert.Equal(t, []string{"2", "Deepika Patel, MBA", "Marketing Specialist", "59000.5", "USD", "", "", "1"}, records[2][:8])
+
+		rec = export("?format=ndjson&salary_min=55000&sort=-salary")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+		var names []string
+		decoder := json.NewDecoder(rec.Body)
+		for decoder.More() {
+			var employee models.Employee
+			assert.NoError(t, decoder.Decode(&employee))
+			names = append(names, employee.Name)
+		}
+		assert.Equal(t, []string{"Rahul Gupta", "Deepika Patel, MBA"}, names)
+
+		rec = export("?format=xlsx&position=Accountant")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Regexp(t, `\.xlsx"$`, rec.Header().Get("Content-Disposition"))
+		workbook, err := excelize.OpenReader(rec.Body)
+		assert.NoError(t, err)
+		rows, err := workbook.GetRows(workbook.GetSheetName(0))
+		assert.NoError(t, err)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, []string{"3", "Amit Kumar", "Accountant", "53000", "USD", "", "", "1"}, rows[1][:8])
+
+		// no employees still make a file with a header row
+		rec = export("?name_prefix=zz")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "id,name,position,salary,currency,department_id,manager_id,version,created_at,updated_at\n", rec.Body.String())
+
+		// formulas stay text, and the export imports again with its references
+		manager := uint(1)
+		assert.NoError(t, employeeDao.CreateEmployees([]*models.Employee{{Name: `=HYPERLINK("http://example.com")`, ManagerID: &manager}}))
+		rec = export("?name_prefix=%3D")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		exported := rec.Body.Bytes()
+		records, err = csv.NewReader(bytes.NewReader(exported)).ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"5", `'=HYPERLINK("http://example.com")`, "", "0", "USD", "", "1"}, records[1][:7])
+		rec = export("?format=xlsx&name_prefix=%3D")
+		workbook, err = excelize.OpenReader(rec.Body)
+		assert.NoError(t, err)
+		rows, err = workbook.GetRows(workbook.GetSheetName(0))
+		assert.NoError(t, err)
+		assert.Equal(t, `'=HYPERLINK("http://example.com")`, rows[1][1])
+
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("file", "employees.csv")
+		assert.NoError(t, err)
+		_, err = part.Write(exported)
+		assert.NoError(t, err)
+		assert.NoError(t, writer.WriteField("mode", "commit"))
+		assert.NoError(t, writer.Close())
+		req, err := http.NewRequest("POST", "/employees:import", &body)
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		imported, err := employeeDao.GetEmployee(6)
+		assert.NoError(t, err)
+		assert.Equal(t, `=HYPERLINK("http://example.com")`, imported.Name)
+		assert.Equal(t, &manager, imported.ManagerID)
+
+		for _, query := range []string{"?format=pdf", "?sort=bonus", "?salary_min=lots"} {
+			rec = export(query)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+			assert.Equal(t, controllers.ProblemContentType, rec.Header().Get("Content-Type"), query)
+			assert.Empty(t, rec.Header().Get("Content-Disposition"), query)
+		}
+	})
+}
+
+func TestEmployeeController_FetchEmployeesFiltered(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, _ := newEmployeeRouter(t, repository)
+
+		req, err := http.NewRequest("POST", "/employees/random", nil)
+		assert.NoError(t, err)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		req1, err1 := http.NewRequest("GET", "/employees?position=Accountant&position=Software+Developer&salary_min=55000&sort=-salary,name&page_size=100", nil)
+		assert.NoError(t, err1)
+		rec1 := httptest.NewRecorder()
+		router.ServeHTTP(rec1, req1)
+		assert.Equal(t, http.StatusOK, rec1.Code)
+
+		var employeeList controllers.EmployeeList
+		assert.NoError(t, json.Unmarshal(rec1.Body.Bytes(), &employeeList))
+		employees := employeeList.Data
+		assert.Len(t, employees, 14)
+		assert.Equal(t, "Sunita Gupta", employees[0].Name)
+		for i, employee := range employees {
+			assert.True(t, employee.Salary.GreaterThanOrEqual(models.RequireAmount("55000").Decimal))
+			if i > 0 {
+				assert.True(t, employee.Salary.LessThanOrEqual(employees[i-1].Salary.Decimal))
+			}
+		}
+
+		for _, invalid := range []string{"sort=password", "sort=name%3BDROP+TABLE+employees", "salary_min=abc", "created_from=yesterday", "cursor=garbage"} {
+			req2, err2 := http.NewRequest("GET", "/employees?"+invalid, nil)
+			assert.NoError(t, err2)
+			rec2 := httptest.NewRecorder()
+			router.ServeHTTP(rec2, req2)
+			assert.Equal(t, http.StatusBadRequest, rec2.Code, invalid)
+		}
+	})
+}
+
+func TestEmployeeController_FetchEmployeesCursor(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, employeeDao := newEmployeeRouter(t, repository)
+
+		req, err := http.NewRequest("POST", "/employees/random", nil)
+		assert.NoError(t, err)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		fetch := func(link string) controllers.EmployeeList {
+			req, err := http.NewRequest("GET", link, nil)
+			assert.NoError(t, err)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusOK, rec.Code, link)
+			var employeeList controllers.EmployeeList
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &employeeList))
+			return employeeList
+		}
+
+		first := fetch("/employees?sort=-salary&page_size=5&cursor=")
+		assert.Len(t, first.Data, 5)
+		assert.False(t, first.Page.HasPrev)
+		assert.True(t, first.Page.HasNext)
+		assert.Equal(t, "Deepak Desai", first.Data[0].Name)
+
+		// rows inserted or deleted before the cursor do not shift the next page
+		assert.NoError(t, employeeDao.DeleteEmployee(int64(first.Data[0].ID), 0))
+		_, err = employeeDao.CreateEmployee(&models.Employee{Name: "New Hire", Salary: models.RequireAmount("1000000")})
+		assert.NoError(t, err)
+
+		second := fetch(first.Links.Next)
+		assert.Len(t, second.Data, 5)
+		assert.True(t, second.Data[0].Salary.LessThan(first.Data[4].Salary.Decimal))
+		assert.True(t, second.Page.HasPrev)
+
+		back := fetch(second.Links.Prev)
+		assert.Equal(t, employeeNames(first.Data[1:]), employeeNames(back.Data[1:]))
+		assert.Equal(t, "New Hire", back.Data[0].Name)
+
+		// a cursor only fits the sort it was created for
+		req, err = http.NewRequest("GET", "/employees?sort=name&cursor="+first.Page.NextCursor, nil)
+		assert.NoError(t, err)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestEmployeeController_SearchEmployees(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, _ := newEmployeeRouter(t, repository)
+
+		req, err := http.NewRequest("POST", "/employees/random", nil)
+		assert.NoError(t, err)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		req1, err1 := http.NewRequest("GET", "/employees/search?q=desai+manager&page_size=3", nil)
+		assert.NoError(t, err1)
+		rec1 := httptest.NewRecorder()
+		router.ServeHTTP(rec1, req1)
+		assert.Equal(t, http.StatusOK, rec1.Code)
+
+		var results controllers.EmployeeSearchResults
+		assert.NoError(t, json.Unmarshal(rec1.Body.Bytes(), &results))
+		assert.Len(t, results.Data, 3)
+		// the SQL dao ranks with fts5 when SQLite was built with it, both highlight the same words here
+		assert.Contains(t, []string{"fts5", "like"}, results.Engine)
+		for _, hit := range results.Data {
+			assert.Equal(t, "Human Resources <mark>Manager</mark>", hit.Highlights["position"])
+			assert.Contains(t, hit.Highlights["name"], "<mark>Desai</mark>")
+		}
+
+		req2, err2 := http.NewRequest("GET", "/employees/search?q=+%2A%22", nil)
+		assert.NoError(t, err2)
+		rec2 := httptest.NewRecorder()
+		router.ServeHTTP(rec2, req2)
+		assert.Equal(t, http.StatusBadRequest, rec2.Code)
+	})
+}
+
+func TestEmployeeController_Hierarchy(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, employeeDao := newEmployeeRouter(t, repository)
+		for _, employee := range []map[string]interface{}{
+			{"name": "Divya Desai", "position": "Architect"},
+			{"name": "Rahul Gupta", "manager_id": 1},
+			{"name": "Amit Kumar", "manager_id": 1},
+			{"name": "Neha \"N\" Reddy", "manager_id": 2},
+			{"name": "Pooja Shah", "manager_id": 4},
+		} {
+			assert.Equal(t, http.StatusCreated, serveJSON(t, router, "POST", "/employees", employee).Code)
+		}
+
+		// managers have to exist and must not report to the employee
+		rec := serveJSON(t, router, "POST", "/employees", map[string]interface{}{"name": "Raj Gupta", "manager_id": 9})
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"field":"manager_id","code":"unknown_manager"`)
+		rec = serveJSON(t, router, "PUT", "/employees/2", map[string]interface{}{"id": 2, "name": "Rahul Gupta", "manager_id": 5})
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"manager_cycle","message":"employee 5 reports to employee 2 already"`)
+		req, err := http.NewRequest("PATCH", "/employees/1", bytes.NewBufferString(`{"manager_id": 1}`))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", models.MergePatchContentType)
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"manager_cycle"`)
+
+		var employeeList controllers.EmployeeList
+		rec = serveJSON(t, router, "GET", "/employees/1/reports?sort=name", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &employeeList))
+		assert.Equal(t, []string{"Amit Kumar", "Rahul Gupta"}, employeeNames(employeeList.Data))
+		assert.Equal(t, http.StatusNotFound, serveJSON(t, router, "GET", "/employees/9/reports", nil).Code)
+
+		var hierarchy controllers.EmployeeHierarchy
+		rec = serveJSON(t, router, "GET", "/employees/5/chain", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &hierarchy))
+		assert.Equal(t, []string{`Neha "N" Reddy`, "Rahul Gupta", "Divya Desai"}, employeeNames(hierarchy.Data))
+		rec = serveJSON(t, router, "GET", "/employees/1/subtree", nil)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &hierarchy))
+		assert.Equal(t, []string{"Rahul Gupta", "Amit Kumar", `Neha "N" Reddy`, "Pooja Shah"}, employeeNames(hierarchy.Data))
+
+		var orgChart controllers.OrgChart
+		rec = serveJSON(t, router, "GET", "/employees:orgchart?root=2", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &orgChart))
+		assert.Len(t, orgChart.Data, 1)
+		assert.Equal(t, "Rahul Gupta", orgChart.Data[0].Name)
+		assert.Equal(t, "Pooja Shah", orgChart.Data[0].Reports[0].Reports[0].Name)
+
+		rec = serveJSON(t, router, "GET", "/employees:orgchart?format=dot", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/vnd.graphviz; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, `digraph orgchart {
+	node [shape=box];
+	1 [label="Divya Desai\nArchitect"];
+	2 [label="Rahul Gupta"];
+	4 [label="Neha \"N\" Reddy"];
+	5 [label="Pooja Shah"];
+	3 [label="Amit Kumar"];
+	1 -> 2;
+	2 -> 4;
+	4 -> 5;
+	1 -> 3;
+}
+`, rec.Body.String())
+
+		// purging a manager leaves the reports without one
+		req, err = http.NewRequest("DELETE", "/admin/employees/4", nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-Admin-Key", "secret")
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		orphan, err := employeeDao.GetEmployee(5)
+		assert.NoError(t, err)
+		assert.Nil(t, orphan.ManagerID)
+		assert.Equal(t, uint(2), orphan.Version)
+	})
+}
+
+func TestEmployeeController_SalaryChanges(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, _ := newEmployeeRouter(t, repository)
+		assert.Equal(t, http.StatusCreated, serveJSON(t, router, "POST", "/employees", map[string]interface{}{"name": "Rahul Gupta", "salary": 1000}).Code)
+		assert.Equal(t, http.StatusNoContent, serveJSON(t, router, "PUT", "/employees/1", map[string]interface{}{"ID": 1, "name": "Rahul Gupta", "salary": 1100}).Code)
+
+		// a raise without a date is effective today, one with a later date is scheduled
+		rec := serveJSON(t, router, "POST", "/employees/1/salary-changes", map[string]interface{}{"salary": 1200, "reason": "merit increase", "author": "hr"})
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var change models.SalaryChange
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &change))
+		assert.Equal(t, models.Today(), change.EffectiveDate)
+		assert.False(t, change.Scheduled)
+		tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(time.DateOnly)
+		rec = serveJSON(t, router, "POST", "/employees/1/salary-changes", map[string]interface{}{"salary": 1500, "effective_date": tomorrow})
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &change))
+		assert.True(t, change.Scheduled)
+
+		var employee models.Employee
+		rec = serveJSON(t, router, "GET", "/employees/1", nil)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &employee))
+		assert.Equal(t, "1200", employee.Salary.String())
+
+		var history controllers.SalaryHistory
+		rec = serveJSON(t, router, "GET", "/employees/1/salary-changes", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &history))
+		if assert.Len(t, history.Data, 4) {
+			assert.Equal(t, []string{"1000", "1100", "1200", "1500"}, []string{history.Data[0].Salary.String(), history.Data[1].Salary.String(), history.Data[2].Salary.String(), history.Data[3].Salary.String()})
+			assert.Equal(t, "merit increase", history.Data[2].Reason)
+			assert.True(t, history.Data[3].Scheduled)
+		}
+
+		rec = serveJSON(t, router, "POST", "/employees/1/salary-changes", map[string]interface{}{"salary": -1, "effective_date": "2024-02-30"})
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"field":"effective_date","code":"invalid"`)
+		assert.Contains(t, rec.Body.String(), `"field":"salary","code":"too_small"`)
+		assert.Equal(t, http.StatusNotFound, serveJSON(t, router, "POST", "/employees/42/salary-changes", map[string]interface{}{"salary": 1}).Code)
+
+		// effective changes are history, scheduled ones can be canceled
+		assert.Equal(t, http.StatusConflict, serveJSON(t, router, "DELETE", "/employees/1/salary-changes/3", nil).Code)
+		assert.Equal(t, http.StatusNoContent, serveJSON(t, router, "DELETE", fmt.Sprintf("/employees/1/salary-changes/%d", change.ID), nil).Code)
+		assert.Equal(t, http.StatusNotFound, serveJSON(t, router, "DELETE", fmt.Sprintf("/employees/1/salary-changes/%d", change.ID), nil).Code)
+		assert.Equal(t, http.StatusBadRequest, serveJSON(t, router, "DELETE", "/employees/1/salary-changes/abc", nil).Code)
+	})
+}
+
+func TestEmployeeController_SalaryCurrency(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, _ := newEmployeeRouter(t, repository)
+
+		// amounts come back exactly as strings, whether they were sent as strings or numbers
+		rec := serveJSON(t, router, "POST", "/employees", map[string]interface{}{"name": "Rahul Gupta", "salary": "0.1", "currency": "EUR"})
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"salary":"0.1","currency":"EUR"`)
+		rec = serveJSON(t, router, "POST", "/employees", map[string]interface{}{"name": "Deepika Patel", "salary": 76000.25})
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"salary":"76000.25","currency":"USD"`)
+
+		// an update without a currency keeps the one of the employee
+		assert.Equal(t, http.StatusNoContent, serveJSON(t, router, "PUT", "/employees/1", map[string]interface{}{"ID": 1, "name": "Rahul Gupta", "salary": "0.2"}).Code)
+		var employee models.Employee
+		rec = serveJSON(t, router, "GET", "/employees/1", nil)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &employee))
+		assert.Equal(t, "0.2", employee.Salary.String())
+		assert.Equal(t, "EUR", employee.Currency)
+
+		var employeeList controllers.EmployeeList
+		rec = serveJSON(t, router, "GET", "/employees?currency=EUR&currency=GBP", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &employeeList))
+		assert.Equal(t, []string{"Rahul Gupta"}, employeeNames(employeeList.Data))
+		rec = serveJSON(t, router, "GET", "/employees?salary_min=76000.25&salary_max=76000.25", nil)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &employeeList))
+		assert.Equal(t, []string{"Deepika Patel"}, employeeNames(employeeList.Data))
+
+		// the salary history follows the currency of the employee
+		rec = serveJSON(t, router, "POST", "/employees/1/salary-changes", map[string]interface{}{"salary": "0.3"})
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"salary":"0.3","currency":"EUR"`)
+		assert.Equal(t, http.StatusBadRequest, serveJSON(t, router, "GET", "/employees?salary_min=1e-5", nil).Code)
+	})
+}
+
+func TestEmployeeController_SalaryReport(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, employeeDao := newEmployeeRouter(t, repository)
+		assert.NoError(t, employeeDao.CreateEmployees([]*models.Employee{
+			{Name: "Amit Kumar", Position: "Accountant", Salary: models.RequireAmount("1000"), Currency: "USD"},
+			{Name: "Rahul Singh", Position: "Accountant", Salary: models.RequireAmount("2000"), Currency: "USD"},
+			{Name: "Sunita Gupta", Position: "Accountant", Salary: models.RequireAmount("4000"), Currency: "USD"},
+			{Name: "Rahul Gupta", Position: "Software Developer", Salary: models.RequireAmount("3000"), Currency: "USD"},
+			{Name: "Neha Reddy", Position: "Software Developer", Salary: models.RequireAmount("100.5"), Currency: "EUR"},
+		}))
+
+		var report controllers.SalaryReport
+		rec := serveJSON(t, router, "GET", "/employees:salary-report?group_by=position&percentile=90&band_width=1500", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		if assert.Len(t, report.Data, 3) {
+			accountants := report.Data[0]
+			assert.Equal(t, map[string]interface{}{"position": "Accountant"}, accountants.Group)
+			assert.Equal(t, "USD", accountants.Currency)
+			assert.Equal(t, 3, accountants.Headcount)
+			assert.Equal(t, []string{"7000", "2333.3333", "2000", "1000", "4000", "3600"}, []string{
+				accountants.Total.String(), accountants.Average.String(), accountants.Median.String(),
+				accountants.Min.String(), accountants.Max.String(), accountants.Percentiles["90"].String(),
+			})
+			if assert.Len(t, accountants.Bands, 3) {
+				assert.Equal(t, "1500", accountants.Bands[1].From.String())
+				assert.Equal(t, "3000", accountants.Bands[1].To.String())
+				assert.Equal(t, 1, accountants.Bands[1].Headcount)
+			}
+			// salaries in different currencies are never added up
+			assert.Equal(t, []string{"EUR", "USD"}, []string{report.Data[1].Currency, report.Data[2].Currency})
+			assert.Equal(t, "100.5", report.Data[1].Median.String())
+		}
+
+		rec = serveJSON(t, router, "GET", "/employees:salary-report?currency=USD&salary_min=2000", nil)
+		report = controllers.SalaryReport{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		if assert.Len(t, report.Data, 1) {
+			assert.Empty(t, report.Data[0].Group)
+			assert.Equal(t, 3, report.Data[0].Headcount)
+			assert.Equal(t, "3000", report.Data[0].Median.String())
+			assert.Nil(t, report.Data[0].Bands)
+		}
+
+		rec = serveJSON(t, router, "GET", "/employees:salary-report?position=Janitor", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"data": []}`, rec.Body.String())
+
+		for _, query := range []string{"group_by=salary", "group_by=position,position", "percentile=101", "percentile=high", "band_width=0", "band_width=0.00001"} {
+			rec = serveJSON(t, router, "GET", "/employees:salary-report?"+query, nil)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+	})
+}
+
+func TestEmployeeController_AuditTrail(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, _ := newEmployeeRouter(t, repository)
+		serve := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+			var buff bytes.Buffer
+			assert.NoError(t, json.NewEncoder(&buff).Encode(body))
+			req, err := http.NewRequest(method, path, &buff)
+			assert.NoError(t, err)
+			req.Header.Set("Authorization", adminAuthorization(t, "alice"))
+			req.Header.Set(controllers.RequestIDHeader, "req-"+method)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			return rec
+		}
+		assert.Equal(t, http.StatusCreated, serve("POST", "/departments", map[string]interface{}{"name": "Engineering"}).Code)
+		assert.Equal(t, http.StatusCreated, serve("POST", "/departments", map[string]interface{}{"name": "Research"}).Code)
+		assert.Equal(t, http.StatusCreated, serve("POST", "/employees", map[string]interface{}{"name": "Rahul Gupta", "salary": "1000", "department_id": 1}).Code)
+		assert.Equal(t, http.StatusNoContent, serve("PUT", "/employees/1", map[string]interface{}{"ID": 1, "name": "Rahul K. Gupta", "salary": "1000", "department_id": 1}).Code)
+		assert.Equal(t, http.StatusNoContent, serve("DELETE", "/departments/1?reassign_to=2", nil).Code)
+
+		// a failed atomic batch leaves no trace
+		rec := serveJSON(t, router, "POST", "/employees:batch", map[string]interface{}{"mode": "atomic", "operations": []map[string]interface{}{
+			{"op": "create", "employee": map[string]interface{}{"name": "Amit Kumar"}},
+			{"op": "delete", "id": 42},
+		}})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		var auditLog controllers.AuditLog
+		rec = serveJSON(t, router, "GET", "/employees/1/audit", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &auditLog))
+		assert.Equal(t, int64(3), auditLog.Page.Total)
+		assert.Equal(t, models.AuditCreate, auditLog.Data[0].Operation)
+		assert.Equal(t, "alice", auditLog.Data[0].Actor)
+		assert.Equal(t, "req-POST", auditLog.Data[0].RequestID)
+		assert.Len(t, auditLog.Data[0].Changes, 5)
+		assert.Equal(t, "name", auditLog.Data[1].Changes[0].Field)
+		assert.JSONEq(t, `"Rahul K. Gupta"`, string(auditLog.Data[1].Changes[0].After))
+		assert.Equal(t, "req-DELETE", auditLog.Data[2].RequestID)
+		assert.Equal(t, "department_id", auditLog.Data[2].Changes[0].Field)
+		assert.JSONEq(t, `2`, string(auditLog.Data[2].Changes[0].After))
+
+		auditLog = controllers.AuditLog{}
+		rec = serveJSON(t, router, "GET", "/audit", nil)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &auditLog))
+		assert.Equal(t, int64(3), auditLog.Page.Total)
+		auditLog = controllers.AuditLog{}
+		rec = serveJSON(t, router, "GET", "/audit?operation=update&request_id=req-PUT&page_size=1", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &auditLog))
+		assert.Len(t, auditLog.Data, 1)
+		assert.Equal(t, int64(1), auditLog.Page.Total)
+		assert.Equal(t, http.StatusNotFound, serveJSON(t, router, "GET", "/employees/9/audit", nil).Code)
+		assert.Equal(t, http.StatusBadRequest, serveJSON(t, router, "GET", "/audit?operation=rename", nil).Code)
+		assert.Equal(t, http.StatusBadRequest, serveJSON(t, router, "GET", "/audit?from=yesterday", nil).Code)
+	})
+}
+
+func TestEmployeeController_AsOf(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, repository string) {
+		router, _ := newEmployeeRouter(t, repository)
+		assert.Equal(t, http.StatusCreated, serveJSON(t, router, "POST", "/employees", map[string]interface{}{"name": "Rahul Gupta", "position": "Software Developer", "salary": "1000"}).Code)
+		time.Sleep(time.Millisecond)
+		hired := url.QueryEscape(time.Now().Format(time.RFC3339Nano))
+		time.Sleep(time.Millisecond)
+		assert.Equal(t, http.StatusNoContent, serveJSON(t, router, "PUT", "/employees/1", map[string]interface{}{"ID": 1, "name": "Rahul Gupta", "position": "Accountant", "salary": "1000"}).Code)
+		assert.Equal(t, http.StatusNoContent, serveJSON(t, router, "DELETE", "/employees/1", nil).Code)
+		assert.Equal(t, http.StatusNotFound, serveJSON(t, router, "GET", "/employees/1", nil).Code)
+
+		var employee models.Employee
+		rec := serveJSON(t, router, "GET", "/employees/1?as_of="+hired, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &employee))
+		assert.Equal(t, "Software Developer", employee.Position)
+
+		var employeeList controllers.EmployeeList
+		rec = serveJSON(t, router, "GET", "/employees?position=Software+Developer&as_of="+hired, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &employeeList))
+		assert.Equal(t, []string{"Rahul Gupta"}, employeeNames(employeeList.Data))
+		assert.Equal(t, http.StatusNotFound, serveJSON(t, router, "GET", "/employees/1?as_of=2000-01-01", nil).Code)
+		assert.Equal(t, http.StatusBadRequest, serveJSON(t, router, "GET", "/employees/1?as_of=yesterday", nil).Code)
+		assert.Equal(t, http.StatusBadRequest, serveJSON(t, router, "GET", "/employees?cursor=abc&as_of="+hired, nil).Code)
+	})
+}
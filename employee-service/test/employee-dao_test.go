@@ -0,0 +1,627 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newSeededEmployeeDao(t *testing.T) *daos.EmployeeDao {
+	employeeDao, err := daos.NewEmployeeDao(newMigratedDB(t))
+	assert.NoError(t, err)
+	assert.NoError(t, employeeDao.CreateEmployees([]*models.Employee{
+		{Name: "Rahul Gupta", Position: "Software Developer", Salary: models.RequireAmount("76000.25")},
+		{Name: "Deepika Patel", Position: "Marketing Specialist", Salary: models.RequireAmount("59000.50")},
+		{Name: "Amit Kumar", Position: "Accountant", Salary: models.RequireAmount("53000.00")},
+		{Name: "Rahul_Singh", Position: "Accountant", Salary: models.RequireAmount("64000.50")},
+		{Name: "Neha Reddy", Position: "Software Developer", Salary: models.RequireAmount("74000.25")},
+	}))
+	return employeeDao
+}
+
+func employeeNames(employees []*models.Employee) []string {
+	names := make([]string, 0, len(employees))
+	for _, employee := range employees {
+		names = append(names, employee.Name)
+	}
+	return names
+}
+
+func TestEmployeeDao_GetEmployeesFiltered(t *testing.T) {
+	employeeDao := newSeededEmployeeDao(t)
+	salaryMin, salaryMax := models.RequireAmount("60000"), models.RequireAmount("75000")
+	exactSalary := models.RequireAmount("76000.25")
+	now := time.Now().UTC()
+	later := now.Add(time.Hour)
+
+	for name, test := range map[string]struct {
+		filter models.EmployeeFilter
+		sort   []models.EmployeeSort
+		want   []string
+	}{
+		"name prefix is case-insensitive": {
+			filter: models.EmployeeFilter{NamePrefix: "rahul"},
+			want:   []string{"Rahul Gupta", "Rahul_Singh"},
+		},
+		"name wildcards match literally": {
+			filter: models.EmployeeFilter{NameContains: "_"},
+			want:   []string{"Rahul_Singh"},
+		},
+		"the escape character matches literally": {
+			filter: models.EmployeeFilter{NamePrefix: "rahul!_"},
+			want:   []string{},
+		},
+		"positions and salary range": {
+			filter: models.EmployeeFilter{Positions: []string{"Accountant", "Software Developer"}, SalaryMin: &salaryMin, SalaryMax: &salaryMax},
+			sort:   []models.EmployeeSort{{Field: "salary", Desc: true}},
+			want:   []string{"Neha Reddy", "Rahul_Singh"},
+		},
+		"currencies and an exact salary": {
+			filter: models.EmployeeFilter{Currencies: []string{"EUR", "USD"}, SalaryMin: &exactSalary, SalaryMax: &exactSalary},
+			want:   []string{"Rahul Gupta"},
+		},
+		"no employee paid in the currency": {
+			filter: models.EmployeeFilter{Currencies: []string{"EUR"}},
+			want:   []string{},
+		},
+		"created range": {
+			filter: models.EmployeeFilter{CreatedFrom: &later},
+			want:   []string{},
+		},
+		"multi-field sort": {
+			sort: []models.EmployeeSort{{Field: "position"}, {Field: "name", Desc: true}},
+			want: []string{"Rahul_Singh", "Amit Kumar", "Deepika Patel", "Rahul Gupta", "Neha Reddy"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			page, err := employeeDao.GetEmployees(&models.EmployeeQuery{Filter: test.filter, Sort: test.sort, Page: 1, Limit: 10})
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, employeeNames(page.Employees))
+			assert.Equal(t, int64(len(test.want)), page.Total)
+		})
+	}
+}
+
+func TestEmployeeDao_GetEmployeesKeyset(t *testing.T) {
+	employeeDao := newSeededEmployeeDao(t)
+	sort := []models.EmployeeSort{{Field: "position"}, {Field: "salary", Desc: true}}
+
+	var names []string
+	query := &models.EmployeeQuery{Sort: sort, Limit: 2, Cursor: models.NewEmployeeStartCursor(sort)}
+	for {
+		page, err := employeeDao.GetEmployees(query)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), page.Total)
+		names = append(names, employeeNames(page.Employees)...)
+		if !page.HasNext {
+			break
+		}
+		query.Cursor = models.NewEmployeeCursor(page.Employees[len(page.Employees)-1], sort, false)
+	}
+	assert.Equal(t, []string{"Rahul_Singh", "Amit Kumar", "Deepika Patel", "Rahul Gupta", "Neha Reddy"}, names)
+
+	// walking backwards from the last row returns the rows before it in listing order
+	last, err := employeeDao.GetEmployees(&models.EmployeeQuery{Sort: sort, Limit: 2, Cursor: query.Cursor})
+	assert.NoError(t, err)
+	page, err := employeeDao.GetEmployees(&models.EmployeeQuery{Sort: sort, Limit: 2, Cursor: models.NewEmployeeCursor(last.Employees[0], sort, true)})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Deepika Patel", "Rahul Gupta"}, employeeNames(page.Employees))
+	assert.True(t, page.HasPrev)
+	assert.True(t, page.HasNext)
+}
+
+func TestEmployeeDao_SearchEmployees(t *testing.T) {
+	employeeDao := newSeededEmployeeDao(t)
+	search := func(text string) *models.EmployeeSearchResult {
+		result, err := employeeDao.SearchEmployees(&models.EmployeeSearchQuery{Terms: models.SearchTerms(text), Limit: 10})
+		assert.NoError(t, err)
+		return result
+	}
+
+	// the fts5 engine highlights whole words, the like fallback the matched part only
+	result := search("rahul")
+	assert.ElementsMatch(t, []string{"Rahul Gupta", "Rahul_Singh"}, hitNames(result))
+	assert.Contains(t, []string{"<mark>Rahul</mark> Gupta", "<mark>Rahul</mark>_Singh"}, result.Hits[0].Highlights["name"])
+
+	// every word has to match, by name or by position
+	result = search("soft nEHa")
+	assert.Equal(t, []string{"Neha Reddy"}, hitNames(result))
+	assert.Contains(t, result.Hits[0].Highlights["position"], "<mark>Soft")
+	assert.Equal(t, "<mark>Neha</mark> Reddy", result.Hits[0].Highlights["name"])
+
+	// highlights are HTML, the text around the matches is escaped
+	_, err := employeeDao.CreateEmployee(&models.Employee{Name: "<img src=x onerror=alert(1)>", Position: "Tester"})
+	assert.NoError(t, err)
+	result = search("onerror")
+	assert.Equal(t, "&lt;img src=x <mark>onerror</mark>=alert(1)&gt;", result.Hits[0].Highlights["name"])
+
+	// the index follows updates and deletes
+	employee, err := employeeDao.GetEmployee(5)
+	assert.NoError(t, err)
+	employee.Name = "Neha Kapoor"
+	_, err = employeeDao.UpdateEmployee(5, employee)
+	assert.NoError(t, err)
+	assert.Empty(t, hitNames(search("reddy")))
+	assert.Equal(t, []string{"Neha Kapoor"}, hitNames(search("kapoor")))
+
+	assert.NoError(t, employeeDao.DeleteEmployee(5, 0))
+	assert.Empty(t, hitNames(search("kapoor")))
+
+	// the limit keeps the best matches, not the first ones
+	for i := 0; i < 12; i++ {
+		_, err = employeeDao.CreateEmployee(&models.Employee{Name: "Isabel Costa"})
+		assert.NoError(t, err)
+	}
+	_, err = employeeDao.CreateEmployee(&models.Employee{Name: "Bel Ortiz"})
+	assert.NoError(t, err)
+	result, err = employeeDao.SearchEmployees(&models.EmployeeSearchQuery{Terms: models.SearchTerms("bel"), Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bel Ortiz"}, hitNames(result))
+
+	// case is folded beyond ASCII, by the searches and the name filters alike
+	_, err = employeeDao.CreateEmployee(&models.Employee{Name: "Émile Zola", Position: "Ingénieur"})
+	assert.NoError(t, err)
+	result = search("émile INGÉNIEUR")
+	assert.Equal(t, []string{"Émile Zola"}, hitNames(result))
+	assert.Equal(t, "<mark>Émile</mark> Zola", result.Hits[0].Highlights["name"])
+	page, err := employeeDao.GetEmployees(&models.EmployeeQuery{Filter: models.EmployeeFilter{NamePrefix: "émi", NameContains: "ZOLA"}, Page: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Émile Zola"}, employeeNames(page.Employees))
+
+	// query syntax is not passed through
+	assert.Empty(t, hitNames(search(`"rahul" OR NEAR(`)))
+}
+
+func hitNames(result *models.EmployeeSearchResult) []string {
+	names := []string{}
+	for _, hit := range result.Hits {
+		names = append(names, hit.Employee.Name)
+	}
+	return names
+}
+
+func TestEmployeeDao_CreateEmployeeDuplicate(t *testing.T) {
+	employeeDao := newSeededEmployeeDao(t)
+
+	// an explicit id that is taken fails like it does in memory, and stores nothing
+	_, err := employeeDao.CreateEmployee(&models.Employee{Model: gorm.Model{ID: 2}, Name: "Divya Desai"})
+	assert.ErrorIs(t, err, sqls.ErrDuplicate)
+	assert.ErrorIs(t, employeeDao.CreateEmployees([]*models.Employee{{Name: "Divya Desai"}, {Model: gorm.Model{ID: 3}, Name: "Ravi Verma"}}), sqls.ErrDuplicate)
+	page, err := employeeDao.GetEmployees(&models.EmployeeQuery{Page: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), page.Total)
+}
+
+func TestEmployeeDao_PatchEmployee(t *testing.T) {
+	employeeDao := newSeededEmployeeDao(t)
+	before, err := employeeDao.GetEmployee(3)
+	assert.NoError(t, err)
+
+	patched, err := employeeDao.PatchEmployee(3, 1, map[string]interface{}{"position": "Senior Accountant"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Amit Kumar", patched.Name)
+	assert.Equal(t, "Senior Accountant", patched.Position)
+	assert.Equal(t, "53000", patched.Salary.String())
+	assert.Equal(t, uint(2), patched.Version)
+	assert.False(t, patched.UpdatedAt.Before(before.UpdatedAt))
+
+	// a patch based on an outdated version is refused
+	_, err = employeeDao.PatchEmployee(3, 1, map[string]interface{}{"salary": models.RequireAmount("1")})
+	assert.ErrorIs(t, err, sqls.ErrVersionMismatch)
+
+	_, err = employeeDao.PatchEmployee(1000, 1, map[string]interface{}{"salary": models.RequireAmount("1")})
+	assert.ErrorIs(t, err, sqls.ErrNotExists)
+}
+
+func TestEmployeeDao_UpdateEmployeeVersion(t *testing.T) {
+	employeeDao := newSeededEmployeeDao(t)
+
+	updated, err := employeeDao.UpdateEmployee(1, &models.Employee{Model: gorm.Model{ID: 1}, Name: "Rahul Gupta", Salary: models.RequireAmount("80000"), Version: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), updated.Version)
+	assert.False(t, updated.CreatedAt.IsZero())
+
+	// the second of two writers based on the same version loses
+	_, err = employeeDao.UpdateEmployee(1, &models.Employee{Model: gorm.Model{ID: 1}, Name: "Rahul G.", Version: 1})
+	assert.ErrorIs(t, err, sqls.ErrVersionMismatch)
+	assert.ErrorIs(t, employeeDao.DeleteEmployee(1, 1), sqls.ErrVersionMismatch)
+	stored, err := employeeDao.GetEmployee(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Rahul Gupta", stored.Name)
+
+	// version 0 writes whatever is stored
+	updated, err = employeeDao.UpdateEmployee(1, &models.Employee{Model: gorm.Model{ID: 1}, Name: "Rahul G."})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), updated.Version)
+	assert.NoError(t, employeeDao.DeleteEmployee(1, 3))
+	_, err = employeeDao.GetEmployee(1)
+	assert.ErrorIs(t, err, sqls.ErrNotExists)
+}
+
+func TestEmployeeDao_DeleteRestorePurge(t *testing.T) {
+	employeeDao := newSeededEmployeeDao(t)
+	search := func(text string) []string {
+		result, err := employeeDao.SearchEmployees(&models.EmployeeSearchQuery{Terms: models.SearchTerms(text), Limit: 10})
+		assert.NoError(t, err)
+		return hitNames(result)
+	}
+
+	assert.ErrorIs(t, employeeDao.DeleteEmployee(1000, 0), sqls.ErrNotExists)
+	assert.NoError(t, employeeDao.DeleteEmployee(2, 0))
+	assert.ErrorIs(t, employeeDao.DeleteEmployee(2, 0), sqls.ErrDeleted)
+	assert.Empty(t, search("deepika"))
+
+	_, err := employeeDao.RestoreEmployee(2, 7)
+	assert.ErrorIs(t, err, sqls.ErrVersionMismatch)
+	restored, err := employeeDao.RestoreEmployee(2, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Deepika Patel", restored.Name)
+	assert.Equal(t, uint(2), restored.Version)
+	assert.False(t, restored.DeletedAt.Valid)
+	assert.Equal(t, []string{"Deepika Patel"}, search("deepika"))
+	_, err = employeeDao.RestoreEmployee(2, 0)
+	assert.ErrorIs(t, err, sqls.ErrNotDeleted)
+
+	// purging works on live and deleted employees alike
+	assert.NoError(t, employeeDao.PurgeEmployee(2))
+	assert.NoError(t, employeeDao.DeleteEmployee(3, 0))
+	assert.NoError(t, employeeDao.PurgeEmployee(3))
+	assert.ErrorIs(t, employeeDao.PurgeEmployee(3), sqls.ErrNotExists)
+	_, err = employeeDao.RestoreEmployee(3, 0)
+	assert.ErrorIs(t, err, sqls.ErrNotExists)
+	assert.Empty(t, search("deepika"))
+}
+
+func TestEmployeeDao_BatchEmployees(t *testing.T) {
+	employeeDao := newSeededEmployeeDao(t)
+	operations := func() []*models.EmployeeBatchOperation {
+		return []*models.EmployeeBatchOperation{
+			{Op: models.EmployeeBatchCreate, Employee: &models.Employee{Name: "Sanjay Mishra", Position: "Marketing Specialist"}},
+			{Op: models.EmployeeBatchUpdate, ID: 1, Version: 1, Employee: &models.Employee{Name: "Rahul Gupta", Position: "Architect"}},
+			{Op: models.EmployeeBatchDelete, ID: 2, Version: 5},
+		}
+	}
+
+	results, err := employeeDao.BatchEmployees(operations(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, models.EmployeeBatchRolledBack, results[0].Status)
+	assert.Equal(t, models.EmployeeBatchFailed, results[2].Status)
+	assert.ErrorIs(t, results[2].Err, sqls.ErrVersionMismatch)
+	page, err := employeeDao.GetEmployees(&models.EmployeeQuery{Page: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Employees, 5)
+	assert.Equal(t, "Software Developer", page.Employees[0].Position)
+	search, err := employeeDao.SearchEmployees(&models.EmployeeSearchQuery{Terms: []string{"sanjay"}, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, search.Hits)
+
+	results, err = employeeDao.BatchEmployees(operations(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, models.EmployeeBatchApplied, results[0].Status)
+	assert.Equal(t, models.EmployeeBatchApplied, results[1].Status)
+	assert.Equal(t, "Architect", results[1].Employee.Position)
+	assert.Equal(t, uint(2), results[1].Employee.Version)
+	assert.Equal(t, models.EmployeeBatchFailed, results[2].Status)
+	created, err := employeeDao.GetEmployee(int64(results[0].Employee.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, "Sanjay Mishra", created.Name)
+}
+
+func TestEmployeeDao_ExportEmployees(t *testing.T) {
+	employeeDao := newSeededEmployeeDao(t)
+	assert.NoError(t, employeeDao.DeleteEmployee(5, 0))
+
+	var exported []*models.Employee
+	collect := func(employee *models.Employee) error {
+		exported = append(exported, employee)
+		return nil
+	}
+	assert.NoError(t, employeeDao.ExportEmployees(&models.EmployeeFilter{}, nil, collect))
+	assert.Equal(t, []string{"Rahul Gupta", "Deepika Patel", "Amit Kumar", "Rahul_Singh"}, employeeNames(exported))
+	assert.Equal(t, uint(1), exported[0].Version)
+	assert.False(t, exported[0].CreatedAt.IsZero())
+
+	exported = nil
+	assert.NoError(t, employeeDao.ExportEmployees(&models.EmployeeFilter{Positions: []string{"Accountant"}},
+		[]models.EmployeeSort{{Field: "salary", Desc: true}}, collect))
+	assert.Equal(t, []string{"Rahul_Singh", "Amit Kumar"}, employeeNames(exported))
+
+	// an error of visit stops the export
+	stop := errors.New("stop")
+	visited := 0
+	err := employeeDao.ExportEmployees(&models.EmployeeFilter{}, nil, func(*models.Employee) error {
+		visited++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, visited)
+}
+
+func TestEmployeeDao_Hierarchy(t *testing.T) {
+	employeeDao, err := daos.NewEmployeeDao(newMigratedDB(t))
+	assert.NoError(t, err)
+	managerID := func(id uint) *uint { return &id }
+	for _, employee := range []*models.Employee{
+		{Name: "Divya Desai"},
+		{Name: "Rahul Gupta", ManagerID: managerID(1)},
+		{Name: "Amit Kumar", ManagerID: managerID(1)},
+		{Name: "Neha Reddy", ManagerID: managerID(2)},
+		{Name: "Pooja Shah", ManagerID: managerID(4)},
+	} {
+		_, err := employeeDao.CreateEmployee(employee)
+		assert.NoError(t, err)
+	}
+
+	chain, err := employeeDao.GetManagementChain(5)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Neha Reddy", "Rahul Gupta", "Divya Desai"}, employeeNames(chain))
+	subtree, err := employeeDao.GetReportingSubtree(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Rahul Gupta", "Amit Kumar", "Neha Reddy", "Pooja Shah"}, employeeNames(subtree))
+	_, err = employeeDao.GetReportingSubtree(42)
+	assert.ErrorIs(t, err, sqls.ErrNotExists)
+
+	// closing a cycle fails, moving a subtree elsewhere does not
+	_, err = employeeDao.PatchEmployee(2, 1, map[string]interface{}{"manager_id": managerID(5)})
+	assert.ErrorIs(t, err, models.ErrInvalidEmployee)
+	_, err = employeeDao.UpdateEmployee(1, &models.Employee{Model: gorm.Model{ID: 1}, Name: "Divya Desai", ManagerID: managerID(4)})
+	assert.ErrorIs(t, err, models.ErrInvalidEmployee)
+	_, err = employeeDao.PatchEmployee(4, 1, map[string]interface{}{"manager_id": managerID(3)})
+	assert.NoError(t, err)
+	chain, err = employeeDao.GetManagementChain(5)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Neha Reddy", "Amit Kumar", "Divya Desai"}, employeeNames(chain))
+
+	// a deleted manager ends the chain and hides their reports from the subtree
+	assert.NoError(t, employeeDao.DeleteEmployee(3, 0))
+	chain, err = employeeDao.GetManagementChain(5)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Neha Reddy"}, employeeNames(chain))
+	subtree, err = employeeDao.GetReportingSubtree(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Rahul Gupta"}, employeeNames(subtree))
+
+	assert.NoError(t, employeeDao.PurgeEmployee(3))
+	orphan, err := employeeDao.GetEmployee(4)
+	assert.NoError(t, err)
+	assert.Nil(t, orphan.ManagerID)
+}
+
+func TestEmployeeDao_SalaryChanges(t *testing.T) {
+	sqlClient := newMigratedDB(t)
+	employeeDao, err := daos.NewEmployeeDao(sqlClient)
+	assert.NoError(t, err)
+	_, err = employeeDao.CreateEmployee(&models.Employee{Name: "Rahul Gupta", Salary: models.RequireAmount("1000")})
+	assert.NoError(t, err)
+
+	// every write that changes the salary is recorded, others are not
+	_, err = employeeDao.UpdateEmployee(1, &models.Employee{Model: gorm.Model{ID: 1}, Name: "Rahul Gupta", Salary: models.RequireAmount("1200")})
+	assert.NoError(t, err)
+	_, err = employeeDao.UpdateEmployee(1, &models.Employee{Model: gorm.Model{ID: 1}, Name: "Rahul K. Gupta", Salary: models.RequireAmount("1200")})
+	assert.NoError(t, err)
+	_, err = employeeDao.PatchEmployee(1, 3, map[string]interface{}{"salary": models.RequireAmount("1300")})
+	assert.NoError(t, err)
+
+	// a scheduled raise waits for its day, a backdated change older than the current one changes nothing
+	today := time.Now().UTC()
+	raise, err := employeeDao.CreateSalaryChange(&models.SalaryChange{EmployeeID: 1, Salary: models.RequireAmount("2000"), EffectiveDate: models.Date(today.AddDate(0, 0, 1).Format(time.DateOnly)), Reason: "promotion", Author: "hr"})
+	assert.NoError(t, err)
+	_, err = employeeDao.CreateSalaryChange(&models.SalaryChange{EmployeeID: 1, Salary: models.RequireAmount("900"), EffectiveDate: models.Date(today.AddDate(0, 0, -1).Format(time.DateOnly))})
+	assert.NoError(t, err)
+	employee, err := employeeDao.GetEmployee(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "1300", employee.Salary.String())
+	assert.Equal(t, uint(4), employee.Version)
+
+	changes, err := employeeDao.GetSalaryChanges(1)
+	assert.NoError(t, err)
+	salaries := make([]string, 0, len(changes))
+	for _, change := range changes {
+		salaries = append(salaries, change.Salary.String())
+	}
+	assert.Equal(t, []string{"900", "1000", "1200", "1300", "2000"}, salaries)
+	assert.Equal(t, models.SalaryReasonInitial, changes[1].Reason)
+	assert.Equal(t, models.SalaryReasonUpdate, changes[3].Reason)
+	assert.Equal(t, "hr", changes[4].Author)
+	_, err = employeeDao.GetSalaryChanges(42)
+	assert.ErrorIs(t, err, sqls.ErrNotExists)
+
+	// only scheduled changes can be canceled
+	assert.ErrorIs(t, employeeDao.DeleteSalaryChange(1, int64(changes[3].ID)), models.ErrSalaryChangeEffective)
+	assert.NoError(t, employeeDao.DeleteSalaryChange(1, int64(raise.ID)))
+	assert.ErrorIs(t, employeeDao.DeleteSalaryChange(1, int64(raise.ID)), sqls.ErrNotExists)
+
+	// a change that became effective since is applied by the next read, here of a dao that has not read yet,
+	// and stamps the update in UTC whatever the local time zone
+	assert.NoError(t, sqlClient.DB.Create(&models.SalaryChange{EmployeeID: 1, Salary: models.RequireAmount("1500"), EffectiveDate: models.Today()}).Error)
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*60*60)
+	t.Cleanup(func() { time.Local = local })
+	employeeDao, err = daos.NewEmployeeDao(sqlClient)
+	assert.NoError(t, err)
+	employee, err = employeeDao.GetEmployee(1)
+	time.Local = local
+	assert.NoError(t, err)
+	assert.Equal(t, "1500", employee.Salary.String())
+	assert.Equal(t, uint(5), employee.Version)
+	var updatedAt string
+	assert.NoError(t, sqlClient.DB.Raw("SELECT CAST(updated_at AS TEXT) FROM employees WHERE id = 1").Scan(&updatedAt).Error)
+	assert.Regexp(t, `\+00:00$`, updatedAt)
+
+	assert.NoError(t, employeeDao.PurgeEmployee(1))
+	var count int64
+	assert.NoError(t, sqlClient.DB.Model(&models.SalaryChange{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestEmployeeDao_GetSalaryReport(t *testing.T) {
+	employeeDao := newSeededEmployeeDao(t)
+	_, err := employeeDao.CreateEmployee(&models.Employee{Name: "Divya Desai", Position: "Accountant", Salary: models.RequireAmount("61000"), Currency: "EUR"})
+	assert.NoError(t, err)
+
+	stats, err := employeeDao.GetSalaryReport(&models.SalaryReportQuery{GroupBy: []string{"position"}})
+	assert.NoError(t, err)
+	assert.Len(t, stats, 4)
+	accountants := stats[1]
+	assert.Equal(t, map[string]interface{}{"position": "Accountant"}, accountants.Group)
+	assert.Equal(t, "USD", accountants.Currency)
+	assert.Equal(t, 2, accountants.Headcount)
+	assert.Equal(t, []string{"117000.5", "58500.25", "58500.25", "53000", "64000.5"}, []string{
+		accountants.Total.String(), accountants.Average.String(), accountants.Median.String(), accountants.Min.String(), accountants.Max.String(),
+	})
+
+	// the aggregates match what the report makes of every salary, whatever the grouping
+	for _, groupBy := range [][]string{nil, {"position"}, {"department", "hire_year"}, {"hire_year", "position"}} {
+		query := &models.SalaryReportQuery{GroupBy: groupBy, Filter: models.EmployeeFilter{Positions: []string{"Accountant", "Software Developer"}}}
+		report := models.NewSalaryReport(query)
+		assert.NoError(t, employeeDao.ExportEmployees(&query.Filter, nil, report.Add))
+		stats, err := employeeDao.GetSalaryReport(query)
+		assert.NoError(t, err)
+		assert.Equal(t, report.Stats(), stats, groupBy)
+	}
+}
+
+func TestEmployeeDao_AuditTrail(t *testing.T) {
+	sqlClient := newMigratedDB(t)
+	employeeDao, err := daos.NewEmployeeDao(sqlClient)
+	assert.NoError(t, err)
+	audited := employeeDao.WithAudit(models.AuditContext{Actor: "alice", RequestID: "req-1"})
+	_, err = audited.CreateEmployee(&models.Employee{Name: "Divya Desai", Salary: models.RequireAmount("90000")})
+	assert.NoError(t, err)
+	managerID := uint(1)
+	_, err = audited.CreateEmployee(&models.Employee{Name: "Rahul Gupta", Salary: models.RequireAmount("1000"), ManagerID: &managerID})
+	assert.NoError(t, err)
+
+	_, err = audited.PatchEmployee(2, 1, map[string]interface{}{"salary": models.RequireAmount("1200")})
+	assert.NoError(t, err)
+	assert.NoError(t, audited.DeleteEmployee(2, 0))
+	_, err = audited.RestoreEmployee(2, 0)
+	assert.NoError(t, err)
+	// purging the manager detaches the report, both are audited
+	assert.NoError(t, audited.PurgeEmployee(1))
+	// salaries taking effect on their own are nobody's change
+	assert.NoError(t, sqlClient.DB.Create(&models.SalaryChange{EmployeeID: 2, Salary: models.RequireAmount("1500"), EffectiveDate: models.Today()}).Error)
+	employeeDao, err = daos.NewEmployeeDao(sqlClient)
+	assert.NoError(t, err)
+	_, err = employeeDao.GetEmployee(2)
+	assert.NoError(t, err)
+
+	page, err := employeeDao.GetAuditEntries(&models.AuditQuery{EmployeeIDs: []uint{2}, Page: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), page.Total)
+	operations := make([]string, 0, len(page.Entries))
+	for _, entry := range page.Entries {
+		operations = append(operations, entry.Operation)
+	}
+	assert.Equal(t, []string{models.AuditCreate, models.AuditUpdate, models.AuditDelete, models.AuditRestore, models.AuditUpdate, models.AuditUpdate}, operations)
+	assert.Equal(t, "alice", page.Entries[1].Actor)
+	assert.Equal(t, "req-1", page.Entries[1].RequestID)
+	assert.Len(t, page.Entries[1].Changes, 1)
+	assert.Equal(t, "salary", page.Entries[1].Changes[0].Field)
+	assert.JSONEq(t, `"1000"`, string(page.Entries[1].Changes[0].Before))
+	assert.JSONEq(t, `"1200"`, string(page.Entries[1].Changes[0].After))
+	assert.Equal(t, "deleted_at", page.Entries[2].Changes[0].Field)
+	assert.Equal(t, "manager_id", page.Entries[4].Changes[0].Field)
+	assert.JSONEq(t, `1`, string(page.Entries[4].Changes[0].Before))
+	assert.Empty(t, page.Entries[5].Actor)
+	assert.JSONEq(t, `"1500"`, string(page.Entries[5].Changes[0].After))
+
+	// the trail outlives purged employees
+	page, err = employeeDao.GetAuditEntries(&models.AuditQuery{Actor: "alice", Operations: []string{models.AuditPurge}, Page: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 1)
+	assert.Equal(t, uint(1), page.Entries[0].EmployeeID)
+	assert.JSONEq(t, `"Divya Desai"`, string(page.Entries[0].Changes[0].Before))
+	assert.JSONEq(t, `null`, string(page.Entries[0].Changes[0].After))
+
+	// entries are only ever added
+	assert.Error(t, sqlClient.DB.Exec("UPDATE audit_entries SET actor = 'mallory'").Error)
+	assert.Error(t, sqlClient.DB.Exec("DELETE FROM audit_entries").Error)
+}
+
+func TestEmployeeDao_GetEmployeeAsOf(t *testing.T) {
+	employeeDao, err := daos.NewEmployeeDao(newMigratedDB(t))
+	assert.NoError(t, err)
+	beforeCreation := time.Now()
+	time.Sleep(time.Millisecond)
+	assert.NoError(t, employeeDao.CreateEmployees([]*models.Employee{
+		{Name: "Rahul Gupta", Position: "Software Developer", Salary: models.RequireAmount("1000")},
+		{Name: "Amit Kumar", Position: "Accountant", Salary: models.RequireAmount("900")},
+	}))
+	time.Sleep(time.Millisecond)
+	hired := time.Now()
+	time.Sleep(time.Millisecond)
+	_, err = employeeDao.PatchEmployee(1, 1, map[string]interface{}{"salary": models.RequireAmount("1200"), "position": "Team Lead"})
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	promoted := time.Now()
+	time.Sleep(time.Millisecond)
+	assert.NoError(t, employeeDao.DeleteEmployee(1, 0))
+
+	employee, err := employeeDao.GetEmployeeAsOf(1, hired)
+	assert.NoError(t, err)
+	assert.Equal(t, "Software Developer", employee.Position)
+	assert.Equal(t, "1000", employee.Salary.String())
+	assert.Equal(t, uint(1), employee.Version)
+	// deleted since, the employee was around then
+	employee, err = employeeDao.GetEmployeeAsOf(1, promoted)
+	assert.NoError(t, err)
+	assert.Equal(t, "Team Lead", employee.Position)
+	assert.Equal(t, "1200", employee.Salary.String())
+	assert.Equal(t, uint(2), employee.Version)
+	assert.False(t, employee.DeletedAt.Valid)
+	_, err = employeeDao.GetEmployeeAsOf(1, time.Now())
+	assert.ErrorIs(t, err, sqls.ErrNotExists)
+	_, err = employeeDao.GetEmployeeAsOf(2, beforeCreation)
+	assert.ErrorIs(t, err, sqls.ErrNotExists)
+
+	// filters apply to the employees as they were
+	page, err := employeeDao.GetEmployees(&models.EmployeeQuery{Filter: models.EmployeeFilter{Positions: []string{"Software Developer", "Accountant"}}, Page: 1, Limit: 10, AsOf: &hired})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Rahul Gupta", "Amit Kumar"}, employeeNames(page.Employees))
+	page, err = employeeDao.GetEmployees(&models.EmployeeQuery{Filter: models.EmployeeFilter{Positions: []string{"Software Developer", "Accountant"}}, Page: 1, Limit: 10, AsOf: &promoted})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Amit Kumar"}, employeeNames(page.Employees))
+
+	// pages merge the employees rewound with those unchanged since
+	for i, name := range []string{"Amit Kumar", "Rahul Gupta"} {
+		page, err = employeeDao.GetEmployees(&models.EmployeeQuery{Sort: []models.EmployeeSort{{Field: "name"}}, Page: i + 1, Limit: 1, AsOf: &promoted})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), page.Total)
+		assert.Equal(t, []string{name}, employeeNames(page.Employees))
+	}
+	page, err = employeeDao.GetEmployees(&models.EmployeeQuery{Page: 1, Limit: 10, AsOf: &beforeCreation})
+	assert.NoError(t, err)
+	assert.Zero(t, page.Total)
+}
+
+func TestEmployeeDao_CreateEmployeeTimestamps(t *testing.T) {
+	employeeDao, err := daos.NewEmployeeDao(newMigratedDB(t))
+	assert.NoError(t, err)
+	for name, employeeRepository := range map[string]daos.EmployeeRepository{"sql": employeeDao, "memory": daos.NewEmployeeMemoryDao()} {
+		// the timestamps are the ones of the insert, a client can neither backdate nor delete a new employee
+		backdated := time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+		created, err := employeeRepository.CreateEmployee(&models.Employee{
+			Model: gorm.Model{CreatedAt: backdated, UpdatedAt: backdated, DeletedAt: gorm.DeletedAt{Time: backdated, Valid: true}},
+			Name:  "Rahul Gupta",
+		})
+		assert.NoError(t, err, name)
+		assert.WithinDuration(t, time.Now(), created.CreatedAt, time.Minute, name)
+		assert.WithinDuration(t, time.Now(), created.UpdatedAt, time.Minute, name)
+		assert.False(t, created.DeletedAt.Valid, name)
+		assert.NoError(t, employeeRepository.CreateEmployees([]*models.Employee{{Model: gorm.Model{CreatedAt: backdated}, Name: "Amit Kumar"}}), name)
+
+		_, err = employeeRepository.GetEmployee(int64(created.ID))
+		assert.NoError(t, err, name)
+		asOf := backdated.AddDate(1, 0, 0)
+		page, err := employeeRepository.GetEmployees(&models.EmployeeQuery{Page: 1, Limit: 10, AsOf: &asOf})
+		assert.NoError(t, err, name)
+		assert.Zero(t, page.Total, name)
+	}
+}
@@ -0,0 +1,163 @@
+package test
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MrAzharuddin/employee-crud/employee-service/config"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls/migrations"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMigratedDB opens a throwaway SQLite database with every migration applied
+func newMigratedDB(t *testing.T) *sqls.SQLClient {
+	sqlClient := openTestDB(t, &config.DatabaseConfig{
+		Driver:   config.DatabaseDriverSQLite,
+		FilePath: filepath.Join(t.TempDir(), "employees.db"),
+		Mode:     config.DatabaseModePersistent,
+	})
+	migrator, err := migrations.NewMigrator(sqlClient)
+	if !assert.NoError(t, err) || !assert.NoError(t, migrator.Up(0)) {
+		t.FailNow()
+	}
+	return sqlClient
+}
+
+func TestMigrator_UpDown(t *testing.T) {
+	sqlClient := openTestDB(t, &config.DatabaseConfig{
+		Driver:   config.DatabaseDriverSQLite,
+		FilePath: filepath.Join(t.TempDir(), "employees.db"),
+		Mode:     config.DatabaseModePersistent,
+	})
+	migrator, err := migrations.NewMigrator(sqlClient)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, migrator.Verify(), migrations.ErrPending)
+
+	assert.NoError(t, migrator.Up(0))
+	assert.NoError(t, migrator.Verify())
+	assert.True(t, sqlClient.DB.Migrator().HasTable(&models.Employee{}))
+	assert.NoError(t, sqlClient.DB.Create(&models.Employee{Name: "John Doe"}).Error)
+
+	// running again is a no-op
+	assert.NoError(t, migrator.Up(0))
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.True(t, status.Applied, status.Name)
+	}
+
+	// rolling everything back drops the tables
+	assert.NoError(t, migrator.Down(len(statuses)))
+	assert.False(t, sqlClient.DB.Migrator().HasTable(&models.Employee{}))
+	assert.ErrorIs(t, migrator.Verify(), migrations.ErrPending)
+}
+
+func TestMigrator_ConcurrentUp(t *testing.T) {
+	databaseConfig := &config.DatabaseConfig{
+		Driver:   config.DatabaseDriverSQLite,
+		FilePath: filepath.Join(t.TempDir(), "employees.db"),
+		Mode:     config.DatabaseModePersistent,
+	}
+	var migrators []*migrations.Migrator
+	for i := 0; i < 4; i++ {
+		migrator, err := migrations.NewMigrator(openTestDB(t, databaseConfig))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		migrators = append(migrators, migrator)
+	}
+
+	// instances starting together wait for each other instead of applying the same migration twice
+	errs := make([]error, len(migrators))
+	var wg sync.WaitGroup
+	for i, migrator := range migrators {
+		wg.Add(1)
+		go func(i int, migrator *migrations.Migrator) {
+			defer wg.Done()
+			errs[i] = migrator.Up(0)
+		}(i, migrator)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, migrators[0].Verify())
+
+	// a held lock row keeps the migrators waiting until it is released
+	sqlClient := openTestDB(t, databaseConfig)
+	assert.NoError(t, sqlClient.DB.Exec("INSERT INTO schema_migrations_lock (id, locked_at) VALUES (1, ?)", time.Now().UTC()).Error)
+	done := make(chan error, 1)
+	go func() { done <- migrators[0].Down(1) }()
+	select {
+	case err := <-done:
+		t.Fatalf("migrated while the lock was held: %v", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+	assert.NoError(t, sqlClient.DB.Exec("DELETE FROM schema_migrations_lock").Error)
+	assert.NoError(t, <-done)
+	assert.ErrorIs(t, migrators[0].Verify(), migrations.ErrPending)
+
+	// the lock row of a migrator that died is broken once it is stale
+	assert.NoError(t, sqlClient.DB.Exec("INSERT INTO schema_migrations_lock (id, locked_at) VALUES (1, ?)", time.Now().UTC().Add(-time.Hour)).Error)
+	assert.NoError(t, migrators[1].Up(0))
+	assert.NoError(t, migrators[1].Verify())
+}
+
+func TestMigrator_AdoptsAutoMigratedDatabase(t *testing.T) {
+	sqlClient := openTestDB(t, &config.DatabaseConfig{
+		Driver:   config.DatabaseDriverSQLite,
+		FilePath: filepath.Join(t.TempDir(), "employees.db"),
+		Mode:     config.DatabaseModePersistent,
+	})
+	// the schema as the service used to create it before migrations existed
+	assert.NoError(t, sqlClient.DB.Exec("CREATE TABLE `employees` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime,`name` text,`position` text,`salary` real)").Error)
+	assert.NoError(t, sqlClient.DB.Exec("INSERT INTO employees (name, salary) VALUES ('John Doe', 1000)").Error)
+
+	migrator, err := migrations.NewMigrator(sqlClient)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up(0))
+
+	var count int64
+	assert.NoError(t, sqlClient.DB.Model(&models.Employee{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestMigrator_ChecksumMismatch(t *testing.T) {
+	sqlClient := newMigratedDB(t)
+	assert.NoError(t, sqlClient.DB.Model(&migrations.SchemaMigration{}).Where("version = ?", 1).Update("checksum", "edited").Error)
+
+	migrator, err := migrations.NewMigrator(sqlClient)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, migrator.Up(0), migrations.ErrChecksumMismatch)
+	assert.ErrorIs(t, migrator.Verify(), migrations.ErrChecksumMismatch)
+}
+
+func TestMigrator_SalaryDecimalCurrency(t *testing.T) {
+	sqlClient := openTestDB(t, &config.DatabaseConfig{
+		Driver:   config.DatabaseDriverSQLite,
+		FilePath: filepath.Join(t.TempDir(), "employees.db"),
+		Mode:     config.DatabaseModePersistent,
+	})
+	migrator, err := migrations.NewMigrator(sqlClient)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up(5))
+	assert.NoError(t, sqlClient.DB.Exec("INSERT INTO employees (name, salary, version) VALUES ('John Doe', 76000.25, 1), ('Jane Doe', NULL, 1)").Error)
+
+	// floating point salaries become exact ones in US dollars
+	assert.NoError(t, migrator.Up(6))
+	var employees []*models.Employee
+	assert.NoError(t, sqlClient.DB.Order("id").Find(&employees).Error)
+	assert.Len(t, employees, 2)
+	assert.Equal(t, "76000.25", employees[0].Salary.String())
+	assert.Equal(t, models.DefaultCurrency, employees[0].Currency)
+	assert.True(t, employees[1].Salary.IsZero())
+
+	assert.NoError(t, migrator.Down(1))
+	var salary float64
+	assert.NoError(t, sqlClient.DB.Raw("SELECT salary FROM employees WHERE id = 1").Scan(&salary).Error)
+	assert.Equal(t, 76000.25, salary)
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MrAzharuddin/employee-crud/employee-service/config"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/daos/clients/sqls/migrations"
+	"github.com/MrAzharuddin/employee-crud/employee-service/pkg/rest/server/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestDB(t *testing.T, databaseConfig *config.DatabaseConfig) *sqls.SQLClient {
+	sqlClient, err := sqls.NewGORMDB(databaseConfig)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() {
+		sqlDB, _ := sqlClient.DB.DB()
+		_ = sqlDB.Close()
+	})
+	return sqlClient
+}
+
+func TestSQLClient_SQLiteModes(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "employees.db")
+	databaseConfig := &config.DatabaseConfig{Driver: config.DatabaseDriverSQLite, FilePath: filePath, Mode: config.DatabaseModePersistent}
+
+	// persistent mode keeps rows between connections
+	sqlClient := openTestDB(t, databaseConfig)
+	assert.NoError(t, sqlClient.DB.AutoMigrate(models.Employee{}))
+	assert.NoError(t, sqlClient.DB.Create(&models.Employee{Name: "John Doe"}).Error)
+
+	var count int64
+	sqlClient = openTestDB(t, databaseConfig)
+	assert.NoError(t, sqlClient.DB.Model(&models.Employee{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// readonly mode reads but refuses writes
+	databaseConfig.Mode = config.DatabaseModeReadOnly
+	sqlClient = openTestDB(t, databaseConfig)
+	assert.True(t, sqlClient.ReadOnly())
+	assert.NoError(t, sqlClient.DB.Model(&models.Employee{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	assert.Error(t, sqlClient.DB.Create(&models.Employee{Name: "Jane Doe"}).Error)
+
+	// ephemeral mode starts from an empty file
+	databaseConfig.Mode = config.DatabaseModeEphemeral
+	sqlClient = openTestDB(t, databaseConfig)
+	assert.False(t, sqlClient.DB.Migrator().HasTable(&models.Employee{}))
+}
+
+func TestSQLClient_SQLiteReadOnlyDSNParams(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "employees.db")
+	sqlClient := openTestDB(t, &config.DatabaseConfig{Driver: config.DatabaseDriverSQLite, FilePath: filePath, Mode: config.DatabaseModePersistent})
+	assert.NoError(t, sqlClient.DB.AutoMigrate(models.Employee{}))
+	assert.NoError(t, sqlClient.DB.Create(&models.Employee{Name: "John Doe"}).Error)
+
+	// mode=ro joins the parameters already in the DSN
+	for _, dsn := range []string{filePath + "?_busy_timeout=5000", "file:" + filePath + "?_busy_timeout=5000"} {
+		sqlClient = openTestDB(t, &config.DatabaseConfig{Driver: config.DatabaseDriverSQLite, DSN: dsn, Mode: config.DatabaseModeReadOnly})
+		var busyTimeout, count int64
+		assert.NoError(t, sqlClient.DB.Raw("PRAGMA busy_timeout").Scan(&busyTimeout).Error, dsn)
+		assert.Equal(t, int64(5000), busyTimeout, dsn)
+		assert.NoError(t, sqlClient.DB.Model(&models.Employee{}).Count(&count).Error, dsn)
+		assert.Equal(t, int64(1), count, dsn)
+		assert.Error(t, sqlClient.DB.Create(&models.Employee{Name: "Jane Doe"}).Error, dsn)
+	}
+}
+
+func TestSQLClient_ReadOnlyNeedsExistingFile(t *testing.T) {
+	_, err := sqls.NewGORMDB(&config.DatabaseConfig{
+		Driver:   config.DatabaseDriverSQLite,
+		FilePath: filepath.Join(t.TempDir(), "missing.db"),
+		Mode:     config.DatabaseModeReadOnly,
+	})
+	assert.Error(t, err)
+}
+
+// TestSQLClient_Postgres runs against a locally started postgres, see useful-commands
+func TestSQLClient_Postgres(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if len(dsn) == 0 {
+		t.Skip("TEST_POSTGRES_DSN not set")
+	}
+	sqlClient := openTestDB(t, &config.DatabaseConfig{
+		Driver:       config.DatabaseDriverPostgres,
+		DSN:          dsn,
+		Mode:         config.DatabaseModePersistent,
+		MaxOpenConns: 4,
+		MaxIdleConns: 2,
+	})
+	migrator, err := migrations.NewMigrator(sqlClient)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Down(int(migrator.Latest())))
+	assert.NoError(t, migrator.Up(0))
+
+	employee := &models.Employee{Name: "John Doe", Position: "Software Developer", Salary: models.RequireAmount("1000")}
+	assert.NoError(t, sqlClient.DB.Create(employee).Error)
+
+	var found models.Employee
+	assert.NoError(t, sqlClient.DB.First(&found, employee.ID).Error)
+	assert.Equal(t, "John Doe", found.Name)
+}
+
+func TestDatabaseConfig_Validate(t *testing.T) {
+	assert.Error(t, (&config.DatabaseConfig{Driver: config.DatabaseDriverPostgres, Mode: config.DatabaseModePersistent}).Validate())
+	assert.Error(t, (&config.DatabaseConfig{Driver: config.DatabaseDriverMySQL, DSN: "dsn", Mode: config.DatabaseModeEphemeral}).Validate())
+	assert.Error(t, (&config.DatabaseConfig{Driver: "oracle", Mode: config.DatabaseModePersistent}).Validate())
+	assert.NoError(t, (&config.DatabaseConfig{Driver: config.DatabaseDriverSQLite, Mode: config.DatabaseModeReadOnly}).Validate())
+}